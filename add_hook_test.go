@@ -0,0 +1,74 @@
+package logrus_mate
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAddHook(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := &recordHook{}
+	if err = mate.AddHook("mike", hook); err != nil {
+		t.Fatal(err)
+	}
+	// a panicking hook added in code is guarded the same as the configured ones
+	if err = mate.AddHook("mike", panicHook{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mate.Logger("mike").WithField("user", "bob").Warn("added in code")
+
+	entries := hook.Entries()
+	if len(entries) != 1 || entries[0].Message != "added in code" || entries[0].Data["user"] != "bob" {
+		t.Fatalf("entries %v", entries)
+	}
+
+	if err = mate.AddHook("missing", hook); err != ErrLoggerNotExist {
+		t.Fatalf("expected ErrLoggerNotExist: %v", err)
+	}
+}
+
+func TestAddHookWhileLogging(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := mate.Logger("mike")
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				logger.Info("busy")
+			}
+		}
+	}()
+
+	hooks := make([]*recordHook, 10)
+	for i := range hooks {
+		hooks[i] = &recordHook{}
+		if err = mate.AddHook("mike", hooks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+
+	logger.Info("after")
+	for _, hook := range hooks {
+		if entries := hook.Entries(); len(entries) == 0 || entries[len(entries)-1].Message != "after" {
+			t.Fatal("the hook added while logging misses the later entries")
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package logrus_mate
+
+import (
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// logStartupBanner logs the effective config summary as the first entry,
+// it helps to find out where the logs go
+func logStartupBanner(logger *logrus.Logger, conf config.Configuration, outName, formatterName string, hookNames []string) {
+	fields := logrus.Fields{
+		"level":     logger.Level.String(),
+		"out":       outName,
+		"formatter": formatterName,
+		"hooks":     hookNames,
+	}
+
+	if fileConf := conf.GetConfig("hooks.file"); fileConf != nil {
+		if filename := fileConf.GetString("filename"); len(filename) > 0 {
+			fields["file"] = filename
+		}
+	}
+
+	logger.WithFields(fields).Infoln("logger started")
+}
@@ -0,0 +1,37 @@
+package logrus_mate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStartupBanner(t *testing.T) {
+	hijackString(t, `
+startup-banner = true
+level = "debug"
+formatter.name = "json"
+out.name = "buffer"
+hooks.test-record.id = "banner"`)
+
+	entries := recordedBy(t, "banner").Entries()
+	if len(entries) != 1 || entries[0].Message != "logger started" {
+		t.Fatalf("entries %v", entries)
+	}
+
+	data := entries[0].Data
+	if data["level"] != "debug" || data["out"] != "buffer" || data["formatter"] != "json" ||
+		!reflect.DeepEqual(data["hooks"], []string{"test-record"}) {
+		t.Fatalf("banner fields %v", data)
+	}
+	if _, exist := data["file"]; exist {
+		t.Fatalf("the file of banner without file hook: %v", data)
+	}
+}
+
+func TestStartupBannerOptIn(t *testing.T) {
+	hijackString(t, `hooks.test-record.id = "no-banner"`)
+
+	if entries := recordedBy(t, "no-banner").Entries(); len(entries) != 0 {
+		t.Fatalf("the banner is logged by default: %v", entries)
+	}
+}
@@ -0,0 +1,196 @@
+package logrus_mate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+type batchKey struct{}
+
+// Batch accumulates the entries logged with its context into one summary entry,
+// see StartBatch
+type Batch struct {
+	name    string
+	started time.Time
+
+	locker  sync.Mutex
+	hook    *batchHook
+	fields  logrus.Fields
+	steps   []logrus.Fields
+	dropped int
+	level   logrus.Level
+	timer   *time.Timer
+	flushed bool
+}
+
+// StartBatch returns a copy of ctx carrying a new batch, the entries logged with
+// the context by WithContext are not written but accumulated as the steps, until
+// Flush of the batch writes them as one entry with the message name. It requires
+// batch configured on the logger, else the entries are written as usual.
+func StartBatch(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b := &Batch{
+		name:    name,
+		started: time.Now(),
+		fields:  make(logrus.Fields),
+		level:   logrus.TraceLevel,
+	}
+
+	return context.WithValue(ctx, batchKey{}, b)
+}
+
+// BatchFromContext returns the batch started by StartBatch, or nil
+func BatchFromContext(ctx context.Context) *Batch {
+	if ctx == nil {
+		return nil
+	}
+
+	b, _ := ctx.Value(batchKey{}).(*Batch)
+	return b
+}
+
+// AddFields adds the fields to the summary entry, the later wins on the same key
+func (b *Batch) AddFields(fields logrus.Fields) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	for k, v := range fields {
+		b.fields[k] = v
+	}
+}
+
+// Flush writes the summary entry at the most severe level of the steps, the
+// batch without any step captured writes nothing. It is safe to call twice.
+func (b *Batch) Flush() {
+	b.flush(false)
+}
+
+func (b *Batch) flush(expired bool) {
+	b.locker.Lock()
+
+	if b.flushed || b.hook == nil {
+		b.flushed = true
+		b.locker.Unlock()
+		return
+	}
+
+	b.flushed = true
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	data := make(logrus.Fields, len(b.fields)+4)
+	for k, v := range b.fields {
+		data[k] = v
+	}
+	data[b.hook.field] = b.steps
+	data["batch_duration"] = time.Since(b.started).String()
+	if b.dropped > 0 {
+		data["batch_dropped"] = b.dropped
+	}
+	if expired {
+		data["batch_expired"] = true
+	}
+
+	hook, level := b.hook, b.level
+	b.locker.Unlock()
+
+	// the entry has no context, so it is written instead of captured again
+	logrus.NewEntry(hook.logger).WithFields(data).Log(level, b.name)
+}
+
+// capture appends the entry as a step, it reports false when the batch was
+// flushed, then the entry is written as usual
+func (b *Batch) capture(hook *batchHook, entry *logrus.Entry) bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	if b.flushed {
+		return false
+	}
+
+	if b.hook == nil {
+		b.hook = hook
+		// the batch never flushed is written and released after ttl
+		b.timer = time.AfterFunc(hook.ttl, func() { b.flush(true) })
+	}
+
+	if entry.Level < b.level {
+		b.level = entry.Level
+	}
+
+	if len(b.steps) >= hook.maxSteps {
+		b.dropped++
+		return true
+	}
+
+	step := make(logrus.Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		step[k] = v
+	}
+	step["msg"] = entry.Message
+	step["level"] = entry.Level.String()
+	step["time"] = entry.Time.Format(time.RFC3339Nano)
+
+	b.steps = append(b.steps, step)
+
+	return true
+}
+
+// batchHook captures the entries of the batch context, the captured entry is
+// dropped from the output and the other hooks. Panic and fatal are never captured.
+type batchHook struct {
+	logger   *logrus.Logger
+	ttl      time.Duration
+	maxSteps int
+	field    string
+}
+
+// newBatchHook creates the hook from config like:
+// batch { ttl = 1m, max-steps = 100, field = "steps" }
+func newBatchHook(logger *logrus.Logger, conf config.Configuration) (hook *batchHook, err error) {
+	hook = &batchHook{
+		logger:   logger,
+		ttl:      conf.GetTimeDuration("ttl", time.Minute),
+		maxSteps: int(conf.GetInt32("max-steps", 100)),
+		field:    conf.GetString("field", "steps"),
+	}
+
+	if hook.ttl <= 0 || hook.maxSteps <= 0 {
+		err = fmt.Errorf("logurs mate: batch ttl and max-steps should be positive, got %s, %d", hook.ttl, hook.maxSteps)
+		return
+	}
+
+	return
+}
+
+func (p *batchHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.ErrorLevel,
+		logrus.WarnLevel,
+		logrus.InfoLevel,
+		logrus.DebugLevel,
+		logrus.TraceLevel,
+	}
+}
+
+func (p *batchHook) Fire(entry *logrus.Entry) error {
+	b := BatchFromContext(entry.Context)
+	if b == nil || isDropped(entry) {
+		return nil
+	}
+
+	if b.capture(p, entry) {
+		markDropped(entry)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package logrus_mate
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBatchAccumulates(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "debug"
+batch { max-steps = 2 }
+formatter.name = "json"
+hooks.test-record.id = "batch"`)
+
+	ctx := StartBatch(context.Background(), "GET /users")
+	b := BatchFromContext(ctx)
+
+	logger.WithContext(ctx).WithField("rows", 10).Debug("query users")
+	logger.WithContext(ctx).Warn("slow query")
+	logger.WithContext(ctx).Info("third step")
+	logger.Info("not in batch")
+	b.AddFields(logrus.Fields{"status": 200})
+
+	// the steps are captured from the output and the hooks until flush
+	if s := buf.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "not in batch") {
+		t.Fatalf("output before flush %q", s)
+	}
+
+	b.Flush()
+	b.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines %q", lines)
+	}
+
+	var summary struct {
+		Level   string                   `json:"level"`
+		Msg     string                   `json:"msg"`
+		Status  int                      `json:"status"`
+		Dropped int                      `json:"batch_dropped"`
+		Expired bool                     `json:"batch_expired"`
+		Steps   []map[string]interface{} `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	// written at the most severe level of the steps, the third step is over max-steps
+	if summary.Msg != "GET /users" || summary.Level != "warning" || summary.Status != 200 ||
+		summary.Dropped != 1 || summary.Expired || len(summary.Steps) != 2 {
+		t.Fatalf("summary %s", lines[1])
+	}
+	if summary.Steps[0]["msg"] != "query users" || summary.Steps[0]["rows"] != float64(10) ||
+		summary.Steps[1]["msg"] != "slow query" || summary.Steps[1]["level"] != "warning" {
+		t.Fatalf("steps %v", summary.Steps)
+	}
+
+	if entries := recordedBy(t, "batch").Entries(); len(entries) != 2 || entries[1].Message != "GET /users" {
+		t.Fatalf("the hooks see %v", entries)
+	}
+
+	// the entries after flush are written as usual
+	logger.WithContext(ctx).Info("after flush")
+	if !strings.Contains(buf.String(), "after flush") {
+		t.Fatalf("output after flush %q", buf.String())
+	}
+}
+
+func TestBatchExpiresByTTL(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+batch.ttl = 50ms
+hooks.test-record.id = "batch-ttl"`)
+
+	ctx := StartBatch(context.Background(), "leaked")
+	logger.WithContext(ctx).Info("step")
+
+	recorded := recordedBy(t, "batch-ttl")
+	if entries := recorded.Entries(); len(entries) != 0 {
+		t.Fatalf("the step is not captured %v", entries)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorded.Entries()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the leaked batch is not flushed by ttl")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entries := recorded.Entries()
+	if len(entries) != 1 || entries[0].Message != "leaked" || entries[0].Data["batch_expired"] != true {
+		t.Fatalf("the hooks see %v", entries)
+	}
+
+	// the flush after expiry writes nothing more
+	BatchFromContext(ctx).Flush()
+	if entries = recorded.Entries(); len(entries) != 1 {
+		t.Fatalf("flushed twice %v", entries)
+	}
+}
+
+func TestBatchWithoutConfig(t *testing.T) {
+	logger, buf := hijackString(t, `level = "info"`)
+
+	ctx := StartBatch(nil, "unused")
+	logger.WithContext(ctx).Info("written")
+	BatchFromContext(ctx).Flush()
+
+	if s := buf.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "written") {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestBatchInvalidConfig(t *testing.T) {
+	for _, conf := range []string{`batch.ttl = 0s`, `batch.max-steps = 0`} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize keeps the buffers grown by some huge entries out of the pool
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool is shared by all loggers configured with buffer-pool = true
+type bufferPool struct {
+	pool sync.Pool
+}
+
+var sharedBufferPool = &bufferPool{
+	pool: sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	},
+}
+
+func (p *bufferPool) Get() *bytes.Buffer {
+	return p.pool.Get().(*bytes.Buffer)
+}
+
+func (p *bufferPool) Put(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	p.pool.Put(buf)
+}
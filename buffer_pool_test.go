@@ -0,0 +1,85 @@
+package logrus_mate
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBufferPoolConcurrent(t *testing.T) {
+	logger, _ := hijackString(t, `
+buffer-pool = true
+formatter.name = "json"`)
+
+	if logger.BufferPool != sharedBufferPool {
+		t.Fatal("the shared pool is not set")
+	}
+
+	w := &BufferWriter{}
+	logger.Out = w
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.WithField("worker", i).Info(strings.Repeat("x", j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(string(w.Bytes())), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("%d lines, expected 800", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+			t.Fatalf("the lines are mixed: %q", line)
+		}
+	}
+}
+
+func TestBufferPoolDropsHugeBuffers(t *testing.T) {
+	buf := sharedBufferPool.Get()
+	buf.Grow(maxPooledBufferSize * 2)
+	sharedBufferPool.Put(buf)
+
+	for i := 0; i < 10; i++ {
+		if b := sharedBufferPool.Get(); b == buf {
+			t.Fatal("the huge buffer is pooled")
+		}
+	}
+}
+
+func benchmarkLogger(b *testing.B, conf string) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(conf)); err != nil {
+		b.Fatal(err)
+	}
+	logger.Out = ioutil.Discard
+
+	fields := logrus.Fields{"user": "bob", "id": 42}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.WithFields(fields).Info("benchmark")
+		}
+	})
+}
+
+func BenchmarkLoggerWithoutBufferPool(b *testing.B) {
+	benchmarkLogger(b, `formatter.name = "json"`)
+}
+
+func BenchmarkLoggerWithBufferPool(b *testing.B) {
+	benchmarkLogger(b, `
+buffer-pool = true
+formatter.name = "json"`)
+}
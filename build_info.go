@@ -0,0 +1,28 @@
+package logrus_mate
+
+import (
+	"sync/atomic"
+)
+
+// BuildInfo is the build metadata attached by the buildinfo hook
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+}
+
+var buildInfo atomic.Value
+
+// SetBuildInfo registers the build metadata, usually the variables set by -ldflags, e.g.
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD)"
+func SetBuildInfo(version, commit, buildTime string) {
+	buildInfo.Store(&BuildInfo{Version: version, Commit: commit, BuildTime: buildTime})
+}
+
+// GetBuildInfo returns the build metadata registered by SetBuildInfo, it is empty before
+func GetBuildInfo() BuildInfo {
+	if info, ok := buildInfo.Load().(*BuildInfo); ok {
+		return *info
+	}
+	return BuildInfo{}
+}
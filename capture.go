@@ -0,0 +1,72 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type captureHook struct {
+	locker  sync.Mutex
+	entries []logrus.Entry
+}
+
+func (p *captureHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *captureHook) Fire(entry *logrus.Entry) error {
+	e := *entry
+	e.Data = make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		e.Data[k] = v
+	}
+
+	p.locker.Lock()
+	p.entries = append(p.entries, e)
+	p.locker.Unlock()
+
+	return nil
+}
+
+// CaptureDuring attaches a capture hook to the named logger while fn runs,
+// and returns the entries logged meanwhile. The entries logged by the goroutines
+// spawned by fn are captured only if they are logged before fn returns, so fn
+// should wait for them. The hooks of logger are restored afterward, the hooks
+// added by others during fn are discarded, so captures of the same logger should
+// not overlap. A panic of fn is recovered and
+// returned as error with the entries captured before it.
+func (p *LogrusMate) CaptureDuring(loggerName string, fn func()) (entries []logrus.Entry, err error) {
+	logger := p.Logger(loggerName)
+	if logger == nil {
+		err = ErrLoggerNotExist
+		return
+	}
+
+	capture := &captureHook{}
+
+	hooks := make(logrus.LevelHooks)
+	for lvl, levelHooks := range logger.Hooks {
+		hooks[lvl] = append([]logrus.Hook(nil), levelHooks...)
+	}
+	hooks.Add(capture)
+
+	origin := logger.ReplaceHooks(hooks)
+
+	defer func() {
+		logger.ReplaceHooks(origin)
+
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logurs mate: capture function panic: %v", r)
+		}
+
+		capture.locker.Lock()
+		entries = capture.entries
+		capture.locker.Unlock()
+	}()
+
+	fn()
+
+	return
+}
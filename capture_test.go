@@ -0,0 +1,88 @@
+package logrus_mate
+
+import (
+	"sync"
+	"testing"
+)
+
+func newCaptureMate(t *testing.T) *LogrusMate {
+	t.Helper()
+
+	mate, err := NewLogrusMate(ConfigString(`
+mike {
+    out.name = "buffer"
+    hooks.test-record.id = "capture"
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mate
+}
+
+func TestCaptureDuring(t *testing.T) {
+	mate := newCaptureMate(t)
+	logger := mate.Logger("mike")
+	hooksBefore := len(logger.Hooks[0])
+
+	logger.Info("before")
+
+	entries, err := mate.CaptureDuring("mike", func() {
+		logger.WithField("n", 0).Info("inside")
+
+		var wg sync.WaitGroup
+		for i := 1; i <= 3; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				logger.WithField("n", i).Warn("spawned")
+			}(i)
+		}
+		wg.Wait()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("after")
+
+	if len(entries) != 4 || entries[0].Message != "inside" || entries[0].Data["n"] != 0 {
+		t.Fatalf("captured %v", entries)
+	}
+	if len(logger.Hooks[0]) != hooksBefore {
+		t.Fatalf("%d hooks after capture, expected %d", len(logger.Hooks[0]), hooksBefore)
+	}
+
+	// the configured hooks keep firing during the capture
+	if recorded := recordedBy(t, "capture").Entries(); len(recorded) != 6 {
+		t.Fatalf("the configured hook recorded %d", len(recorded))
+	}
+}
+
+func TestCaptureDuringPanic(t *testing.T) {
+	mate := newCaptureMate(t)
+	logger := mate.Logger("mike")
+	hooksBefore := len(logger.Hooks[0])
+
+	entries, err := mate.CaptureDuring("mike", func() {
+		logger.Error("about to panic")
+		panic("boom")
+	})
+
+	if err == nil {
+		t.Fatal("the panic is not returned")
+	}
+	if len(entries) != 1 || entries[0].Message != "about to panic" {
+		t.Fatalf("captured %v", entries)
+	}
+	if len(logger.Hooks[0]) != hooksBefore {
+		t.Fatalf("%d hooks after the panic, expected %d", len(logger.Hooks[0]), hooksBefore)
+	}
+}
+
+func TestCaptureDuringMissingLogger(t *testing.T) {
+	mate := newCaptureMate(t)
+
+	if _, err := mate.CaptureDuring("missing", func() {}); err != ErrLoggerNotExist {
+		t.Fatalf("expected ErrLoggerNotExist: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package logrus_mate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrDrainTimeout = errors.New("drain timeout")
+)
+
+// DrainError reports the hooks failed to drain, keyed by logger.hook
+type DrainError struct {
+	Failed map[string]error
+}
+
+func (p *DrainError) Error() string {
+	var names []string
+	for name, err := range p.Failed {
+		names = append(names, fmt.Sprintf("%s: %s", name, err))
+	}
+	sort.Strings(names)
+	return "logurs mate: hooks not drained, " + strings.Join(names, ", ")
+}
+
+type closableHook struct {
+	name  string
+	close func() error
+}
+
+// CloseWithTimeout closes the hooks having Close() error, or flushes the hooks having
+// Flush() error only, of the loggers created by Logger(name), so the async and
+// network hooks could flush the buffered entries.
+// The hooks are closed concurrently, the hooks not closed within d are reported
+// by *DrainError and left running, so shutdown never hangs on an unreachable collector.
+func (p *LogrusMate) CloseWithTimeout(d time.Duration) (err error) {
+	var hooks []closableHook
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		hooks = append(hooks, closableHooks(k.(string), v.(*logrus.Logger))...)
+		return true
+	})
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	type result struct {
+		index int
+		err   error
+	}
+
+	results := make(chan result, len(hooks))
+	pending := make(map[int]bool, len(hooks))
+
+	for i, h := range hooks {
+		pending[i] = true
+		go func(i int, h closableHook) {
+			results <- result{index: i, err: h.close()}
+		}(i, h)
+	}
+
+	failed := make(map[string]error)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.index)
+			if r.err != nil {
+				failed[hooks[r.index].name] = r.err
+			}
+		case <-timer.C:
+			for i := range pending {
+				failed[hooks[i].name] = ErrDrainTimeout
+			}
+			pending = nil
+		}
+	}
+
+	if len(failed) > 0 {
+		err = &DrainError{Failed: failed}
+	}
+
+	return
+}
+
+// closableHooks returns the distinct hooks of logger having Close or Flush,
+// the hook added for several levels is closed once
+func closableHooks(loggerName string, logger *logrus.Logger) (hooks []closableHook) {
+	seen := make(map[logrus.Hook]bool)
+
+	for _, levelHooks := range logger.Hooks {
+		for _, hook := range levelHooks {
+			if reflect.TypeOf(hook).Comparable() {
+				if seen[hook] {
+					continue
+				}
+				seen[hook] = true
+			}
+
+			name := fmt.Sprintf("%T", hook)
+			if safe, ok := hook.(*safeHook); ok {
+				name, hook = safe.name, safe.hook
+			}
+
+			closeFunc := closeFuncOf(hook)
+			if closeFunc == nil {
+				continue
+			}
+
+			hooks = append(hooks, closableHook{name: loggerName + "." + name, close: closeFunc})
+		}
+	}
+
+	return
+}
+
+// closeFuncOf returns Close of hook, or Flush if hook has no Close
+func closeFuncOf(hook logrus.Hook) func() error {
+	if closer, ok := hook.(interface{ Close() error }); ok {
+		return closer.Close
+	}
+
+	if flusher, ok := hook.(interface{ Flush() error }); ok {
+		return flusher.Flush
+	}
+
+	return nil
+}
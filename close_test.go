@@ -0,0 +1,111 @@
+package logrus_mate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// closingHook takes delay to close and returns err
+type closingHook struct {
+	delay  time.Duration
+	err    error
+	closed chan struct{}
+}
+
+func (p *closingHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (p *closingHook) Fire(*logrus.Entry) error { return nil }
+
+func (p *closingHook) Close() error {
+	time.Sleep(p.delay)
+	close(p.closed)
+	return p.err
+}
+
+type slowHook struct{ closingHook }
+
+type failingHook struct{ closingHook }
+
+// flushingHook has Flush only
+type flushingHook struct {
+	flushed bool
+}
+
+func (p *flushingHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.InfoLevel, logrus.ErrorLevel}
+}
+
+func (p *flushingHook) Fire(*logrus.Entry) error { return nil }
+
+func (p *flushingHook) Flush() error {
+	p.flushed = true
+	return nil
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("mike")
+
+	errClose := errors.New("collector unreachable")
+	slow := &slowHook{closingHook{delay: time.Second, closed: make(chan struct{})}}
+	failing := &failingHook{closingHook{err: errClose, closed: make(chan struct{})}}
+	fast := &closingHook{closed: make(chan struct{})}
+	flushing := &flushingHook{}
+
+	for _, hook := range []logrus.Hook{slow, failing, fast, flushing} {
+		if err = mate.AddHook("mike", hook); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	err = mate.CloseWithTimeout(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("waited %v for the slow hook", elapsed)
+	}
+
+	drainErr, ok := err.(*DrainError)
+	if !ok {
+		t.Fatalf("expected *DrainError: %v", err)
+	}
+
+	expected := map[string]error{
+		"mike.*logrus_mate.slowHook":    ErrDrainTimeout,
+		"mike.*logrus_mate.failingHook": errClose,
+	}
+	if len(drainErr.Failed) != len(expected) {
+		t.Fatalf("failed %v, expected %v", drainErr.Failed, expected)
+	}
+	for name, e := range expected {
+		if drainErr.Failed[name] != e {
+			t.Fatalf("failed %v, expected %v", drainErr.Failed, expected)
+		}
+	}
+
+	select {
+	case <-fast.closed:
+	default:
+		t.Fatal("the fast hook is not closed")
+	}
+	if !flushing.flushed {
+		t.Fatal("the hook having Flush only is not flushed")
+	}
+}
+
+func TestCloseWithTimeoutNothing(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("mike")
+
+	if err = mate.CloseWithTimeout(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
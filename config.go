@@ -8,10 +8,25 @@ type Option func(*Config)
 
 type Config struct {
 	configOpts []config.Option
+	remotes    []*remoteSource
+	err        error
 }
 
+// ConfigFile loads config from file, `include "other.conf"` lines are
+// resolved relative to the directory of the including file
 func ConfigFile(fn string) Option {
 	return func(o *Config) {
+		content, hasInclude, err := expandIncludes(fn)
+		if hasInclude {
+			if err != nil {
+				o.err = err
+				return
+			}
+
+			o.configOpts = append(o.configOpts, config.ConfigString(content))
+			return
+		}
+
 		o.configOpts = append(o.configOpts, config.ConfigFile(fn))
 	}
 }
@@ -0,0 +1,128 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// the keys of beego file logger mapped to the file hook
+var beegoFileKeys = map[string]string{
+	"filename":   "filename",
+	"maxlines":   "max-lines",
+	"maxsize":    "max-size",
+	"daily":      "daily",
+	"maxdays":    "max-days",
+	"hourly":     "hourly",
+	"rotate":     "rotate",
+	"perm":       "perm",
+	"rotateperm": "rotate-perm",
+}
+
+// the beego levels by RFC5424, emergency to debug
+var beegoLevels = []logrus.Level{
+	logrus.PanicLevel, // emergency
+	logrus.FatalLevel, // alert
+	logrus.FatalLevel, // critical
+	logrus.ErrorLevel, // error
+	logrus.WarnLevel,  // warning
+	logrus.InfoLevel,  // notice
+	logrus.InfoLevel,  // informational
+	logrus.DebugLevel, // debug
+}
+
+// ConfigBeego configures by the json of beego file logger, e.g.
+// {"filename":"logs/app.log","maxlines":10000,"maxsize":1048576,"daily":true,"maxdays":7,"level":6}
+// The logger writes by the file hook (import hooks/file) with the text formatter, the beego
+// level is mapped to the logrus level, and the keys not supported, e.g. maxhours and separate,
+// are warned on stderr and ignored.
+func ConfigBeego(jsonConfig string) Option {
+	return func(o *Config) {
+		str, err := parseBeego(jsonConfig)
+		if err != nil {
+			o.err = err
+			return
+		}
+		ConfigString(str)(o)
+	}
+}
+
+func parseBeego(jsonConfig string) (str string, err error) {
+	values := map[string]interface{}{}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(jsonConfig))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&values); err != nil {
+		err = fmt.Errorf("logurs mate: bad beego config: %s", err)
+		return
+	}
+
+	if _, exist := values["filename"]; !exist {
+		err = fmt.Errorf("logurs mate: beego config has no filename")
+		return
+	}
+
+	// beego logs everything by default
+	level := logrus.DebugLevel
+	if v, exist := values["level"]; exist {
+		n, ok := v.(json.Number)
+		i, nerr := n.Int64()
+		if !ok || nerr != nil || i < 0 || i >= int64(len(beegoLevels)) {
+			err = fmt.Errorf("logurs mate: beego level should be 0 to 7, but got %v", v)
+			return
+		}
+		level = beegoLevels[i]
+	}
+
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "level = %s\n", strconv.Quote(level.String()))
+	fmt.Fprintf(b, "formatter.name = \"text\"\n")
+	fmt.Fprintf(b, "out.name = \"nil\"\n")
+	fmt.Fprintf(b, "hooks.file.level = %d\n", level)
+
+	var keys []string
+	for k := range values {
+		if k != "level" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name, exist := beegoFileKeys[k]
+		if !exist {
+			_, _ = fmt.Fprintf(os.Stderr, "logurs mate: beego config key %s is not supported, ignored\n", k)
+			continue
+		}
+
+		var value string
+		switch v := values[k].(type) {
+		case string:
+			value = strconv.Quote(v)
+		case bool:
+			value = strconv.FormatBool(v)
+		case json.Number:
+			if _, nerr := v.Int64(); nerr != nil {
+				err = fmt.Errorf("logurs mate: beego %s should be an integer, but got %s", k, v)
+				return
+			}
+			value = v.String()
+		default:
+			err = fmt.Errorf("logurs mate: beego %s has bad value %v", k, v)
+			return
+		}
+
+		fmt.Fprintf(b, "hooks.file.%s = %s\n", name, value)
+	}
+
+	str = b.String()
+
+	return
+}
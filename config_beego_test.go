@@ -0,0 +1,78 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBeego(t *testing.T) {
+	str, err := parseBeego(`{"filename":"logs/app.log","maxlines":10000,"maxsize":1048576,"daily":true,"maxdays":7,"rotate":true,"perm":"0600","level":6,"separate":["error"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the keys sorted, the unsupported separate is ignored
+	expected := `level = "info"
+formatter.name = "text"
+out.name = "nil"
+hooks.file.level = 4
+hooks.file.daily = true
+hooks.file.filename = "logs/app.log"
+hooks.file.max-days = 7
+hooks.file.max-lines = 10000
+hooks.file.max-size = 1048576
+hooks.file.perm = "0600"
+hooks.file.rotate = true
+`
+	if str != expected {
+		t.Fatalf("config\n%s\nexpected\n%s", str, expected)
+	}
+
+	conf := configOf(str)
+	if conf.GetString("level") != "info" || conf.GetInt64("hooks.file.max-size") != 1048576 || conf.GetString("hooks.file.perm") != "0600" {
+		t.Fatalf("parsed %s", str)
+	}
+}
+
+func TestParseBeegoLevels(t *testing.T) {
+	for level, expected := range map[string]string{
+		"":  "debug",
+		"0": "panic",
+		"2": "fatal",
+		"3": "error",
+		"4": "warning",
+		"5": "info",
+		"7": "debug",
+	} {
+		jsonConfig := `{"filename":"app.log"}`
+		if level != "" {
+			jsonConfig = `{"filename":"app.log","level":` + level + `}`
+		}
+
+		str, err := parseBeego(jsonConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(str, `level = "`+expected+`"`) {
+			t.Fatalf("level %s: %s", level, str)
+		}
+	}
+}
+
+func TestParseBeegoInvalid(t *testing.T) {
+	for _, jsonConfig := range []string{
+		`{"filename":`,
+		`{"maxlines":10}`,
+		`{"filename":"app.log","level":8}`,
+		`{"filename":"app.log","level":"info"}`,
+		`{"filename":"app.log","maxlines":1.5}`,
+		`{"filename":"app.log","daily":[true]}`,
+	} {
+		if _, err := parseBeego(jsonConfig); err == nil {
+			t.Fatalf("%s is accepted", jsonConfig)
+		}
+		if _, err := NewLogrusMate(ConfigBeego(jsonConfig)); err == nil {
+			t.Fatalf("%s is accepted by the option", jsonConfig)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// the keys of file hook accepted by ConfigDSN, compact names are mapped to the config names
+var dsnFileKeys = map[string]string{
+	"daily":        "daily",
+	"hourly":       "hourly",
+	"rotate":       "rotate",
+	"maxdays":      "max-days",
+	"max-days":     "max-days",
+	"maxlines":     "max-lines",
+	"max-lines":    "max-lines",
+	"maxsize":      "max-size",
+	"max-size":     "max-size",
+	"perm":         "perm",
+	"rotateperm":   "rotate-perm",
+	"rotate-perm":  "rotate-perm",
+	"stripcolors":  "strip-colors",
+	"strip-colors": "strip-colors",
+}
+
+// ConfigDSN configures by a compact string for the simple cases, e.g.
+// file:///var/log/app.log?daily=true&maxdays=7&formatter=json&level=info
+// stdout://?formatter=text&level=debug
+// The schemes are file, stdout and stderr, level and formatter are accepted by all,
+// the file scheme writes by the file hook and accepts its options.
+func ConfigDSN(dsn string) Option {
+	return func(o *Config) {
+		str, err := parseDSN(dsn)
+		if err != nil {
+			o.err = err
+			return
+		}
+		ConfigString(str)(o)
+	}
+}
+
+func parseDSN(dsn string) (str string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		err = fmt.Errorf("logurs mate: bad dsn %q: %s", dsn, err)
+		return
+	}
+
+	query := u.Query()
+
+	b := &bytes.Buffer{}
+
+	level := query.Get("level")
+	if len(level) == 0 {
+		level = "info"
+	}
+
+	// the file hook filters by its own level, it follows the logger level
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(b, "level = %s\n", strconv.Quote(level))
+
+	formatter := query.Get("formatter")
+	if len(formatter) == 0 {
+		formatter = "text"
+	}
+	fmt.Fprintf(b, "formatter.name = %s\n", strconv.Quote(formatter))
+
+	var keys []string
+	for k := range query {
+		if k != "level" && k != "formatter" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	switch u.Scheme {
+	case "stdout", "stderr":
+		if len(keys) > 0 {
+			err = fmt.Errorf("logurs mate: dsn of %s does not accept %v", u.Scheme, keys)
+			return
+		}
+		fmt.Fprintf(b, "out.name = %s\n", strconv.Quote(u.Scheme))
+	case "file":
+		filename := u.Host + u.Path
+		if len(filename) == 0 {
+			err = fmt.Errorf("logurs mate: dsn %q has no filename", dsn)
+			return
+		}
+
+		fmt.Fprintf(b, "out.name = \"nil\"\n")
+		fmt.Fprintf(b, "hooks.file.filename = %s\n", strconv.Quote(filename))
+		fmt.Fprintf(b, "hooks.file.level = %d\n", lvl)
+
+		for _, k := range keys {
+			name, exist := dsnFileKeys[k]
+			if !exist {
+				err = fmt.Errorf("logurs mate: dsn of file does not accept %s", k)
+				return
+			}
+			fmt.Fprintf(b, "hooks.file.%s = %s\n", name, dsnValue(query.Get(k)))
+		}
+	default:
+		err = fmt.Errorf("logurs mate: dsn scheme should be file, stdout or stderr: %q", u.Scheme)
+		return
+	}
+
+	str = b.String()
+
+	return
+}
+
+// dsnValue keeps bool and number bare, quotes the others
+func dsnValue(v string) string {
+	if _, err := strconv.ParseBool(v); err == nil {
+		return v
+	}
+	if _, err := strconv.ParseInt(v, 10, 64); err == nil && (len(v) == 1 || v[0] != '0') {
+		return v
+	}
+	return strconv.Quote(v)
+}
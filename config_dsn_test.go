@@ -0,0 +1,50 @@
+package logrus_mate
+
+import (
+	"testing"
+)
+
+func TestParseDSN(t *testing.T) {
+	for dsn, expected := range map[string]string{
+		"stdout://?formatter=json&level=debug": `level = "debug"
+formatter.name = "json"
+out.name = "stdout"
+`,
+		"stderr://": `level = "info"
+formatter.name = "text"
+out.name = "stderr"
+`,
+		"file:///var/log/app.log?daily=true&maxdays=7&level=warn&perm=0640": `level = "warn"
+formatter.name = "text"
+out.name = "nil"
+hooks.file.filename = "/var/log/app.log"
+hooks.file.level = 3
+hooks.file.daily = true
+hooks.file.max-days = 7
+hooks.file.perm = "0640"
+`,
+	} {
+		str, err := parseDSN(dsn)
+		if err != nil {
+			t.Fatalf("%s: %s", dsn, err)
+		}
+		if str != expected {
+			t.Fatalf("%s parsed\n%s\nexpected\n%s", dsn, str, expected)
+		}
+	}
+}
+
+func TestParseDSNErrors(t *testing.T) {
+	for _, dsn := range []string{
+		"syslog://localhost",
+		"stdout://?daily=true",
+		"file://",
+		"file:///app.log?colour=true",
+		"file:///app.log?level=loud",
+		"://bad",
+	} {
+		if _, err := parseDSN(dsn); err == nil {
+			t.Fatalf("%s is accepted", dsn)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// includeRegexp matches a whole-line HOCON include directive, e.g.
+// include "base.conf" or include file("base.conf")
+var includeRegexp = regexp.MustCompile(`^\s*include\s+(?:file\(\s*"([^"]+)"\s*\)|"([^"]+)")\s*$`)
+
+// expandIncludes reads fn and replaces every include directive by the content of
+// the included file, resolved relative to the directory of the including file.
+// The result is parsed as one document, so objects with the same key are merged
+// and later definitions override earlier ones.
+func expandIncludes(fn string) (content string, hasInclude bool, err error) {
+	return expandIncludesWithStack(fn, nil)
+}
+
+func expandIncludesWithStack(fn string, stack []string) (content string, hasInclude bool, err error) {
+	absFn, err := filepath.Abs(fn)
+	if err != nil {
+		return
+	}
+
+	for _, visited := range stack {
+		if visited == absFn {
+			err = fmt.Errorf("logurs mate: cyclic config include: %s -> %s", strings.Join(stack, " -> "), absFn)
+			return
+		}
+	}
+
+	data, err := ioutil.ReadFile(absFn)
+	if err != nil {
+		if len(stack) > 0 {
+			err = fmt.Errorf("logurs mate: config include %s from %s: %v", fn, stack[len(stack)-1], err)
+		}
+		return
+	}
+
+	stack = append(stack, absFn)
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		matches := includeRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		hasInclude = true
+
+		includeFn := matches[1]
+		if len(includeFn) == 0 {
+			includeFn = matches[2]
+		}
+
+		if !filepath.IsAbs(includeFn) {
+			includeFn = filepath.Join(filepath.Dir(absFn), includeFn)
+		}
+
+		var included string
+		if included, _, err = expandIncludesWithStack(includeFn, stack); err != nil {
+			return
+		}
+
+		lines[i] = included
+	}
+
+	content = strings.Join(lines, "\n")
+
+	return
+}
@@ -0,0 +1,66 @@
+package logrus_mate
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func writeConfFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(fn, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestConfigFileInclude(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"base.conf": "level = \"debug\"\nformatter.name = \"json\"\n",
+		"app.conf":  "include \"base.conf\"\nlevel = \"warn\"\n",
+	})
+
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigFile(filepath.Join(dir, "app.conf"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// the later definitions override the included
+	if logger.Level != logrus.WarnLevel {
+		t.Fatalf("level %s, expected warn", logger.Level)
+	}
+	if _, ok := logger.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Fatalf("formatter %T, expected the json of base.conf", logger.Formatter)
+	}
+}
+
+func TestConfigFileIncludeMissing(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"app.conf": "include \"missing.conf\"\n",
+	})
+
+	err := Hijack(logrus.New(), ConfigFile(filepath.Join(dir, "app.conf")))
+	if err == nil || !strings.Contains(err.Error(), "missing.conf") || !strings.Contains(err.Error(), "app.conf") {
+		t.Fatalf("expected the error naming the missing and the including files, got %v", err)
+	}
+}
+
+func TestConfigFileIncludeCycle(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"a.conf": "include \"b.conf\"\n",
+		"b.conf": "include file(\"a.conf\")\n",
+	})
+
+	err := Hijack(logrus.New(), ConfigFile(filepath.Join(dir, "a.conf")))
+	if err == nil || !strings.Contains(err.Error(), "cyclic") {
+		t.Fatalf("expected the cyclic include error, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package logrus_mate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	remoteBackendsLocker = sync.Mutex{}
+	newRemoteBackendFunc = make(map[string]NewRemoteBackendFunc)
+)
+
+// RemoteBackend reads the config of a key in the KV store like etcd or consul,
+// the clients are registered by RegisterRemoteBackend, so mate needn't depend on them
+type RemoteBackend interface {
+	// Get returns the current value of key
+	Get(key string) ([]byte, error)
+	// Watch sends the new values of key until stop is closed, the channel
+	// is closed when the backend disconnected
+	Watch(key string, stop <-chan struct{}) (<-chan []byte, error)
+}
+
+// NewRemoteBackendFunc creates the backend of url, e.g. etcd://127.0.0.1:2379/logging
+type NewRemoteBackendFunc func(u *url.URL) (RemoteBackend, error)
+
+func RegisterRemoteBackend(scheme string, newBackendFunc NewRemoteBackendFunc) {
+	remoteBackendsLocker.Lock()
+	defer remoteBackendsLocker.Unlock()
+
+	if scheme == "" {
+		panic("logurs mate: Register remote backend scheme is empty")
+	}
+
+	if newBackendFunc == nil {
+		panic("logurs mate: Register remote backend is nil")
+	}
+
+	if _, exist := newRemoteBackendFunc[scheme]; exist {
+		panic("logurs mate: Register called twice for remote backend " + scheme)
+	}
+
+	newRemoteBackendFunc[scheme] = newBackendFunc
+}
+
+// remoteSource is the key watched by the mate created with ConfigRemote
+type remoteSource struct {
+	url     string
+	key     string
+	backend RemoteBackend
+	retry   time.Duration
+}
+
+// ConfigRemote loads config from the key of a KV store, the url is like
+// etcd://127.0.0.1:2379/config/logging?retry=5s, the path is the key.
+// NewLogrusMate watches the key and applies the updates to its loggers live,
+// the last good config is kept while the value is invalid or the backend is
+// disconnected, the watch is resumed after retry (default 5s).
+func ConfigRemote(rawURL string) Option {
+	return func(o *Config) {
+		source, err := newRemoteSource(rawURL)
+		if err != nil {
+			o.err = err
+			return
+		}
+
+		value, err := source.backend.Get(source.key)
+		if err != nil {
+			o.err = fmt.Errorf("logurs mate: remote config %s: %s", rawURL, err)
+			return
+		}
+
+		if _, err = parseRemoteConfig(value); err != nil {
+			o.err = fmt.Errorf("logurs mate: remote config %s: %s", rawURL, err)
+			return
+		}
+
+		o.configOpts = append(o.configOpts, config.ConfigString(string(value)))
+		o.remotes = append(o.remotes, source)
+	}
+}
+
+func newRemoteSource(rawURL string) (source *remoteSource, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+
+	remoteBackendsLocker.Lock()
+	newBackend, exist := newRemoteBackendFunc[u.Scheme]
+	remoteBackendsLocker.Unlock()
+
+	if !exist {
+		err = errors.New("logurs mate: remote backend not registerd: " + u.Scheme)
+		return
+	}
+
+	source = &remoteSource{url: rawURL, key: u.Path, retry: 5 * time.Second}
+
+	if retry := u.Query().Get("retry"); len(retry) > 0 {
+		if source.retry, err = time.ParseDuration(retry); err != nil || source.retry <= 0 {
+			err = fmt.Errorf("logurs mate: remote config %s: invalid retry %q", rawURL, retry)
+			return
+		}
+	}
+
+	if source.backend, err = newBackend(u); err != nil {
+		err = fmt.Errorf("logurs mate: remote config %s: %s", rawURL, err)
+		return
+	}
+
+	return
+}
+
+// parseRemoteConfig parses the value, the provider could panic on the bad input
+func parseRemoteConfig(value []byte) (conf config.Configuration, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse config: %v", r)
+		}
+	}()
+
+	c := config.NewConfig(config.ConfigString(string(value)))
+	if c == nil {
+		err = errors.New("parse config: empty")
+		return
+	}
+
+	return c, nil
+}
+
+// watchRemote applies the values of source until stop is closed
+func (p *LogrusMate) watchRemote(source *remoteSource, stop <-chan struct{}) {
+	for {
+		values, err := source.backend.Watch(source.key, stop)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logurs mate: watch remote config %s: %s, keep the last config\n", source.url, err)
+		} else {
+			for value := range values {
+				p.applyRemote(source, value)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(source.retry):
+		}
+	}
+}
+
+// applyRemote stores the logger configs of value, and hijacks the created loggers in place.
+// The logger failed to hijack keeps its last config. The loggers absent from value are kept,
+// and the hooks of the replaced configs are not closed since they could be shared.
+func (p *LogrusMate) applyRemote(source *remoteSource, value []byte) {
+	conf, err := parseRemoteConfig(value)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "logurs mate: remote config %s: %s, keep the last config\n", source.url, err)
+		return
+	}
+
+	for _, name := range conf.Keys() {
+		loggerConf := conf.GetConfig(name)
+
+		if lv, exist := p.loggers.Load(name); exist {
+			if err = hijackByConfig(lv.(*logrus.Logger), loggerConf); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "logurs mate: remote config %s of logger %s: %s, keep the last config\n", source.url, name, err)
+				continue
+			}
+		}
+
+		p.loggersConf.Store(name, loggerConf)
+	}
+}
+
+// StopRemote stops watching the keys of ConfigRemote
+func (p *LogrusMate) StopRemote() {
+	p.remoteStopOnce.Do(func() {
+		if p.remoteStop != nil {
+			close(p.remoteStop)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package logrus_mate
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterRemoteBackend("mockkv", func(u *url.URL) (RemoteBackend, error) {
+		b, exist := mockKVs.Load(u.Host)
+		if !exist {
+			return nil, errors.New("mock kv not found: " + u.Host)
+		}
+		return b.(*mockKV), nil
+	})
+}
+
+var mockKVs sync.Map
+
+// mockKV is the KV backend of tests, the watches are served by the channels
+// passed to Watch in order, closing one simulates the disconnection
+type mockKV struct {
+	locker  sync.Mutex
+	values  map[string][]byte
+	watches chan chan []byte
+}
+
+func newMockKV(host string, key string, value string) *mockKV {
+	kv := &mockKV{values: map[string][]byte{key: []byte(value)}, watches: make(chan chan []byte, 10)}
+	mockKVs.Store(host, kv)
+	return kv
+}
+
+func (p *mockKV) Get(key string) ([]byte, error) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	value, exist := p.values[key]
+	if !exist {
+		return nil, errors.New("key not found: " + key)
+	}
+	return value, nil
+}
+
+func (p *mockKV) Watch(key string, stop <-chan struct{}) (<-chan []byte, error) {
+	select {
+	case ch := <-p.watches:
+		return ch, nil
+	default:
+		return nil, errors.New("connection refused")
+	}
+}
+
+// connect serves the next Watch
+func (p *mockKV) connect() chan []byte {
+	ch := make(chan []byte)
+	p.watches <- ch
+	return ch
+}
+
+func waitLevel(t *testing.T, logger *logrus.Logger, expected logrus.Level) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logger.GetLevel() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("level %s, expected %s", logger.GetLevel(), expected)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConfigRemoteLiveUpdates(t *testing.T) {
+	kv := newMockKV("live", "/config/logging", `mike { level = "info", out.name = "nil" }`)
+	watch := kv.connect()
+
+	mate, err := NewLogrusMate(ConfigRemote("mockkv://live/config/logging?retry=10ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mate.StopRemote()
+
+	logger := mate.Logger("mike")
+	if logger == nil || logger.GetLevel() != logrus.InfoLevel {
+		t.Fatalf("logger of the remote config %v", logger)
+	}
+
+	// the logger keeps logging while hijacked again
+	done := make(chan struct{})
+	logging := make(chan struct{})
+	go func() {
+		defer close(logging)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				logger.WithField("n", 1).Debug("while updating")
+			}
+		}
+	}()
+
+	watch <- []byte(`mike { level = "debug", out.name = "nil" }, jack { level = "warn", out.name = "nil" }`)
+	waitLevel(t, logger, logrus.DebugLevel)
+
+	close(done)
+	<-logging
+
+	// the logger is hijacked in place, the new logger is created by the new config
+	if mate.Logger("mike") != logger {
+		t.Fatal("the logger is replaced")
+	}
+	if jack := mate.Logger("jack"); jack == nil || jack.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("jack of the update %v", jack)
+	}
+
+	// the invalid logger config is skipped, the last good one is kept
+	watch <- []byte(`mike { level = "verbose", out.name = "nil" }`)
+	watch <- []byte(`jack { level = "error", out.name = "nil" }`)
+	waitLevel(t, mate.Logger("jack"), logrus.ErrorLevel)
+	if level := logger.GetLevel(); level != logrus.DebugLevel {
+		t.Fatalf("level %s after the invalid update", level)
+	}
+}
+
+func TestConfigRemoteReconnect(t *testing.T) {
+	kv := newMockKV("reconnect", "/logging", `mike { level = "info", out.name = "nil" }`)
+	watch := kv.connect()
+
+	mate, err := NewLogrusMate(ConfigRemote("mockkv://reconnect/logging?retry=10ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mate.StopRemote()
+
+	logger := mate.Logger("mike")
+
+	// disconnected, the watch fails until the backend is back
+	close(watch)
+	time.Sleep(50 * time.Millisecond)
+	if level := logger.GetLevel(); level != logrus.InfoLevel {
+		t.Fatalf("level %s while disconnected", level)
+	}
+
+	watch = kv.connect()
+	watch <- []byte(`mike { level = "trace", out.name = "nil" }`)
+	waitLevel(t, logger, logrus.TraceLevel)
+}
+
+func TestConfigRemoteStop(t *testing.T) {
+	kv := newMockKV("stop", "/logging", `mike { level = "info", out.name = "nil" }`)
+	kv.connect()
+
+	mate, err := NewLogrusMate(ConfigRemote("mockkv://stop/logging?retry=10ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mate.StopRemote()
+	mate.StopRemote()
+}
+
+func TestConfigRemoteInvalid(t *testing.T) {
+	newMockKV("invalid", "/logging", `mike { level = "info" }`)
+
+	for _, rawURL := range []string{
+		"unknown://invalid/logging",
+		"mockkv://missing/logging",
+		"mockkv://invalid/absent",
+		"mockkv://invalid/logging?retry=never",
+		"mockkv://invalid/logging?retry=-1s",
+	} {
+		if _, err := NewLogrusMate(ConfigRemote(rawURL)); err == nil {
+			t.Fatalf("%s is accepted", rawURL)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+)
+
+type schema map[string]interface{}
+
+func schemaOf(typ interface{}, description string, def interface{}) schema {
+	s := schema{"type": typ}
+	if len(description) > 0 {
+		s["description"] = description
+	}
+	if def != nil {
+		s["default"] = def
+	}
+	return s
+}
+
+func objectSchema(properties map[string]schema) schema {
+	return schema{"type": "object", "properties": properties}
+}
+
+// the HOCON durations are written as "30s" or milliseconds
+var durationType = []string{"string", "integer"}
+
+func namedSchema(description, def string, options schema) schema {
+	return objectSchema(map[string]schema{
+		"name":    schemaOf("string", description, def),
+		"options": options,
+	})
+}
+
+// formatterSchema is the named formatter with the fallback used when it fails
+func formatterSchema() schema {
+	s := namedSchema("formatter name", "text", formatterOptionsSchema())
+	s["properties"].(map[string]schema)["fallback"] = namedSchema("formatter used when the primary fails", "text", formatterOptionsSchema())
+	return s
+}
+
+func formatterOptionsSchema() schema {
+	return schema{
+		"type": "object",
+		"description": "options of the formatter, the text and json options are listed, " +
+			"other formatters have their own",
+		"properties": map[string]schema{
+			"force-colors":        schemaOf("boolean", "text", false),
+			"disable-colors":      schemaOf("boolean", "text", false),
+			"disable-timestamp":   schemaOf("boolean", "text", false),
+			"full-timestamp":      schemaOf("boolean", "text", false),
+			"timestamp-format":    schemaOf("string", "text", nil),
+			"disable-sorting":     schemaOf("boolean", "text", false),
+			"expand-errors":       schemaOf("boolean", "text", false),
+			"timestamp_format":    schemaOf("string", "json", nil),
+			"caller_prettyfier":   schema{"type": "string", "description": "json", "enum": []string{"full", "short", "package"}},
+			"large_int_as_string": schemaOf("boolean", "json", false),
+		},
+	}
+}
+
+func fileHookSchema() schema {
+	return objectSchema(map[string]schema{
+		"filename":          schemaOf("string", "", "logs/logrus.log"),
+		"level":             schemaOf("integer", "max level written, 0 panic ... 5 debug", 0),
+		"strip-colors":      schemaOf("boolean", "", true),
+		"daily":             schemaOf("boolean", "", true),
+		"hourly":            schemaOf("boolean", "", true),
+		"max-days":          schemaOf("integer", "", 7),
+		"max-files":         schemaOf("integer", "keep the newest rotated files, 0 is unlimited", 0),
+		"rotate":            schemaOf("boolean", "", true),
+		"readonly-buffer":   schemaOf("integer", "bytes held while the filesystem is read-only, 0 disables", 0),
+		"readonly-probe":    schemaOf(durationType, "", "10s"),
+		"coalesce-bytes":    schemaOf("integer", "write the messages at once by the bytes, 0 writes each", 0),
+		"coalesce-interval": schemaOf(durationType, "the coalesced messages are written after", "100ms"),
+		"pid-suffix":        schemaOf("boolean", "write logs/app.<pid>.log per process", false),
+		"always-number":     schemaOf("boolean", "name the first rotated file with .001 too", false),
+		"rotate-mode":       schema{"type": "string", "enum": []string{"round-robin"}, "description": "write logs/app.0.log ... app.<slots-1>.log in cycle"},
+		"slots":             schemaOf("integer", "the files of round-robin, 2 or more", 0),
+		"encrypt-key":       schemaOf("string", "base64 AES key, compress and encrypt the rotated files", nil),
+		"max-lines":         schemaOf("integer", "", 10000),
+		"max-size":          schemaOf("integer", "", 1024),
+		"perm":              schemaOf("string", "", "0660"),
+		"rotate-perm":       schemaOf("string", "", "0440"),
+		"stderr-fallback":   schemaOf("boolean", "", false),
+		"min-free-bytes":    schemaOf("integer", "", 0),
+		"min-free-percent":  schemaOf("number", "", 0),
+		"check-interval":    schemaOf(durationType, "", "10s"),
+		"rotate-cron":       schemaOf("string", "e.g. \"0 3 * * *\" or @daily", nil),
+		"truncate":          schemaOf("boolean", "", false),
+		"max-open-age":      schemaOf(durationType, "", "0s"),
+		"line-terminator":   schemaOf("string", "e.g. \"\\r\\n\" or \"\\u0000\"", "\n"),
+		"index":             schemaOf("boolean", "write the offsets into the sidecar <filename>.idx", false),
+		"index-every":       schemaOf("integer", "", 1000),
+		"strip-max-len":     schemaOf("integer", "the longer messages are stripped by a byte scan, 0 always by regexp", 65536),
+		"open-retries":      schemaOf("integer", "retries of the initial open", 0),
+		"open-backoff":      schemaOf(durationType, "doubled after each retry", "100ms"),
+		"audit-chain":       schemaOf("boolean", "chain every record to the previous by prev_hash", false),
+		"audit-hash":        schema{"type": "string", "enum": []string{"sha256", "sha512"}, "default": "sha256"},
+	})
+}
+
+func hookSchema() schema {
+	return schema{
+		"type":        "object",
+		"description": "options of the hook, all hooks accept enabled, breaker and when",
+		"properties": map[string]schema{
+			"enabled": schemaOf("boolean", "", true),
+			"breaker": objectSchema(map[string]schema{
+				"failure-threshold": schemaOf("integer", "consecutive failures opening the breaker", 5),
+				"open-duration":     schemaOf(durationType, "the hook is not fired while open", "30s"),
+			}),
+			"when": schema{
+				"type":        "object",
+				"description": "field predicates, e.g. when { alert.equals = true }",
+				"additionalProperties": objectSchema(map[string]schema{
+					"equals":   schemaOf([]string{"string", "number", "boolean"}, "", nil),
+					"contains": schemaOf("string", "", nil),
+					"matches":  schemaOf("string", "regexp", nil),
+				}),
+			},
+		},
+		"additionalProperties": true,
+	}
+}
+
+func loggerSchema() schema {
+	hooks := schema{
+		"type":                 "object",
+		"description":          "hooks by name",
+		"properties":           map[string]schema{"file": fileHookSchema()},
+		"additionalProperties": hookSchema(),
+	}
+
+	return objectSchema(map[string]schema{
+		"level": schema{
+			"type":    "string",
+			"enum":    []string{"panic", "fatal", "error", "warn", "warning", "info", "debug", "trace"},
+			"default": "info",
+		},
+		"enabled":        schemaOf("boolean", "", true),
+		"report-caller":  schemaOf("boolean", "", false),
+		"strict-hooks":   schemaOf("boolean", "", false),
+		"buffer-pool":    schemaOf("boolean", "", false),
+		"nolock":         schemaOf("boolean", "", false),
+		"startup-banner": schemaOf("boolean", "", false),
+		"tags":           schema{"type": "array", "description": "attached to every entry as a list", "items": schemaOf("string", "", nil)},
+		"tags-field":     schemaOf("string", "", "tags"),
+		"transforms":     schema{"type": "array", "description": "the registered transforms run in order before the hooks", "items": schemaOf("string", "", nil)},
+		"timer-level":    schemaOf("string", "the level of the entries logged by Timer", "info"),
+		"level-aliases": schema{
+			"type":                 "object",
+			"description":          "domain severity names to logrus levels, e.g. notice = \"info\"",
+			"additionalProperties": schemaOf("string", "", nil),
+		},
+		"encoding": schema{
+			"type":        "string",
+			"description": "utf8 replaces the invalid sequences, ascii escapes the non-ASCII chars",
+			"enum":        []string{"utf8", "ascii"},
+		},
+		"empty-message": schema{
+			"description": "the entries of empty message, \"omit\" removes the msg key, or { default = \"event\" }",
+			"oneOf": []schema{
+				schema{"type": "string", "enum": []string{"keep", "omit"}, "default": "keep"},
+				objectSchema(map[string]schema{
+					"default": schemaOf("string", "the message substituted", nil),
+				}),
+			},
+		},
+		"mirror": schema{
+			"type": "string",
+			"enum": []string{"to-standard", "from-standard", "both"},
+		},
+		"out":       namedSchema("writer name", "stdout", schemaOf("object", "options of the writer", nil)),
+		"formatter": formatterSchema(),
+		"hooks":     hooks,
+		"fanout": schema{
+			"type":        "object",
+			"description": "outputs by name, each formats and writes every entry, in place of out and formatter",
+			"additionalProperties": objectSchema(map[string]schema{
+				"out":       namedSchema("writer name", "stdout", schemaOf("object", "", nil)),
+				"formatter": formatterSchema(),
+				"primary":   schemaOf("boolean", "written before the others", false),
+			}),
+		},
+		"sample": objectSchema(map[string]schema{
+			"burst":       schemaOf("integer", "", 10),
+			"sample-rate": schemaOf("integer", "", 100),
+			"reset-after": schemaOf(durationType, "", "1m"),
+			"key-field":   schemaOf("string", "", nil),
+		}),
+		"route": objectSchema(map[string]schema{
+			"field":     schemaOf("string", "", "_route"),
+			"exclusive": schemaOf("boolean", "", true),
+			"targets": schema{
+				"type": "object",
+				"additionalProperties": objectSchema(map[string]schema{
+					"out":       namedSchema("writer name", "stdout", schemaOf("object", "", nil)),
+					"formatter": formatterSchema(),
+				}),
+			},
+		}),
+		"template": objectSchema(map[string]schema{
+			"marker": schemaOf("string", "prefix of the template messages", nil),
+			"missing": schema{
+				"type":    "string",
+				"enum":    []string{"mark", "literal"},
+				"default": "mark",
+			},
+		}),
+		"field-case": schema{
+			"description": "normalizes the field keys, e.g. \"snake\" or { convention = \"snake\", collision = \"suffix\" }",
+			"oneOf": []schema{
+				schema{"type": "string", "enum": []string{"snake", "camel", "lower"}},
+				objectSchema(map[string]schema{
+					"convention": schema{"type": "string", "enum": []string{"snake", "camel", "lower"}},
+					"collision": schema{
+						"type":    "string",
+						"enum":    []string{"merge", "suffix"},
+						"default": "merge",
+					},
+				}),
+			},
+		},
+		"flatten": schema{
+			"description": "flattens the map fields into the top level keys, e.g. true or { separator = \".\", arrays = \"index\" }",
+			"oneOf": []schema{
+				schemaOf("boolean", "", false),
+				objectSchema(map[string]schema{
+					"separator": schemaOf("string", "", "."),
+					"arrays": schema{
+						"type":    "string",
+						"enum":    []string{"index", "json", "keep"},
+						"default": "index",
+					},
+				}),
+			},
+		},
+		"max-field-len": schema{
+			"description": "truncates the long string values, e.g. 1024 or { default = 1024, fields { body = 4096 } }",
+			"oneOf": []schema{
+				schemaOf("integer", "", nil),
+				objectSchema(map[string]schema{
+					"default": schemaOf("integer", "", 0),
+					"fields": schema{
+						"type":                 "object",
+						"description":          "limits by field, 0 is unlimited",
+						"additionalProperties": schemaOf("integer", "", nil),
+					},
+				}),
+			},
+		},
+		"relevel": objectSchema(map[string]schema{
+			"rules": schema{
+				"type":        "object",
+				"description": "rules by name, the first matched in name order rewrites the level",
+				"additionalProperties": objectSchema(map[string]schema{
+					"match":   schemaOf("string", "regexp of the message", nil),
+					"package": schemaOf("string", "prefix of the logging package", nil),
+					"from":    schema{"type": "array", "description": "the original levels", "items": schemaOf("string", "", nil)},
+					"level":   schemaOf("string", "the new level", nil),
+				}),
+			},
+		}),
+		"level-override": objectSchema(map[string]schema{
+			"field": schemaOf("string", "the entries with it set pass above the level", "debug_override"),
+			"level": schemaOf("string", "the most verbose level passed by the override", "debug"),
+		}),
+		"quiet-hours": objectSchema(map[string]schema{
+			"level":    schemaOf("string", "the min level written within the windows", "error"),
+			"windows":  schema{"type": "array", "items": schemaOf("string", "like 22:00-06:00", nil)},
+			"timezone": schemaOf("string", "", nil),
+		}),
+		"batch": objectSchema(map[string]schema{
+			"ttl":       schemaOf(durationType, "the batch never flushed is written after", "1m"),
+			"max-steps": schemaOf("integer", "", 100),
+			"field":     schemaOf("string", "", "steps"),
+		}),
+		"heartbeat": objectSchema(map[string]schema{
+			"interval": schemaOf(durationType, "", "1m"),
+			"message":  schemaOf("string", "", "heartbeat"),
+			"level":    schemaOf("string", "", "info"),
+		}),
+	})
+}
+
+// ConfigSchema returns the JSON Schema of mate config, the loggers by name, for
+// editors and CI to validate the configs. It should be updated with the keys parsed.
+func ConfigSchema() []byte {
+	s := schema{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "logrus_mate config",
+		"type":                 "object",
+		"additionalProperties": loggerSchema(),
+	}
+
+	data, _ := json.MarshalIndent(s, "", "  ")
+
+	return data
+}
@@ -0,0 +1,170 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogap/config"
+)
+
+// validateKey validates the value of key in conf against s, the subset of JSON
+// Schema used by ConfigSchema. It is stricter than JSON Schema: the keys not in
+// the properties of an object are reported, so the typos in the configs and the
+// keys missing from the schema are found.
+func validateKey(conf config.Configuration, path, key string, s schema) (errs []string) {
+	keyPath := strings.TrimPrefix(path+"."+key, ".")
+
+	if alternatives, exist := s["oneOf"].([]schema); exist {
+		for _, alternative := range alternatives {
+			if len(validateKey(conf, path, key, alternative)) == 0 {
+				return nil
+			}
+		}
+		return []string{keyPath + ": matches none of oneOf"}
+	}
+
+	types := schemaTypes(s)
+
+	switch {
+	case conf.IsObject(key):
+		if !types["object"] {
+			return []string{keyPath + ": object is not allowed"}
+		}
+		return validateObject(conf.GetConfig(key), keyPath, s)
+	case conf.IsArray(key):
+		if !types["array"] {
+			return []string{keyPath + ": array is not allowed"}
+		}
+		return nil
+	}
+
+	value := conf.GetString(key)
+
+	if enum, exist := s["enum"].([]string); exist {
+		for _, v := range enum {
+			if v == value {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %q is not in %v", keyPath, value, enum)}
+	}
+
+	_, errInt := strconv.ParseInt(value, 10, 64)
+	_, errFloat := strconv.ParseFloat(value, 64)
+
+	if types["string"] ||
+		(types["boolean"] && (value == "true" || value == "false")) ||
+		(types["integer"] && errInt == nil) ||
+		(types["number"] && errFloat == nil) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s: %q is not %v", keyPath, value, s["type"])}
+}
+
+func validateObject(conf config.Configuration, path string, s schema) (errs []string) {
+	properties, _ := s["properties"].(map[string]schema)
+
+	for _, key := range conf.Keys() {
+		if property, exist := properties[key]; exist {
+			errs = append(errs, validateKey(conf, path, key, property)...)
+			continue
+		}
+
+		switch additional := s["additionalProperties"].(type) {
+		case schema:
+			errs = append(errs, validateKey(conf, path, key, additional)...)
+		case bool:
+			if !additional {
+				errs = append(errs, strings.TrimPrefix(path+"."+key, ".")+": unknown key")
+			}
+		default:
+			// the object without properties, e.g. the options of writer, is free form
+			if properties != nil {
+				errs = append(errs, strings.TrimPrefix(path+"."+key, ".")+": unknown key")
+			}
+		}
+	}
+
+	return
+}
+
+func schemaTypes(s schema) map[string]bool {
+	types := make(map[string]bool)
+	switch typ := s["type"].(type) {
+	case string:
+		types[typ] = true
+	case []string:
+		for _, t := range typ {
+			types[t] = true
+		}
+	}
+	return types
+}
+
+// validateConfig validates the mate config of loggers by the schema of ConfigSchema
+func validateConfig(conf config.Configuration) []string {
+	return validateObject(conf, "", schema{"type": "object", "additionalProperties": loggerSchema()})
+}
+
+func TestConfigSchemaJSON(t *testing.T) {
+	var s map[string]interface{}
+	if err := json.Unmarshal(ConfigSchema(), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, ok := s["additionalProperties"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("no logger schema: %v", s)
+	}
+
+	properties := logger["properties"].(map[string]interface{})
+	for _, key := range []string{"level", "out", "formatter", "hooks"} {
+		if _, exist := properties[key]; !exist {
+			t.Errorf("key %s is missing", key)
+		}
+	}
+}
+
+func TestConfigSchemaExamples(t *testing.T) {
+	for _, filename := range []string{"example/mate.conf", "example/mate.conf.example", "example/mate2.conf.example"} {
+		conf := config.NewConfig(config.ConfigFile(filename))
+		if conf.IsEmpty() {
+			t.Fatalf("%s is empty", filename)
+		}
+
+		if errs := validateConfig(conf); len(errs) > 0 {
+			t.Errorf("%s: %v", filename, errs)
+		}
+	}
+}
+
+func TestConfigSchemaReportsInvalid(t *testing.T) {
+	conf := configOf(`
+		mike {
+			level = "loud"
+			report-callr = true
+			nolock = "sometimes"
+			hooks.file.max-files = "ten"
+			sample { burst = 10 }
+		}`)
+
+	errs := validateConfig(conf)
+
+	for _, expected := range []string{"mike.level", "mike.report-callr", "mike.nolock", "mike.hooks.file.max-files"} {
+		found := false
+		for _, err := range errs {
+			found = found || strings.HasPrefix(err, expected+":")
+		}
+		if !found {
+			t.Errorf("%s is not reported: %v", expected, errs)
+		}
+	}
+
+	if len(errs) != 4 {
+		t.Fatalf("errors %v", errs)
+	}
+}
@@ -0,0 +1,53 @@
+package logrus_mate
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextFieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying the fields, the entries logged
+// with the context by WithContext get the fields merged. The fields of ctx are
+// inherited, the same key is overridden by the inner fields.
+func ContextWithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := make(logrus.Fields, len(fields))
+
+	for k, v := range FieldsFromContext(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stashed by ContextWithFields, it must not be modified
+func FieldsFromContext(ctx context.Context) logrus.Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(contextFieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// contextFieldsHook merges the fields of entry context into entry data,
+// the fields given by WithFields take precedence
+type contextFieldsHook struct{}
+
+func (p *contextFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *contextFieldsHook) Fire(entry *logrus.Entry) error {
+	for k, v := range FieldsFromContext(entry.Context) {
+		if _, exist := entry.Data[k]; !exist {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package logrus_mate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestContextWithFieldsNested(t *testing.T) {
+	logger, _ := hijackString(t, `hooks { test-record { id = "context" } }`)
+
+	outer := ContextWithFields(context.Background(), logrus.Fields{"request": "r1", "user": "alice"})
+	inner := ContextWithFields(outer, logrus.Fields{"user": "bob", "span": "s1"})
+
+	logger.WithContext(inner).Info("inner")
+	logger.WithContext(outer).Info("outer")
+	logger.WithContext(inner).WithField("user", "carol").Info("explicit")
+	logger.Info("no context")
+
+	entries := recordedBy(t, "context").Entries()
+	if len(entries) != 4 {
+		t.Fatalf("entries %v", entries)
+	}
+
+	expected := []logrus.Fields{
+		{"request": "r1", "user": "bob", "span": "s1"},
+		{"request": "r1", "user": "alice"},
+		{"request": "r1", "user": "carol", "span": "s1"},
+		{},
+	}
+
+	for i, fields := range expected {
+		if len(entries[i].Data) != len(fields) {
+			t.Fatalf("%s: fields %v, expected %v", entries[i].Message, entries[i].Data, fields)
+		}
+		for k, v := range fields {
+			if entries[i].Data[k] != v {
+				t.Fatalf("%s: fields %v, expected %v", entries[i].Message, entries[i].Data, fields)
+			}
+		}
+	}
+}
+
+func TestContextWithFieldsOuterUnchanged(t *testing.T) {
+	outer := ContextWithFields(context.Background(), logrus.Fields{"user": "alice"})
+	ContextWithFields(outer, logrus.Fields{"user": "bob", "span": "s1"})
+
+	fields := FieldsFromContext(outer)
+	if len(fields) != 1 || fields["user"] != "alice" {
+		t.Fatalf("the outer fields are modified: %v", fields)
+	}
+
+	if fields = FieldsFromContext(context.Background()); fields != nil {
+		t.Fatalf("fields of empty context %v", fields)
+	}
+}
@@ -0,0 +1,29 @@
+package logrus_mate
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// droppedKey marks the entry dropped by sampler, relevel or routed away from the logger,
+// the hooks configured by mate skip it and the dropFormatter outputs nothing
+const droppedKey = "_dropped"
+
+func markDropped(entry *logrus.Entry) {
+	entry.Data[droppedKey] = true
+}
+
+func isDropped(entry *logrus.Entry) bool {
+	_, dropped := entry.Data[droppedKey]
+	return dropped
+}
+
+type dropFormatter struct {
+	logrus.Formatter
+}
+
+func (p *dropFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if isDropped(entry) {
+		return nil, nil
+	}
+	return p.Formatter.Format(entry)
+}
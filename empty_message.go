@@ -0,0 +1,177 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// the message key of the json and text formatters
+const messageKey = "msg"
+
+// emptyMessageFormatter handles the entries of empty message, e.g. the structured
+// events carrying only fields. With omit the msg key is removed from the json
+// object and the text line, with the default the message is substituted.
+type emptyMessageFormatter struct {
+	logrus.Formatter
+	omit          bool
+	defaultString string
+}
+
+// parseEmptyMessage parses empty-message = "omit", "keep" or
+// empty-message { default = "event" }
+func parseEmptyMessage(conf config.Configuration) (omit bool, defaultString string, err error) {
+	if conf.IsObject("empty-message") {
+		defaultString = conf.GetConfig("empty-message").GetString("default")
+		if len(defaultString) == 0 {
+			err = fmt.Errorf("logurs mate: empty-message default is empty")
+		}
+		return
+	}
+
+	switch mode := conf.GetString("empty-message", "keep"); mode {
+	case "keep":
+	case "omit":
+		omit = true
+	default:
+		err = fmt.Errorf("logurs mate: empty-message should be omit, keep or { default = \"...\" }, but got %q", mode)
+	}
+
+	return
+}
+
+func (p *emptyMessageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if len(entry.Message) > 0 {
+		return p.Formatter.Format(entry)
+	}
+
+	if !p.omit {
+		// the entry is shared by the other outputs, the message is set on a copy
+		defaultEntry := *entry
+		defaultEntry.Message = p.defaultString
+		return p.Formatter.Format(&defaultEntry)
+	}
+
+	serialized, err := p.Formatter.Format(entry)
+	if err != nil {
+		return serialized, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimLeft(serialized, " \t\r\n"), []byte{'{'}) {
+		return omitJSONMessage(serialized), nil
+	}
+
+	return omitTextMessage(serialized), nil
+}
+
+// omitTextMessage removes ` msg=` or ` msg=""` of the text line, it is the first as
+// the fields follow it, the colored line has no key and is kept as it is
+func omitTextMessage(line []byte) []byte {
+	for _, token := range []string{" " + messageKey + "=\"\"", " " + messageKey + "="} {
+		i := bytes.Index(line, []byte(token))
+		if i < 0 {
+			continue
+		}
+
+		end := i + len(token)
+		if end < len(line) && line[end] != ' ' && line[end] != '\n' {
+			continue
+		}
+
+		return append(line[:i:i], line[end:]...)
+	}
+
+	return line
+}
+
+// omitJSONMessage removes "msg":"" of the top level object, the keys of the nested
+// objects and the strings escaping it are skipped
+func omitJSONMessage(data []byte) []byte {
+	depth := 0
+	// the end of the last comma or opening brace of the top level
+	sep := -1
+
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '{', '[':
+			depth++
+			if depth == 1 {
+				sep = i + 1
+			}
+		case '}', ']':
+			depth--
+		case ',':
+			if depth == 1 {
+				sep = i + 1
+			}
+		case '"':
+			end := jsonStringEnd(data, i)
+			if end < 0 {
+				return data
+			}
+
+			if depth == 1 && sep >= 0 && len(bytes.TrimSpace(data[sep:i])) == 0 && string(data[i+1:end]) == messageKey {
+				if valueEnd, ok := jsonEmptyValue(data, end+1); ok {
+					return removeJSONMember(data, sep, valueEnd)
+				}
+			}
+
+			i = end
+		}
+	}
+
+	return data
+}
+
+// jsonStringEnd returns the index of the closing quote of the string starting at i
+func jsonStringEnd(data []byte, i int) int {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j
+		}
+	}
+	return -1
+}
+
+// jsonEmptyValue reports whether `: ""` follows at i, and returns the end of it
+func jsonEmptyValue(data []byte, i int) (int, bool) {
+	i = skipJSONSpace(data, i)
+	if i >= len(data) || data[i] != ':' {
+		return 0, false
+	}
+
+	i = skipJSONSpace(data, i+1)
+	if i+1 >= len(data) || data[i] != '"' || data[i+1] != '"' {
+		return 0, false
+	}
+
+	return i + 2, true
+}
+
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\r' || data[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// removeJSONMember removes the member between start and end with the comma after
+// it, or before it when it is the last
+func removeJSONMember(data []byte, start, end int) []byte {
+	after := skipJSONSpace(data, end)
+	if after < len(data) && data[after] == ',' {
+		return append(data[:start:start], data[after+1:]...)
+	}
+
+	// the last member, the comma before it goes too, the space before the brace is kept
+	if data[start-1] == ',' {
+		start--
+	}
+
+	return append(data[:start:start], data[end:]...)
+}
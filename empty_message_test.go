@@ -0,0 +1,103 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestEmptyMessageOmitJSON(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+empty-message = "omit"
+formatter.name = "json"`)
+
+	logger.WithFields(logrus.Fields{"event": "signup", "user": "mike"}).Info("")
+
+	m := jsonLine(t, buf)
+	if _, exist := m["msg"]; exist {
+		t.Fatalf("msg key in %q", buf.String())
+	}
+	if m["event"] != "signup" || m["user"] != "mike" || m["level"] != "info" {
+		t.Fatalf("line %v", m)
+	}
+
+	// the message not empty is kept
+	buf.Reset()
+	logger.WithField("event", "login").Info("welcome")
+	if m = jsonLine(t, buf); m["msg"] != "welcome" {
+		t.Fatalf("line %v", m)
+	}
+}
+
+func TestEmptyMessageOmitText(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+empty-message = "omit"
+formatter { name = "text", options { disable-colors = true, disable-timestamp = true } }`)
+
+	logger.WithField("event", "signup").Info("")
+
+	if s := buf.String(); s != "level=info event=signup\n" {
+		t.Fatalf("line %q", s)
+	}
+}
+
+func TestEmptyMessageDefault(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+empty-message { default = "event" }
+formatter.name = "json"`)
+
+	logger.WithField("event", "signup").Info("")
+
+	if m := jsonLine(t, buf); m["msg"] != "event" {
+		t.Fatalf("line %v", m)
+	}
+}
+
+func TestEmptyMessageKeep(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+formatter.name = "json"`)
+
+	logger.WithField("event", "signup").Info("")
+
+	if m := jsonLine(t, buf); m["msg"] != "" {
+		t.Fatalf("line %v", m)
+	}
+}
+
+func TestOmitJSONMessage(t *testing.T) {
+	for data, expected := range map[string]string{
+		`{"msg":""}`:                         `{}`,
+		`{"a":1,"msg":""}`:                   `{"a":1}`,
+		`{"msg":"","a":1}`:                   `{"a":1}`,
+		`{"a":1,"msg":"","b":2}`:             `{"a":1,"b":2}`,
+		"{\n  \"a\": 1,\n  \"msg\": \"\"\n}": "{\n  \"a\": 1\n}",
+		`{"n":{"msg":""},"msg":""}`:          `{"n":{"msg":""}}`,
+		`{"s":"\"msg\":\"\"","msg":""}`:      `{"s":"\"msg\":\"\""}`,
+		`{"list":["msg",""],"msg":""}`:       `{"list":["msg",""]}`,
+		`{"msg":"x"}`:                        `{"msg":"x"}`,
+		`{"a":"unterminated`:                 `{"a":"unterminated`,
+	} {
+		out := string(omitJSONMessage([]byte(data)))
+		if out != expected {
+			t.Fatalf("%s is %s, expected %s", data, out, expected)
+		}
+		if json.Valid([]byte(expected)) && !json.Valid([]byte(out)) {
+			t.Fatalf("%s is invalid json %s", data, out)
+		}
+	}
+}
+
+func TestEmptyMessageInvalid(t *testing.T) {
+	for _, conf := range []string{`empty-message = "drop"`, `empty-message { default = "" }`} {
+		err := Hijack(logrus.New(), ConfigString(conf))
+		if err == nil || !strings.Contains(err.Error(), "empty-message") {
+			t.Fatalf("%s: %v", conf, err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package logrus_mate
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDisabledLogger(t *testing.T) {
+	logger := logrus.New()
+	err := Hijack(logger, ConfigString(`
+enabled = false
+hooks.test-record { id = "disabled-logger" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := logger.Out.(*NullWriter); !ok {
+		t.Fatalf("out %T, expected the null writer", logger.Out)
+	}
+
+	logger.Error("silenced")
+
+	if len(logger.Hooks) != 0 {
+		t.Fatalf("the disabled logger has hooks: %v", logger.Hooks)
+	}
+	if _, exist := recordHooks.Load("disabled-logger"); exist {
+		t.Fatal("the hook of disabled logger is created")
+	}
+}
+
+func TestDisabledHook(t *testing.T) {
+	logger, buf := hijackString(t, `
+hooks {
+    test-record { id = "disabled-hook", enabled = false }
+    test-fail {}
+}`)
+
+	logger.Info("written")
+
+	if buf.Len() == 0 {
+		t.Fatal("the logger with a disabled hook writes nothing")
+	}
+	if _, exist := recordHooks.Load("disabled-hook"); exist {
+		t.Fatal("the disabled hook is created")
+	}
+}
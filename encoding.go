@@ -0,0 +1,86 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// encodingFormatter sanitizes the formatted output for the downstream which
+// chokes on raw bytes. With "utf8" the invalid sequences are replaced by U+FFFD,
+// with "ascii" the non-ASCII chars are escaped as \uXXXX too. The control bytes
+// except tab and newline are escaped as \u00XX by both.
+type encodingFormatter struct {
+	logrus.Formatter
+	ascii bool
+}
+
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case "", "utf8", "ascii":
+		return nil
+	}
+	return fmt.Errorf("logurs mate: encoding should be utf8 or ascii, but got %s", encoding)
+}
+
+func (p *encodingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := p.Formatter.Format(entry)
+	if err != nil || p.clean(data) {
+		return data, err
+	}
+
+	out := make([]byte, 0, len(data)+16)
+	var buf [utf8.UTFMax]byte
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+
+		switch {
+		case r == '\n' || r == '\t':
+			out = append(out, byte(r))
+		case r < 0x20 || r == 0x7f:
+			out = appendEscapedRune(out, r)
+		case r < utf8.RuneSelf:
+			out = append(out, byte(r))
+		case !p.ascii:
+			n := utf8.EncodeRune(buf[:], r)
+			out = append(out, buf[:n]...)
+		case r > 0xffff:
+			r -= 0x10000
+			out = appendEscapedRune(out, 0xd800+(r>>10))
+			out = appendEscapedRune(out, 0xdc00+(r&0x3ff))
+		default:
+			out = appendEscapedRune(out, r)
+		}
+	}
+
+	return out, nil
+}
+
+// clean reports whether data needs no change, the common case is not copied
+func (p *encodingFormatter) clean(data []byte) bool {
+	ascii := true
+	for _, b := range data {
+		if (b < 0x20 && b != '\n' && b != '\t') || b == 0x7f {
+			return false
+		}
+		if b >= utf8.RuneSelf {
+			ascii = false
+		}
+	}
+
+	if ascii {
+		return true
+	}
+
+	return !p.ascii && utf8.Valid(data)
+}
+
+func appendEscapedRune(out []byte, r rune) []byte {
+	return append(out, '\\', 'u',
+		hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+}
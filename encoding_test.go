@@ -0,0 +1,85 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rawFormatter writes the message as is, like a formatter not quoting the values
+type rawFormatter struct{}
+
+func (rawFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+func TestEncodingUTF8(t *testing.T) {
+	f := &encodingFormatter{Formatter: rawFormatter{}}
+
+	for message, expected := range map[string]string{
+		"plain ascii":            "plain ascii\n",
+		"héllo 世界":               "héllo 世界\n",
+		"bad \xff\xfe bytes":     "bad �� bytes\n",
+		"bell\a esc\x1b del\x7f": `bell\u0007 esc\u001b del\u007f` + "\n",
+		"tab\tkept":              "tab\tkept\n",
+	} {
+		data, err := f.Format(&logrus.Entry{Message: message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%q is encoded as %q, expected %q", message, data, expected)
+		}
+		if !utf8.Valid(data) {
+			t.Fatalf("%q is encoded as the invalid utf8 %q", message, data)
+		}
+	}
+}
+
+func TestEncodingASCII(t *testing.T) {
+	f := &encodingFormatter{Formatter: rawFormatter{}, ascii: true}
+
+	for message, expected := range map[string]string{
+		"plain ascii":        "plain ascii\n",
+		"héllo 世界":           "h\\u00e9llo \\u4e16\\u754c\n",
+		"emoji 😀":            "emoji \\ud83d\\ude00\n",
+		"bad \xff bytes\x00": "bad \\ufffd bytes\\u0000\n",
+	} {
+		data, err := f.Format(&logrus.Entry{Message: message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%q is encoded as %q, expected %q", message, data, expected)
+		}
+	}
+}
+
+func TestEncodingByConfig(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+encoding = "ascii"
+formatter.name = "csv"
+formatter.options.columns = ["msg", "payload", "name"]`)
+
+	logger.WithField("payload", "bin\x01\xffary").WithField("name", "José").Info("got")
+
+	s := buf.String()
+	for _, b := range []byte(s) {
+		if b >= utf8.RuneSelf || (b < 0x20 && b != '\n') {
+			t.Fatalf("output %q has the raw byte %#x", s, b)
+		}
+	}
+	if !strings.Contains(s, `Jos\u00e9`) {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestEncodingInvalid(t *testing.T) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(`encoding = "latin1"`)); err == nil {
+		t.Fatal("the invalid encoding is accepted")
+	}
+}
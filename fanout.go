@@ -0,0 +1,129 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// FanoutErrorHandler is called when an output of fanout failed to format or write
+// the entry, the other outputs are still written
+type FanoutErrorHandler func(output string, entry *logrus.Entry, err error)
+
+var fanoutErrorHandler atomic.Value
+
+// SetFanoutErrorHandler replaces the handler of the fanout errors, which prints
+// them to stderr by default
+func SetFanoutErrorHandler(handler FanoutErrorHandler) {
+	if handler == nil {
+		handler = printFanoutError
+	}
+	fanoutErrorHandler.Store(handler)
+}
+
+func printFanoutError(output string, entry *logrus.Entry, err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "logurs mate: fanout output %s: %s\n", output, err)
+}
+
+func handleFanoutError(output string, entry *logrus.Entry, err error) {
+	if handler, ok := fanoutErrorHandler.Load().(FanoutErrorHandler); ok {
+		handler(output, entry, err)
+		return
+	}
+	printFanoutError(output, entry, err)
+}
+
+type fanoutOutput struct {
+	name    string
+	primary bool
+	*routeTarget
+}
+
+// fanoutHook formats the entry once per output by its own formatter, e.g. json
+// to a file and text to stdout, and writes all of them before the logging returns.
+// It is the last hook, the out of logger writes nothing, and the formatter of logger
+// is the one of the first output, so entry.Bytes() in the hooks gets its bytes.
+type fanoutHook struct {
+	outputs []fanoutOutput
+}
+
+func newFanoutHook(conf, fanoutConf config.Configuration) (hook *fanoutHook, err error) {
+	hook = &fanoutHook{}
+
+	names := fanoutConf.Keys()
+	sort.Strings(names)
+
+	for _, name := range names {
+		var target *routeTarget
+		if target, err = newRouteTarget(fanoutConf.GetConfig(name)); err != nil {
+			err = fmt.Errorf("logurs mate: fanout output %s: %s", name, err)
+			return
+		}
+
+		// every output honors the drop and encoding of logger
+		target.formatter = wrapFormatter(conf, target.formatter)
+
+		primary := fanoutConf.GetConfig(name).GetBoolean("primary", false)
+
+		hook.outputs = append(hook.outputs, fanoutOutput{name: name, primary: primary, routeTarget: target})
+	}
+
+	// the primary outputs, e.g. console, are written first, so a hung or failing
+	// output never delays or hides them
+	sort.SliceStable(hook.outputs, func(i, j int) bool {
+		return hook.outputs[i].primary && !hook.outputs[j].primary
+	})
+
+	if len(hook.outputs) == 0 {
+		err = fmt.Errorf("logurs mate: fanout has no outputs")
+		return
+	}
+
+	return
+}
+
+// formatter returns the formatter of the first output, the primary one if any
+func (p *fanoutHook) formatter() logrus.Formatter {
+	return p.outputs[0].formatter
+}
+
+func (p *fanoutHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *fanoutHook) Fire(entry *logrus.Entry) error {
+	for i := range p.outputs {
+		p.outputs[i].write(entry)
+	}
+	return nil
+}
+
+func (p *fanoutOutput) write(entry *logrus.Entry) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	// the panic of an output is its error, the other outputs are still written
+	defer func() {
+		if r := recover(); r != nil {
+			handleFanoutError(p.name, entry, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	serialized, err := p.formatter.Format(entry)
+	if err != nil {
+		handleFanoutError(p.name, entry, err)
+		return
+	}
+
+	if len(serialized) == 0 {
+		return
+	}
+
+	if _, err = writeEntry(p.out, entry, serialized); err != nil {
+		handleFanoutError(p.name, entry, err)
+	}
+}
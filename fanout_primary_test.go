@@ -0,0 +1,86 @@
+package logrus_mate
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterWriter("test-panic-output", func(config.Configuration) (io.Writer, error) {
+		return panicWriter{}, nil
+	})
+}
+
+type panicWriter struct{}
+
+func (panicWriter) Write([]byte) (int, error) {
+	panic("test output panic")
+}
+
+func TestFanoutConsoleSurvivesFileError(t *testing.T) {
+	var locker sync.Mutex
+	failed := make(map[string]int)
+	SetFanoutErrorHandler(func(output string, entry *logrus.Entry, err error) {
+		locker.Lock()
+		defer locker.Unlock()
+		failed[output]++
+	})
+	defer SetFanoutErrorHandler(nil)
+
+	logger := logrus.New()
+	err := Hijack(logger, ConfigString(`
+level = "info"
+fanout {
+    a-file { out { name = "test-output", options { id = "primary-file", fail = true } }, formatter.name = "json" }
+    b-broken { out.name = "test-panic-output", formatter.name = "json" }
+    console {
+        primary = true
+        out { name = "test-output", options.id = "primary-console" }
+        formatter { name = "text", options.disable-colors = true }
+    }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("first")
+	logger.Error("second")
+
+	// the failing and panicking outputs never suppress the console line
+	lines := outputOf(t, "primary-console").Lines()
+	if len(lines) != 2 || !strings.Contains(lines[0], "msg=first") || !strings.Contains(lines[1], "msg=second") {
+		t.Fatalf("console %q", lines)
+	}
+
+	locker.Lock()
+	defer locker.Unlock()
+	if failed["a-file"] != 2 || failed["b-broken"] != 2 || len(failed) != 2 {
+		t.Fatalf("failed %v", failed)
+	}
+}
+
+func TestFanoutPrimaryFirst(t *testing.T) {
+	hook, err := newFanoutHook(configOf(``), configOf(`
+a { out.name = "nil" }
+b { primary = true, out.name = "nil" }
+c { out.name = "nil" }
+d { primary = true, out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, output := range hook.outputs {
+		names = append(names, output.name)
+	}
+
+	// the primary outputs first, each group by name
+	if s := strings.Join(names, ","); s != "b,d,a,c" {
+		t.Fatalf("outputs %s", s)
+	}
+}
@@ -0,0 +1,211 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterWriter("test-output", func(conf config.Configuration) (io.Writer, error) {
+		w := &outputWriter{}
+		if conf != nil {
+			w.fail = conf.GetBoolean("fail", false)
+			outputWriters.Store(conf.GetString("id"), w)
+		}
+		return w, nil
+	})
+}
+
+var outputWriters sync.Map
+
+var errTestOutput = errors.New("test output failed")
+
+// outputWriter records the writes, or fails every write with fail
+type outputWriter struct {
+	locker sync.Mutex
+	buf    bytes.Buffer
+	fail   bool
+}
+
+func (p *outputWriter) Write(b []byte) (int, error) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.fail {
+		return 0, errTestOutput
+	}
+	return p.buf.Write(b)
+}
+
+func (p *outputWriter) Lines() []string {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	s := strings.TrimSpace(p.buf.String())
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func outputOf(t *testing.T, id string) *outputWriter {
+	t.Helper()
+
+	w, exist := outputWriters.Load(id)
+	if !exist {
+		t.Fatalf("no test-output of id %q", id)
+	}
+	return w.(*outputWriter)
+}
+
+// entryBytesHook records entry.Bytes(), like the hooks shipping the formatted entry
+type entryBytesHook struct {
+	locker sync.Mutex
+	bytes  []string
+}
+
+func (p *entryBytesHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *entryBytesHook) Fire(entry *logrus.Entry) error {
+	b, err := entry.Bytes()
+	if err != nil {
+		return err
+	}
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	p.bytes = append(p.bytes, string(b))
+	return nil
+}
+
+func TestFanoutHeterogeneousFormatters(t *testing.T) {
+	logger := logrus.New()
+	err := Hijack(logger, ConfigString(`
+level = "info"
+fanout {
+    console {
+        primary = true
+        out { name = "test-output", options.id = "fanout-console" }
+        formatter { name = "text", options.disable-colors = true }
+    }
+    collector {
+        out { name = "test-output", options.id = "fanout-collector" }
+        formatter.name = "json"
+    }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.WithField("user", "bob").Info("login")
+	logger.Debug("below level")
+	logger.WithField("user", "alice").Warn("logout")
+
+	console, collector := outputOf(t, "fanout-console").Lines(), outputOf(t, "fanout-collector").Lines()
+	if len(console) != 2 || len(collector) != 2 {
+		t.Fatalf("console %q, collector %q", console, collector)
+	}
+
+	// the outputs get the corresponding entries, each by its own formatter
+	for i, expected := range []struct{ level, msg, user string }{{"info", "login", "bob"}, {"warning", "logout", "alice"}} {
+		if line := console[i]; !strings.Contains(line, "level="+expected.level) || !strings.Contains(line, "msg="+expected.msg) ||
+			!strings.Contains(line, "user="+expected.user) {
+			t.Fatalf("console line %d %q", i, line)
+		}
+
+		var e map[string]interface{}
+		if err = json.Unmarshal([]byte(collector[i]), &e); err != nil {
+			t.Fatalf("collector line %d %q: %s", i, collector[i], err)
+		}
+		if e["level"] != expected.level || e["msg"] != expected.msg || e["user"] != expected.user {
+			t.Fatalf("collector line %d %v", i, e)
+		}
+	}
+}
+
+func TestFanoutEntryBytesInHooks(t *testing.T) {
+	logger := logrus.New()
+	err := Hijack(logger, ConfigString(`
+level = "info"
+fanout {
+    a { out { name = "test-output", options.id = "fanout-bytes-a" }, formatter.name = "json" }
+    b { primary = true, out { name = "test-output", options.id = "fanout-bytes-b" }, formatter { name = "text", options.disable-colors = true } }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the hooks calling entry.Bytes() get the bytes of the primary output and write nothing
+	hook := &entryBytesHook{}
+	logger.AddHook(hook)
+	logger.AddHook(hook)
+
+	logger.Info("once")
+
+	a, b := outputOf(t, "fanout-bytes-a").Lines(), outputOf(t, "fanout-bytes-b").Lines()
+	if len(a) != 1 || len(b) != 1 {
+		t.Fatalf("the outputs are written %d and %d times", len(a), len(b))
+	}
+
+	if len(hook.bytes) != 2 {
+		t.Fatalf("bytes %q", hook.bytes)
+	}
+	for _, s := range hook.bytes {
+		if s != b[0]+"\n" {
+			t.Fatalf("entry.Bytes() is %q, expected the primary output %q", s, b[0])
+		}
+	}
+}
+
+func TestFanoutFailingOutput(t *testing.T) {
+	var locker sync.Mutex
+	var failed []string
+	SetFanoutErrorHandler(func(output string, entry *logrus.Entry, err error) {
+		locker.Lock()
+		defer locker.Unlock()
+		failed = append(failed, output+": "+entry.Message+": "+err.Error())
+	})
+	defer SetFanoutErrorHandler(nil)
+
+	logger := logrus.New()
+	err := Hijack(logger, ConfigString(`
+level = "info"
+fanout {
+    disk { out { name = "test-output", options { id = "fanout-disk", fail = true } }, formatter.name = "json" }
+    console { out { name = "test-output", options.id = "fanout-ok" }, formatter.name = "json" }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("still written")
+
+	if lines := outputOf(t, "fanout-ok").Lines(); len(lines) != 1 || !strings.Contains(lines[0], "still written") {
+		t.Fatalf("the other output %q", lines)
+	}
+	if len(failed) != 1 || failed[0] != "disk: still written: "+errTestOutput.Error() {
+		t.Fatalf("failed %q", failed)
+	}
+}
+
+func TestFanoutInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`fanout {}`,
+		`fanout { a.out.name = "missing-writer" }`,
+		`fanout { a.formatter.name = "missing-formatter" }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
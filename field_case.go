@@ -0,0 +1,169 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldCaseHook renames the field keys to one convention, so "UserID", "userId"
+// and "user_id" are searched as one key. The keys normalized to the same are
+// merged into a list of their values, or suffixed by the number like "user_id_2".
+type fieldCaseHook struct {
+	normalize func(string) string
+	suffix    bool
+	separator string
+}
+
+// newFieldCaseHook parses field-case = "snake" or
+// field-case { convention = "snake", collision = "suffix" }
+func newFieldCaseHook(conf config.Configuration) (hook *fieldCaseHook, err error) {
+	convention, collision := "", "merge"
+
+	if !conf.IsObject("field-case") {
+		convention = conf.GetString("field-case")
+	} else {
+		caseConf := conf.GetConfig("field-case")
+		convention = caseConf.GetString("convention")
+		collision = caseConf.GetString("collision", "merge")
+	}
+
+	hook = &fieldCaseHook{separator: "_"}
+
+	switch convention {
+	case "snake":
+		hook.normalize = snakeCase
+	case "camel":
+		hook.normalize = camelCase
+		hook.separator = ""
+	case "lower":
+		hook.normalize = strings.ToLower
+	default:
+		err = fmt.Errorf("logurs mate: field-case convention should be snake, camel or lower, but got %q", convention)
+		return
+	}
+
+	switch collision {
+	case "merge":
+	case "suffix":
+		hook.suffix = true
+	default:
+		err = fmt.Errorf("logurs mate: field-case collision should be merge or suffix, but got %q", collision)
+		return
+	}
+
+	return
+}
+
+func (p *fieldCaseHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *fieldCaseHook) Fire(entry *logrus.Entry) error {
+	if len(entry.Data) == 0 {
+		return nil
+	}
+
+	// the keys already normalized keep their names, then the others by order
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := p.normalize(keys[i]) == keys[i], p.normalize(keys[j]) == keys[j]
+		if ni != nj {
+			return ni
+		}
+		return keys[i] < keys[j]
+	})
+
+	data := make(logrus.Fields, len(entry.Data))
+	merged := make(map[string]bool)
+
+	for _, key := range keys {
+		v := entry.Data[key]
+		name := p.normalize(key)
+
+		prev, exist := data[name]
+		switch {
+		case !exist:
+			data[name] = v
+		case p.suffix:
+			for n := 2; ; n++ {
+				suffixed := name + p.separator + strconv.Itoa(n)
+				if _, exist = data[suffixed]; !exist {
+					data[suffixed] = v
+					break
+				}
+			}
+		case merged[name]:
+			data[name] = append(prev.([]interface{}), v)
+		default:
+			data[name] = []interface{}{prev, v}
+			merged[name] = true
+		}
+	}
+
+	entry.Data = data
+
+	return nil
+}
+
+// fieldWords splits the key at '_', '-', '.', spaces and the case changes,
+// e.g. "HTTPServerID" is "http", "server", "id"
+func fieldWords(key string) (words []string) {
+	runes := []rune(key)
+	start := 0
+
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(runes))
+
+	return
+}
+
+func snakeCase(key string) string {
+	words := fieldWords(key)
+	if len(words) == 0 {
+		return key
+	}
+	return strings.Join(words, "_")
+}
+
+func camelCase(key string) string {
+	words := fieldWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	for i := 1; i < len(words); i++ {
+		runes := []rune(words[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, "")
+}
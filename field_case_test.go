@@ -0,0 +1,110 @@
+package logrus_mate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFieldCaseConventions(t *testing.T) {
+	for _, c := range []struct {
+		convention string
+		key        string
+		expected   string
+	}{
+		{"snake", "UserID", "user_id"},
+		{"snake", "userId", "user_id"},
+		{"snake", "HTTPServerID", "http_server_id"},
+		{"snake", "request-path.full", "request_path_full"},
+		{"camel", "user_id", "userId"},
+		{"camel", "HTTPServerID", "httpServerId"},
+		{"camel", "Request Path", "requestPath"},
+		{"lower", "UserID", "userid"},
+		{"lower", "user_id", "user_id"},
+	} {
+		hook, err := newFieldCaseHook(configOf(`field-case = "` + c.convention + `"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := hook.normalize(c.key); name != c.expected {
+			t.Fatalf("%s of %q is %q, expected %q", c.convention, c.key, name, c.expected)
+		}
+	}
+}
+
+func TestFieldCaseByConfig(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+field-case = "snake"
+formatter.name = "json"
+hooks.test-record.id = "field-case"`)
+
+	logger.WithFields(logrus.Fields{"UserID": 1, "requestPath": "/"}).Info("request")
+
+	data := recordedBy(t, "field-case").Entries()[0].Data
+	if expected := (logrus.Fields{"user_id": 1, "request_path": "/"}); !reflect.DeepEqual(data, expected) {
+		t.Fatalf("data %v, expected %v", data, expected)
+	}
+	if s := buf.String(); !strings.Contains(s, `"user_id":1`) || !strings.Contains(s, `"request_path":"/"`) {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestFieldCaseCollisionMerge(t *testing.T) {
+	hook, err := newFieldCaseHook(configOf(`field-case = "snake"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := &logrus.Entry{Data: logrus.Fields{"user_id": 1, "UserID": 2, "userId": 3}}
+	if err = hook.Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// the key already normalized first, then the others by name
+	if expected := (logrus.Fields{"user_id": []interface{}{1, 2, 3}}); !reflect.DeepEqual(entry.Data, expected) {
+		t.Fatalf("data %v, expected %v", entry.Data, expected)
+	}
+}
+
+func TestFieldCaseCollisionSuffix(t *testing.T) {
+	for _, c := range []struct {
+		conf     string
+		expected logrus.Fields
+	}{
+		{
+			`field-case { convention = "snake", collision = "suffix" }`,
+			logrus.Fields{"user_id": 1, "user_id_2": 2, "user_id_3": 3},
+		},
+		{
+			`field-case { convention = "camel", collision = "suffix" }`,
+			logrus.Fields{"userId": 3, "userId2": 2, "userId3": 1},
+		},
+	} {
+		hook, err := newFieldCaseHook(configOf(c.conf))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry := &logrus.Entry{Data: logrus.Fields{"user_id": 1, "UserID": 2, "userId": 3}}
+		if err = hook.Fire(entry); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(entry.Data, c.expected) {
+			t.Fatalf("%s: data %v, expected %v", c.conf, entry.Data, c.expected)
+		}
+	}
+}
+
+func TestFieldCaseInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`field-case = "kebab"`,
+		`field-case { convention = "snake", collision = "drop" }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldLimitHook truncates the string field values longer than the limit, e.g. a
+// full http body, with the suffix "...(truncated N bytes)", N is the bytes cut.
+// The limit of a field in fields overrides the default, 0 is unlimited.
+type fieldLimitHook struct {
+	limit  int
+	fields map[string]int
+}
+
+// newFieldLimitHook parses max-field-len = 1024 or
+// max-field-len { default = 1024, fields { body = 4096 } }
+func newFieldLimitHook(conf config.Configuration) (hook *fieldLimitHook, err error) {
+	hook = &fieldLimitHook{fields: make(map[string]int)}
+
+	if !conf.IsObject("max-field-len") {
+		hook.limit = int(conf.GetInt64("max-field-len"))
+	} else {
+		limitConf := conf.GetConfig("max-field-len")
+		hook.limit = int(limitConf.GetInt64("default"))
+
+		if fieldsConf := limitConf.GetConfig("fields"); fieldsConf != nil {
+			for _, field := range fieldsConf.Keys() {
+				hook.fields[field] = int(fieldsConf.GetInt64(field))
+			}
+		}
+	}
+
+	if hook.limit < 0 {
+		err = fmt.Errorf("logurs mate: max-field-len should not be negative, but got %d", hook.limit)
+		return
+	}
+
+	for field, limit := range hook.fields {
+		if limit < 0 {
+			err = fmt.Errorf("logurs mate: max-field-len of %s should not be negative, but got %d", field, limit)
+			return
+		}
+	}
+
+	return
+}
+
+func (p *fieldLimitHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *fieldLimitHook) Fire(entry *logrus.Entry) error {
+	for field, v := range entry.Data {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		limit, exist := p.fields[field]
+		if !exist {
+			limit = p.limit
+		}
+
+		if limit > 0 && len(s) > limit {
+			entry.Data[field] = truncateValue(s, limit)
+		}
+	}
+
+	return nil
+}
+
+// truncateValue cuts s at limit bytes, backing to the start of a rune so the
+// kept part is still valid UTF-8
+func truncateValue(s string, limit int) string {
+	n := limit
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + "...(truncated " + strconv.Itoa(len(s)-n) + " bytes)"
+}
@@ -0,0 +1,74 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMaxFieldLenGlobal(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+max-field-len = 16
+formatter.name = "json"
+hooks.test-record.id = "field-limit"`)
+
+	body := strings.Repeat("x", 100)
+	logger.WithField("body", body).WithField("short", "ok").WithField("count", 123456789012345678).Info("request")
+
+	entries := recordedBy(t, "field-limit").Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries %v", entries)
+	}
+
+	data := entries[0].Data
+	if expected := strings.Repeat("x", 16) + "...(truncated 84 bytes)"; data["body"] != expected {
+		t.Fatalf("body %q, expected %q", data["body"], expected)
+	}
+	// the short and non-string values are kept
+	if data["short"] != "ok" || data["count"] != 123456789012345678 {
+		t.Fatalf("data %v", data)
+	}
+	if s := buf.String(); strings.Contains(s, body) || !strings.Contains(s, `truncated 84 bytes`) {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestMaxFieldLenPerField(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+max-field-len { default = 8, fields { body = 20, trace = 0 } }
+hooks.test-record.id = "field-limit-per"`)
+
+	long := strings.Repeat("y", 40)
+	logger.WithFields(logrus.Fields{"body": long, "trace": long, "other": long}).Info("request")
+
+	data := recordedBy(t, "field-limit-per").Entries()[0].Data
+	for field, expected := range map[string]string{
+		"body":  strings.Repeat("y", 20) + "...(truncated 20 bytes)",
+		"trace": long,
+		"other": strings.Repeat("y", 8) + "...(truncated 32 bytes)",
+	} {
+		if data[field] != expected {
+			t.Fatalf("%s is %q, expected %q", field, data[field], expected)
+		}
+	}
+}
+
+func TestMaxFieldLenMultibyte(t *testing.T) {
+	// the limit in the middle of a rune backs to its start
+	s := truncateValue("ab世界", 4)
+	if s != "ab...(truncated 6 bytes)" || !utf8.ValidString(s) {
+		t.Fatalf("truncated %q", s)
+	}
+}
+
+func TestMaxFieldLenInvalid(t *testing.T) {
+	for _, conf := range []string{`max-field-len = -1`, `max-field-len { fields { body = -5 } }`} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package logrus_mate_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+	_ "github.com/gogap/logrus_mate/hooks/file"
+)
+
+func TestConfigDSNFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dsn.log")
+
+	logger := logrus.New()
+	if err := logrus_mate.Hijack(logger, logrus_mate.ConfigDSN("file://"+filename+"?formatter=json&level=info")); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("below level")
+	logger.Info("written by dsn")
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := string(data); !strings.Contains(s, `"msg":"written by dsn"`) || strings.Contains(s, "below level") {
+		t.Fatalf("file %q", s)
+	}
+}
+
+func TestStartupBannerFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "banner.log")
+
+	logger := logrus.New()
+	err := logrus_mate.Hijack(logger, logrus_mate.ConfigString(`
+startup-banner = true
+formatter.name = "json"
+out.name = "nil"
+hooks.file { filename = "`+filename+`", level = 4 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := string(data); !strings.Contains(s, `"msg":"logger started"`) || !strings.Contains(s, `"file":"`+filename+`"`) {
+		t.Fatalf("banner %q", s)
+	}
+}
+
+func TestFlushAndRotateFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	mate, err := logrus_mate.NewLogrusMate(logrus_mate.ConfigString(`
+mike {
+    level = "info"
+    out.name = "nil"
+    hooks.file { filename = "` + filename + `", level = 6, daily = true, rotate = true }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := mate.Logger("mike")
+
+	for i := 0; i < 10; i++ {
+		logger.Infof("before %d", i)
+	}
+	if err = mate.FlushAndRotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after")
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "app.*.log"))
+	if len(rotated) != 1 {
+		t.Fatalf("rotated files %v", rotated)
+	}
+
+	data, err := ioutil.ReadFile(rotated[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Count(s, "before") != 10 || strings.Contains(s, "after") {
+		t.Fatalf("rotated %q", s)
+	}
+
+	if data, err = ioutil.ReadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Contains(s, "before") || !strings.Contains(s, "after") {
+		t.Fatalf("active %q", s)
+	}
+}
+
+func TestConfigBeegoFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "beego.log")
+
+	logger := logrus.New()
+	err := logrus_mate.Hijack(logger, logrus_mate.ConfigBeego(`{"filename":"`+filename+`","maxlines":10000,"maxsize":1048576,"daily":true,"maxdays":7,"level":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// beego level 4 is warning
+	logger.Info("below level")
+	logger.Warn("written by beego config")
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); !strings.Contains(s, `msg="written by beego config"`) || strings.Contains(s, "below level") {
+		t.Fatalf("file %q", s)
+	}
+}
@@ -0,0 +1,150 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// the deeper values are kept as they are, e.g. a map containing itself
+const flattenMaxDepth = 32
+
+// flattenHook flattens the map values into the top level keys joined by the
+// separator, e.g. http { method, status } is http.method and http.status. The
+// arrays are flattened by index like tags.0, encoded as json, or kept.
+type flattenHook struct {
+	separator string
+	arrays    string
+}
+
+// newFlattenHook parses flatten = true or
+// flatten { separator = ".", arrays = "index" }
+func newFlattenHook(conf config.Configuration) (hook *flattenHook, err error) {
+	hook = &flattenHook{separator: ".", arrays: "index"}
+
+	if !conf.IsObject("flatten") {
+		if !conf.GetBoolean("flatten", false) {
+			hook = nil
+		}
+		return
+	}
+
+	flattenConf := conf.GetConfig("flatten")
+	hook.separator = flattenConf.GetString("separator", hook.separator)
+	hook.arrays = flattenConf.GetString("arrays", hook.arrays)
+
+	switch hook.arrays {
+	case "index", "json", "keep":
+	default:
+		err = fmt.Errorf("logurs mate: flatten arrays should be index, json or keep, but got %q", hook.arrays)
+		return
+	}
+
+	return
+}
+
+func (p *flattenHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *flattenHook) Fire(entry *logrus.Entry) error {
+	nested := false
+	for _, v := range entry.Data {
+		if p.flattenable(v) {
+			nested = true
+			break
+		}
+	}
+
+	if !nested {
+		return nil
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if !p.flattenable(v) {
+			data[k] = v
+		}
+	}
+
+	// the top level fields win the flattened keys clashing with them
+	for k, v := range entry.Data {
+		if p.flattenable(v) {
+			p.flatten(data, k, reflect.ValueOf(v), 0)
+		}
+	}
+
+	entry.Data = data
+
+	return nil
+}
+
+// flattenable reports whether v is a map of string keys, or an array flattened by index
+func (p *flattenHook) flattenable(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		return rv.Type().Key().Kind() == reflect.String && rv.Len() > 0
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are a value, not an array
+			return false
+		}
+		return p.arrays != "keep" && rv.Len() > 0
+	}
+
+	return false
+}
+
+func (p *flattenHook) flatten(data logrus.Fields, key string, rv reflect.Value, depth int) {
+	for rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if depth >= flattenMaxDepth || !rv.IsValid() || !p.flattenable(rv.Interface()) {
+		p.set(data, key, rv)
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			p.flatten(data, key+p.separator+iter.Key().String(), iter.Value(), depth+1)
+		}
+	default:
+		if p.arrays == "json" {
+			if b, err := json.Marshal(rv.Interface()); err == nil {
+				p.set(data, key, reflect.ValueOf(string(b)))
+				return
+			}
+			p.set(data, key, rv)
+			return
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			p.flatten(data, key+p.separator+strconv.Itoa(i), rv.Index(i), depth+1)
+		}
+	}
+}
+
+func (p *flattenHook) set(data logrus.Fields, key string, rv reflect.Value) {
+	if _, exist := data[key]; exist {
+		return
+	}
+
+	if !rv.IsValid() {
+		data[key] = nil
+		return
+	}
+
+	data[key] = rv.Interface()
+}
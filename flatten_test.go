@@ -0,0 +1,123 @@
+package logrus_mate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func flattenOf(t *testing.T, conf string) *flattenHook {
+	t.Helper()
+
+	hook, err := newFlattenHook(configOf(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook
+}
+
+func TestFlattenTwoLevels(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+flatten = true
+formatter.name = "json"
+hooks.test-record.id = "flatten"`)
+
+	logger.WithField("http", map[string]interface{}{
+		"method": "GET",
+		"status": 200,
+		"client": map[string]interface{}{"ip": "10.0.0.1", "port": 8080},
+	}).Info("request")
+
+	data := recordedBy(t, "flatten").Entries()[0].Data
+	expected := logrus.Fields{
+		"http.method":      "GET",
+		"http.status":      200,
+		"http.client.ip":   "10.0.0.1",
+		"http.client.port": 8080,
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("data %v, expected %v", data, expected)
+	}
+	if s := buf.String(); !strings.Contains(s, `"http.client.ip":"10.0.0.1"`) || strings.Contains(s, `"http":`) {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestFlattenArrays(t *testing.T) {
+	tags := []string{"a", "b"}
+
+	for conf, expected := range map[string]logrus.Fields{
+		`flatten = true`:                                {"tags.0": "a", "tags.1": "b"},
+		`flatten { arrays = "json" }`:                   {"tags": `["a","b"]`},
+		`flatten { arrays = "keep" }`:                   {"tags": tags},
+		`flatten { separator = "_" }`:                   {"tags_0": "a", "tags_1": "b"},
+		`flatten { arrays = "index", separator = "/" }`: {"tags/0": "a", "tags/1": "b"},
+	} {
+		entry := &logrus.Entry{Data: logrus.Fields{"tags": tags}}
+		if err := flattenOf(t, conf).Fire(entry); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(entry.Data, expected) {
+			t.Fatalf("%s: data %v, expected %v", conf, entry.Data, expected)
+		}
+	}
+}
+
+func TestFlattenKeepsValues(t *testing.T) {
+	entry := &logrus.Entry{Data: logrus.Fields{
+		"http.method": "top",
+		"http":        map[string]interface{}{"method": "nested", "path": "/"},
+		"body":        []byte("raw"),
+		"empty":       map[string]interface{}{},
+		"ints":        map[int]string{1: "x"},
+	}}
+	if err := flattenOf(t, `flatten = true`).Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// the top level field wins the clash, bytes and the maps not flattenable are kept
+	expected := logrus.Fields{
+		"http.method": "top",
+		"http.path":   "/",
+		"body":        []byte("raw"),
+		"empty":       map[string]interface{}{},
+		"ints":        map[int]string{1: "x"},
+	}
+	if !reflect.DeepEqual(entry.Data, expected) {
+		t.Fatalf("data %v, expected %v", entry.Data, expected)
+	}
+}
+
+func TestFlattenSelfContaining(t *testing.T) {
+	loop := map[string]interface{}{}
+	loop["self"] = loop
+
+	entry := &logrus.Entry{Data: logrus.Fields{"loop": loop}}
+	if err := flattenOf(t, `flatten = true`).Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// stopped at the max depth
+	if len(entry.Data) != 1 {
+		t.Fatalf("data %v", entry.Data)
+	}
+	for key := range entry.Data {
+		if strings.Count(key, ".self") != flattenMaxDepth {
+			t.Fatalf("key %q", key)
+		}
+	}
+}
+
+func TestFlattenConfig(t *testing.T) {
+	if flattenOf(t, `flatten = false`) != nil {
+		t.Fatal("flatten = false creates the hook")
+	}
+
+	conf := `flatten { arrays = "drop" }`
+	if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+		t.Fatalf("%s is accepted", conf)
+	}
+}
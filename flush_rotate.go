@@ -0,0 +1,89 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FlushAndRotate flushes the hooks having Flush, e.g. the async hooks, then syncs
+// and rotates the files of the hooks having FlushAndRotate like file, of the loggers
+// created by Logger(name). Each file is rotated under its lock, so the entries logged
+// before the call are all in the rotated file.
+func (p *LogrusMate) FlushAndRotate() (err error) {
+	failed := make(map[string]error)
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		for name, hook := range distinctHooks(v.(*logrus.Logger)) {
+			if flusher, ok := hook.(interface{ Flush() error }); ok {
+				if _, rotatable := hook.(interface{ FlushAndRotate() error }); !rotatable {
+					if e := flusher.Flush(); e != nil {
+						failed[k.(string)+"."+name] = e
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		for name, hook := range distinctHooks(v.(*logrus.Logger)) {
+			if rotator, ok := hook.(interface{ FlushAndRotate() error }); ok {
+				if e := rotator.FlushAndRotate(); e != nil {
+					failed[k.(string)+"."+name] = e
+				}
+			}
+		}
+		return true
+	})
+
+	if len(failed) > 0 {
+		var names []string
+		for name, e := range failed {
+			names = append(names, fmt.Sprintf("%s: %s", name, e))
+		}
+		sort.Strings(names)
+		err = fmt.Errorf("logurs mate: flush and rotate failed, %s", strings.Join(names, ", "))
+	}
+
+	return
+}
+
+// distinctHooks returns the hooks of logger by name, unwrapped from the guard,
+// the predicate and the breaker, the hook added for several levels is returned once
+func distinctHooks(logger *logrus.Logger) map[string]logrus.Hook {
+	hooks := make(map[string]logrus.Hook)
+	seen := make(map[logrus.Hook]bool)
+
+	for _, levelHooks := range logger.Hooks {
+		for _, hook := range levelHooks {
+			if reflect.TypeOf(hook).Comparable() {
+				if seen[hook] {
+					continue
+				}
+				seen[hook] = true
+			}
+
+			name := fmt.Sprintf("%T", hook)
+			if safe, ok := hook.(*safeHook); ok {
+				name, hook = safe.name, safe.hook
+			}
+			if predicate, ok := hook.(*predicateHook); ok {
+				hook = predicate.hook
+			}
+			if b, ok := hook.(*breakerHook); ok {
+				hook = b.hook
+			}
+
+			if _, exist := hooks[name]; exist {
+				name = fmt.Sprintf("%s#%d", name, len(hooks))
+			}
+			hooks[name] = hook
+		}
+	}
+
+	return hooks
+}
@@ -0,0 +1,55 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+type CallerPrettyfier func(*runtime.Frame) (function string, file string)
+
+var callerPrettyfiers = map[string]CallerPrettyfier{
+	"full":    fullCallerPrettyfier,
+	"short":   shortCallerPrettyfier,
+	"package": packageCallerPrettyfier,
+}
+
+// NewCallerPrettyfier returns the caller prettyfier of strategy name,
+// empty name returns nil, which means the logrus default output
+func NewCallerPrettyfier(name string) (prettyfier CallerPrettyfier, err error) {
+	if len(name) == 0 {
+		return
+	}
+
+	prettyfier, exist := callerPrettyfiers[name]
+	if !exist {
+		err = fmt.Errorf("logurs mate: unknown caller prettyfier: %s", name)
+		return
+	}
+
+	return
+}
+
+// fullCallerPrettyfier: github.com/gogap/logrus_mate.Func, /path/to/logrus_mate/file.go:12
+func fullCallerPrettyfier(f *runtime.Frame) (string, string) {
+	return f.Function, fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// shortCallerPrettyfier: logrus_mate.Func, file.go:12
+func shortCallerPrettyfier(f *runtime.Frame) (string, string) {
+	return shortFunction(f.Function), fmt.Sprintf("%s:%d", path.Base(f.File), f.Line)
+}
+
+// packageCallerPrettyfier: logrus_mate.Func, logrus_mate/file.go:12
+func packageCallerPrettyfier(f *runtime.Frame) (string, string) {
+	dir, file := path.Split(f.File)
+	return shortFunction(f.Function), fmt.Sprintf("%s/%s:%d", path.Base(dir), file, f.Line)
+}
+
+func shortFunction(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		return function[i+1:]
+	}
+	return function
+}
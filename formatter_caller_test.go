@@ -0,0 +1,46 @@
+package logrus_mate
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestCallerPrettyfiers(t *testing.T) {
+	frame := &runtime.Frame{
+		Function: "github.com/gogap/logrus_mate/hooks/file.(*FileHook).Fire",
+		File:     "/home/go/src/github.com/gogap/logrus_mate/hooks/file/hook.go",
+		Line:     12,
+	}
+
+	cases := []struct {
+		name     string
+		function string
+		file     string
+	}{
+		{"full", "github.com/gogap/logrus_mate/hooks/file.(*FileHook).Fire", "/home/go/src/github.com/gogap/logrus_mate/hooks/file/hook.go:12"},
+		{"short", "file.(*FileHook).Fire", "hook.go:12"},
+		{"package", "file.(*FileHook).Fire", "file/hook.go:12"},
+	}
+
+	for _, c := range cases {
+		prettyfier, err := NewCallerPrettyfier(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		function, file := prettyfier(frame)
+		if function != c.function || file != c.file {
+			t.Errorf("%s: got %q %q, expected %q %q", c.name, function, file, c.function, c.file)
+		}
+	}
+}
+
+func TestCallerPrettyfierUnknown(t *testing.T) {
+	if _, err := NewCallerPrettyfier("long"); err == nil {
+		t.Fatal("expected the error of unknown strategy")
+	}
+
+	if prettyfier, err := NewCallerPrettyfier(""); err != nil || prettyfier != nil {
+		t.Fatal("the empty strategy is the logrus default")
+	}
+}
@@ -0,0 +1,129 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+type CEFFormatterConfig struct {
+	Vendor           string
+	Product          string
+	Version          string
+	SignatureIDField string
+	Extensions       map[string]string
+	IncludeUnmapped  bool
+}
+
+// CEFFormatter formats entry in Common Event Format:
+// CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+type CEFFormatter struct {
+	Config CEFFormatterConfig
+}
+
+var cefSeverities = map[logrus.Level]int{
+	logrus.TraceLevel: 0,
+	logrus.DebugLevel: 1,
+	logrus.InfoLevel:  3,
+	logrus.WarnLevel:  6,
+	logrus.ErrorLevel: 8,
+	logrus.FatalLevel: 10,
+	logrus.PanicLevel: 10,
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func init() {
+	RegisterFormatter("cef", NewCEFFormatter)
+}
+
+func NewCEFFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
+	conf := CEFFormatterConfig{
+		Vendor:           "gogap",
+		Product:          "logrus_mate",
+		Version:          "1.0",
+		SignatureIDField: "signature_id",
+		Extensions:       map[string]string{},
+		IncludeUnmapped:  true,
+	}
+
+	if config != nil {
+		conf.Vendor = config.GetString("vendor", conf.Vendor)
+		conf.Product = config.GetString("product", conf.Product)
+		conf.Version = config.GetString("version", conf.Version)
+		conf.SignatureIDField = config.GetString("signature-id-field", conf.SignatureIDField)
+		conf.IncludeUnmapped = config.GetBoolean("include-unmapped", true)
+
+		if extConf := config.GetConfig("extensions"); extConf != nil {
+			for _, field := range extConf.Keys() {
+				conf.Extensions[field] = extConf.GetString(field)
+			}
+		}
+	}
+
+	formatter = &CEFFormatter{Config: conf}
+
+	return
+}
+
+func (p *CEFFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	signatureID := entry.Level.String()
+	if v, exist := entry.Data[p.Config.SignatureIDField]; exist {
+		signatureID = fmt.Sprint(v)
+	}
+
+	fmt.Fprintf(b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(p.Config.Vendor),
+		cefHeaderEscaper.Replace(p.Config.Product),
+		cefHeaderEscaper.Replace(p.Config.Version),
+		cefHeaderEscaper.Replace(signatureID),
+		cefHeaderEscaper.Replace(entry.Message),
+		cefSeverities[entry.Level],
+	)
+
+	b.WriteString("rt=")
+	b.WriteString(strconv.FormatInt(entry.Time.UnixNano()/1e6, 10))
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		if k == p.Config.SignatureIDField {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		extKey, mapped := p.Config.Extensions[k]
+		if !mapped {
+			if !p.Config.IncludeUnmapped {
+				continue
+			}
+			extKey = k
+		}
+
+		v := entry.Data[k]
+		if e, ok := v.(error); ok {
+			v = e.Error()
+		}
+
+		b.WriteByte(' ')
+		b.WriteString(extKey)
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscaper.Replace(fmt.Sprint(v)))
+	}
+
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
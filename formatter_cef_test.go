@@ -0,0 +1,82 @@
+package logrus_mate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func cefFormat(t *testing.T, conf string, entry *logrus.Entry) string {
+	t.Helper()
+
+	formatter, err := NewFormatter("cef", configOf(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func cefEntry(level logrus.Level, message string, fields logrus.Fields) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	entry.Time = time.Unix(1700000000, 0)
+	entry.Level = level
+	entry.Message = message
+	return entry
+}
+
+func TestCEFFormatter(t *testing.T) {
+	line := cefFormat(t, `
+vendor  = "Acme"
+product = "Gate"
+version = "2.1"
+extensions { user = "suser", ip = "src" }`,
+		cefEntry(logrus.WarnLevel, "login failed", logrus.Fields{
+			"signature_id": "auth-01",
+			"user":         "bob",
+			"ip":           "10.0.0.1",
+			"error":        errors.New("bad password"),
+		}))
+
+	expected := "CEF:0|Acme|Gate|2.1|auth-01|login failed|6|rt=1700000000000 error=bad password src=10.0.0.1 suser=bob\n"
+	if line != expected {
+		t.Fatalf("\n%q\nexpected\n%q", line, expected)
+	}
+}
+
+func TestCEFFormatterEscape(t *testing.T) {
+	line := cefFormat(t, `vendor = "a|b\\c"`,
+		cefEntry(logrus.ErrorLevel, "x|y\nz", logrus.Fields{"q": "k=v\\w\r\nend"}))
+
+	expected := `CEF:0|a\|b\\c|logrus_mate|1.0|error|x\|y z|8|rt=1700000000000 q=k\=v\\w\r\nend` + "\n"
+	if line != expected {
+		t.Fatalf("\n%q\nexpected\n%q", line, expected)
+	}
+}
+
+func TestCEFFormatterUnmapped(t *testing.T) {
+	line := cefFormat(t, `
+include-unmapped = false
+extensions { user = "suser" }`,
+		cefEntry(logrus.InfoLevel, "hi", logrus.Fields{"user": "bob", "other": 1}))
+
+	expected := "CEF:0|gogap|logrus_mate|1.0|info|hi|3|rt=1700000000000 suser=bob\n"
+	if line != expected {
+		t.Fatalf("\n%q\nexpected\n%q", line, expected)
+	}
+}
+
+func TestCEFSeverities(t *testing.T) {
+	for _, level := range logrus.AllLevels {
+		severity, exist := cefSeverities[level]
+		if !exist || severity < 0 || severity > 10 {
+			t.Fatalf("the severity of %s is %d", level, severity)
+		}
+	}
+}
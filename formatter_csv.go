@@ -0,0 +1,111 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+type CSVFormatterConfig struct {
+	Columns         []string
+	Header          bool
+	TimestampFormat string
+	Delimiter       rune
+}
+
+// CSVFormatter formats entry as one CSV row of the columns, `time` `level` and
+// `msg` are the entry time, level and message, the others are the fields,
+// the absent fields are empty columns
+type CSVFormatter struct {
+	Config CSVFormatterConfig
+
+	headerOnce sync.Once
+}
+
+func init() {
+	RegisterFormatter("csv", NewCSVFormatter)
+}
+
+func NewCSVFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
+	conf := CSVFormatterConfig{
+		Columns:         []string{"time", "level", "msg"},
+		TimestampFormat: time.RFC3339,
+		Delimiter:       ',',
+	}
+
+	if config != nil {
+		if columns := config.GetStringList("columns"); len(columns) > 0 {
+			conf.Columns = columns
+		}
+		conf.Header = config.GetBoolean("header", false)
+		conf.TimestampFormat = config.GetString("timestamp-format", conf.TimestampFormat)
+
+		delimiter := []rune(config.GetString("delimiter", ","))
+		if len(delimiter) != 1 {
+			err = fmt.Errorf("logurs mate: csv delimiter should be one char: %q", string(delimiter))
+			return
+		}
+		conf.Delimiter = delimiter[0]
+	}
+
+	formatter = &CSVFormatter{Config: conf}
+
+	return
+}
+
+func (p *CSVFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	w := csv.NewWriter(b)
+	w.Comma = p.Config.Delimiter
+
+	var err error
+
+	// the header is written before the first row only, so it is the first line of
+	// the output which is not rotated, the rotated files have no header
+	p.headerOnce.Do(func() {
+		if p.Config.Header {
+			err = w.Write(p.Config.Columns)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(p.Config.Columns))
+	for i, column := range p.Config.Columns {
+		switch column {
+		case "time":
+			row[i] = entry.Time.Format(p.Config.TimestampFormat)
+		case "level":
+			row[i] = entry.Level.String()
+		case "msg":
+			row[i] = entry.Message
+		default:
+			v, exist := entry.Data[column]
+			if !exist {
+				continue
+			}
+			if e, ok := v.(error); ok {
+				v = e.Error()
+			}
+			row[i] = fmt.Sprint(v)
+		}
+	}
+
+	if err = w.Write(row); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package logrus_mate
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readCSV(t *testing.T, s string, comma rune) [][]string {
+	t.Helper()
+
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv %q: %s", s, err)
+	}
+	return records
+}
+
+func TestCSVVaryingFields(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "csv"
+formatter.options { columns = ["level", "msg", "user", "count", "err"], header = true }`)
+
+	logger.WithField("user", "bob").Info("login")
+	logger.WithFields(map[string]interface{}{"count": 3, "extra": "ignored"}).Warn("retry")
+	logger.WithField("err", errors.New("disk full")).Error("write failed")
+	logger.Info("no fields")
+
+	expected := [][]string{
+		{"level", "msg", "user", "count", "err"},
+		{"info", "login", "bob", "", ""},
+		{"warning", "retry", "", "3", ""},
+		{"error", "write failed", "", "", "disk full"},
+		{"info", "no fields", "", "", ""},
+	}
+
+	if records := readCSV(t, buf.String(), ','); !reflect.DeepEqual(records, expected) {
+		t.Fatalf("records %q", records)
+	}
+}
+
+func TestCSVQuoting(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "csv"
+formatter.options.columns = ["msg", "note"]`)
+
+	msg := `Hello, I am "A" Logger`
+	note := "two\nlines"
+	logger.WithField("note", note).Info(msg)
+
+	records := readCSV(t, buf.String(), ',')
+	if len(records) != 1 || records[0][0] != msg || records[0][1] != note {
+		t.Fatalf("records %q", records)
+	}
+
+	if line := buf.String(); !strings.HasPrefix(line, `"Hello, I am ""A"" Logger","two`) {
+		t.Fatalf("line %q", line)
+	}
+}
+
+func TestCSVTimeAndDelimiter(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "csv"
+formatter.options { columns = ["time", "msg"], timestamp-format = "2006-01-02", delimiter = ";" }`)
+
+	logger.Info("a,b")
+
+	records := readCSV(t, buf.String(), ';')
+	if len(records) != 1 || len(records[0][0]) != len("2006-01-02") || records[0][1] != "a,b" {
+		t.Fatalf("records %q", records)
+	}
+	if strings.Contains(buf.String(), `"`) {
+		t.Fatalf("the comma needn't quote with the delimiter ;: %q", buf.String())
+	}
+}
+
+func TestCSVInvalidDelimiter(t *testing.T) {
+	if _, err := NewCSVFormatter(configOf(`delimiter = ";;"`)); err == nil {
+		t.Fatal("expected error")
+	}
+}
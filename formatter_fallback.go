@@ -0,0 +1,81 @@
+package logrus_mate
+
+import (
+	"fmt"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// formatterErrorKey carries the error of the primary formatter in the entry formatted by fallback
+const formatterErrorKey = "formatter_error"
+
+// fallbackFormatter formats the entry by the fallback when the primary formatter
+// returned an error or panicked, e.g. json on an unmarshalable field, so the entry
+// is not lost and the output stays one line per entry
+type fallbackFormatter struct {
+	logrus.Formatter
+	fallback logrus.Formatter
+}
+
+// withFallbackFormatter wraps formatter by the fallback of
+// formatter { fallback { name = "text", options {...} } }, text by default
+func withFallbackFormatter(formatter logrus.Formatter, formatterConf config.Configuration) (logrus.Formatter, error) {
+	if formatterConf == nil || !formatterConf.HasPath("fallback") {
+		return formatter, nil
+	}
+
+	fallbackConf := formatterConf.GetConfig("fallback")
+
+	name := "text"
+	var optionsConf config.Configuration
+	if fallbackConf != nil {
+		name = fallbackConf.GetString("name", name)
+		optionsConf = fallbackConf.GetConfig("options")
+	}
+
+	fallback, err := NewFormatter(name, optionsConf)
+	if err != nil {
+		return nil, fmt.Errorf("logurs mate: fallback formatter %s: %s", name, err)
+	}
+
+	return &fallbackFormatter{Formatter: formatter, fallback: fallback}, nil
+}
+
+func (p *fallbackFormatter) Format(entry *logrus.Entry) (serialized []byte, err error) {
+	if serialized, err = p.format(entry); err == nil {
+		return
+	}
+
+	// the fields of entry are shared by the other outputs, the error is added to a copy
+	fields := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+	fields[formatterErrorKey] = err.Error()
+
+	fallbackEntry := *entry
+	fallbackEntry.Data = fields
+	if fallbackEntry.Buffer != nil {
+		// the primary may have written a part before failing
+		fallbackEntry.Buffer.Reset()
+	}
+
+	primaryErr := err
+	if serialized, err = p.fallback.Format(&fallbackEntry); err != nil {
+		err = fmt.Errorf("logurs mate: format failed: %s, fallback failed: %s", primaryErr, err)
+	}
+
+	return
+}
+
+// format is the primary Format with the panic returned as error
+func (p *fallbackFormatter) format(entry *logrus.Entry) (serialized []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			serialized, err = nil, fmt.Errorf("formatter panic: %v", r)
+		}
+	}()
+
+	return p.Formatter.Format(entry)
+}
@@ -0,0 +1,86 @@
+package logrus_mate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFallbackFormatterOnJSONError(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+formatter {
+    name = "json"
+    fallback {
+        name = "text"
+        options.disable-colors = true
+        options.disable-timestamp = true
+    }
+}`)
+
+	logger.WithField("ch", make(chan int)).WithField("user", "mike").Info("unmarshalable")
+	logger.WithField("user", "mike").Info("fine")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("output %q", buf.String())
+	}
+
+	// the broken entry is written by text with the json error, the next is json again
+	if l := lines[0]; !strings.Contains(l, `msg=unmarshalable`) || !strings.Contains(l, `user=mike`) || !strings.Contains(l, formatterErrorKey+`=`) {
+		t.Fatalf("fallback line %q", l)
+	}
+	if l := lines[1]; !strings.HasPrefix(l, `{`) || !strings.Contains(l, `"msg":"fine"`) {
+		t.Fatalf("primary line %q", l)
+	}
+}
+
+type panicFormatter struct{}
+
+func (panicFormatter) Format(*logrus.Entry) ([]byte, error) {
+	panic("test formatter panic")
+}
+
+type errorFormatter struct{}
+
+func (errorFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, errors.New("test formatter failed")
+}
+
+func TestFallbackFormatterOnPanic(t *testing.T) {
+	formatter := &fallbackFormatter{Formatter: panicFormatter{}, fallback: &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}}
+
+	entry := logrus.NewEntry(logrus.New()).WithField("user", "mike")
+	entry.Message = "boom"
+
+	serialized, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(serialized); !strings.Contains(s, "test formatter panic") || !strings.Contains(s, "msg=boom") {
+		t.Fatalf("serialized %q", s)
+	}
+
+	// the error field is added to a copy, the shared fields are not changed
+	if _, exist := entry.Data[formatterErrorKey]; exist {
+		t.Fatalf("entry data %v", entry.Data)
+	}
+}
+
+func TestFallbackFormatterBothFail(t *testing.T) {
+	formatter := &fallbackFormatter{Formatter: panicFormatter{}, fallback: errorFormatter{}}
+
+	_, err := formatter.Format(logrus.NewEntry(logrus.New()))
+	if err == nil || !strings.Contains(err.Error(), "test formatter panic") || !strings.Contains(err.Error(), "test formatter failed") {
+		t.Fatalf("error %v", err)
+	}
+}
+
+func TestFallbackFormatterInvalid(t *testing.T) {
+	conf := `formatter { name = "json", fallback.name = "no-such-formatter" }`
+	if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+		t.Fatalf("%s is accepted", conf)
+	}
+}
@@ -1,12 +1,16 @@
 package logrus_mate
 
 import (
+	"strconv"
+
 	"github.com/gogap/config"
 	"github.com/sirupsen/logrus"
 )
 
 type JSONFormatterConfig struct {
-	TimestampFormat string `json:"timestamp_format"`
+	TimestampFormat  string `json:"timestamp_format"`
+	CallerPrettyfier string `json:"caller_prettyfier"`
+	LargeIntAsString bool   `json:"large_int_as_string"`
 }
 
 func init() {
@@ -14,10 +18,84 @@ func init() {
 }
 
 func NewJSONFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
-	var format string
+	conf := JSONFormatterConfig{}
 	if config != nil {
-		format = config.GetString("timestamp_format")
+		conf.TimestampFormat = config.GetString("timestamp_format")
+		conf.CallerPrettyfier = config.GetString("caller_prettyfier")
+		conf.LargeIntAsString = config.GetBoolean("large_int_as_string")
+	}
+
+	prettyfier, err := NewCallerPrettyfier(conf.CallerPrettyfier)
+	if err != nil {
+		return
 	}
-	formatter = &logrus.JSONFormatter{TimestampFormat: format}
+
+	jsonFormatter := &logrus.JSONFormatter{
+		TimestampFormat:  conf.TimestampFormat,
+		CallerPrettyfier: prettyfier,
+	}
+
+	if conf.LargeIntAsString {
+		formatter = &LargeIntJSONFormatter{JSONFormatter: jsonFormatter}
+		return
+	}
+
+	formatter = jsonFormatter
 	return
 }
+
+// max integer could be represented exactly by float64
+const maxSafeInteger = 1<<53 - 1
+
+// LargeIntJSONFormatter renders the integer fields beyond 2^53 as strings,
+// so the parsers using float64 for json numbers would not lose precision
+type LargeIntJSONFormatter struct {
+	*logrus.JSONFormatter
+}
+
+func (p *LargeIntJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var data logrus.Fields
+
+	for k, v := range entry.Data {
+		if s, ok := largeIntToString(v); ok {
+			if data == nil {
+				data = make(logrus.Fields, len(entry.Data))
+				for k2, v2 := range entry.Data {
+					data[k2] = v2
+				}
+			}
+			data[k] = s
+		}
+	}
+
+	if data == nil {
+		return p.JSONFormatter.Format(entry)
+	}
+
+	e := *entry
+	e.Data = data
+
+	return p.JSONFormatter.Format(&e)
+}
+
+func largeIntToString(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case int:
+		if int64(n) > maxSafeInteger || int64(n) < -maxSafeInteger {
+			return strconv.FormatInt(int64(n), 10), true
+		}
+	case int64:
+		if n > maxSafeInteger || n < -maxSafeInteger {
+			return strconv.FormatInt(n, 10), true
+		}
+	case uint:
+		if uint64(n) > maxSafeInteger {
+			return strconv.FormatUint(uint64(n), 10), true
+		}
+	case uint64:
+		if n > maxSafeInteger {
+			return strconv.FormatUint(n, 10), true
+		}
+	}
+	return "", false
+}
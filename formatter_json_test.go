@@ -0,0 +1,41 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONLargeIntAsString(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "json"
+formatter.options.large_int_as_string = true`)
+
+	logger.WithFields(map[string]interface{}{
+		"id":    uint64(12345678901234567890),
+		"neg":   int64(-9007199254740993),
+		"small": 9007199254740991,
+		"float": 1.5,
+	}).Info("ids")
+
+	line := buf.String()
+	for _, expected := range []string{
+		`"id":"12345678901234567890"`,
+		`"neg":"-9007199254740993"`,
+		`"small":9007199254740991`,
+		`"float":1.5`,
+	} {
+		if !strings.Contains(line, expected) {
+			t.Fatalf("%s not in %s", expected, line)
+		}
+	}
+}
+
+func TestJSONLargeIntAsNumberByDefault(t *testing.T) {
+	logger, buf := hijackString(t, `formatter.name = "json"`)
+
+	logger.WithField("id", uint64(12345678901234567890)).Info("ids")
+
+	if line := buf.String(); !strings.Contains(line, `"id":12345678901234567890`) {
+		t.Fatalf("the large int is changed without the option: %s", line)
+	}
+}
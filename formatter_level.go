@@ -0,0 +1,70 @@
+package logrus_mate
+
+import (
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// LevelFormatter dispatches the entry to the formatter of entry.Level,
+// the Default formatter is used for the levels not configured
+type LevelFormatter struct {
+	Default    logrus.Formatter
+	Formatters map[logrus.Level]logrus.Formatter
+}
+
+func init() {
+	RegisterFormatter("level", NewLevelFormatter)
+}
+
+func NewLevelFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
+	f := &LevelFormatter{
+		Formatters: make(map[logrus.Level]logrus.Formatter),
+	}
+
+	if config != nil {
+		for _, key := range config.Keys() {
+			if key == "default" {
+				continue
+			}
+
+			var lvl logrus.Level
+			if lvl, err = ParseLevel(key); err != nil {
+				return
+			}
+
+			if f.Formatters[lvl], err = newSubFormatter(config.GetConfig(key)); err != nil {
+				return
+			}
+		}
+
+		if f.Default, err = newSubFormatter(config.GetConfig("default")); err != nil {
+			return
+		}
+	} else {
+		f.Default = &logrus.TextFormatter{}
+	}
+
+	formatter = f
+
+	return
+}
+
+func newSubFormatter(conf config.Configuration) (formatter logrus.Formatter, err error) {
+	name := "text"
+
+	var optionsConf config.Configuration
+
+	if conf != nil {
+		name = conf.GetString("name", "text")
+		optionsConf = conf.GetConfig("options")
+	}
+
+	return NewFormatter(name, optionsConf)
+}
+
+func (p *LevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if f, exist := p.Formatters[entry.Level]; exist {
+		return f.Format(entry)
+	}
+	return p.Default.Format(entry)
+}
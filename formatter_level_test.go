@@ -0,0 +1,39 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevelFormatter(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter {
+    name = "level"
+    options {
+        error.name   = "json"
+        default.name = "text"
+        default.options.disable-colors = true
+    }
+}`)
+
+	logger.Error("failed")
+	errorLine := buf.String()
+	buf.Reset()
+
+	logger.Info("started")
+	infoLine := buf.String()
+
+	if !strings.HasPrefix(errorLine, "{") || !strings.Contains(errorLine, `"msg":"failed"`) {
+		t.Fatalf("the error entry is not json: %q", errorLine)
+	}
+
+	if !strings.Contains(infoLine, "level=info") || !strings.Contains(infoLine, "msg=started") {
+		t.Fatalf("the info entry is not text: %q", infoLine)
+	}
+}
+
+func TestLevelFormatterBadLevel(t *testing.T) {
+	if _, err := NewFormatter("level", configOf(`verbose.name = "json"`)); err == nil {
+		t.Fatal("expected the error of unknown level")
+	}
+}
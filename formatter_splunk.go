@@ -0,0 +1,106 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+type SplunkFormatterConfig struct {
+	TimestampFormat string
+	EventBreaker    string
+}
+
+// SplunkFormatter formats entry as key=value pairs in the way Splunk extracts:
+// 2006-01-02T15:04:05.000-07:00 level=info msg="user login" user=mike
+type SplunkFormatter struct {
+	Config SplunkFormatterConfig
+}
+
+var splunkValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", `\r`, "\n", `\n`)
+
+func init() {
+	RegisterFormatter("splunk", NewSplunkFormatter)
+}
+
+func NewSplunkFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
+	conf := SplunkFormatterConfig{
+		TimestampFormat: "2006-01-02T15:04:05.000-07:00",
+		EventBreaker:    "\n",
+	}
+
+	if config != nil {
+		conf.TimestampFormat = config.GetString("timestamp-format", conf.TimestampFormat)
+		conf.EventBreaker = config.GetString("event-breaker", conf.EventBreaker)
+	}
+
+	formatter = &SplunkFormatter{Config: conf}
+
+	return
+}
+
+func (p *SplunkFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	b.WriteString(entry.Time.Format(p.Config.TimestampFormat))
+
+	p.writePair(b, "level", entry.Level.String())
+	p.writePair(b, "msg", entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := entry.Data[k]
+		if e, ok := v.(error); ok {
+			v = e.Error()
+		}
+		p.writePair(b, splunkKey(k), fmt.Sprint(v))
+	}
+
+	b.WriteString(p.Config.EventBreaker)
+
+	return b.Bytes(), nil
+}
+
+func (p *SplunkFormatter) writePair(b *bytes.Buffer, key, value string) {
+	b.WriteByte(' ')
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	if !splunkNeedsQuote(value) {
+		b.WriteString(value)
+		return
+	}
+
+	b.WriteByte('"')
+	b.WriteString(splunkValueEscaper.Replace(value))
+	b.WriteByte('"')
+}
+
+// splunkNeedsQuote reports whether value breaks the automatic key=value extraction,
+// the bare tokens are kept unquoted
+func splunkNeedsQuote(value string) bool {
+	if len(value) == 0 {
+		return true
+	}
+	return strings.ContainsAny(value, " \t\r\n\"=,;|")
+}
+
+// splunkKey replaces the chars other than letters, digits and underscore,
+// which Splunk does not accept in field names
+func splunkKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
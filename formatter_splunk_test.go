@@ -0,0 +1,68 @@
+package logrus_mate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func splunkFormat(t *testing.T, conf string, message string, fields logrus.Fields) string {
+	t.Helper()
+
+	formatter, err := NewFormatter("splunk", configOf(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	entry.Time = time.Date(2024, 1, 1, 10, 0, 0, 123e6, time.FixedZone("", -7*3600))
+	entry.Level = logrus.InfoLevel
+	entry.Message = message
+
+	b, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// the examples of the Splunk logging best practices: use clear key-value pairs,
+// quote the values having spaces
+func TestSplunkFormatter(t *testing.T) {
+	line := splunkFormat(t, ``, "user login", logrus.Fields{
+		"user":      "mike",
+		"action":    "purchase",
+		"item":      "Red Shirt",
+		"price":     12.5,
+		"status":    200,
+		"error":     errors.New(`bad "token"`),
+		"empty":     "",
+		"client-ip": "10.0.0.1",
+	})
+
+	expected := `2024-01-01T10:00:00.123-07:00 level=info msg="user login" action=purchase client_ip=10.0.0.1 empty="" error="bad \"token\"" item="Red Shirt" price=12.5 status=200 user=mike` + "\n"
+	if line != expected {
+		t.Fatalf("\n%s\nexpected\n%s", line, expected)
+	}
+}
+
+func TestSplunkFormatterQuoting(t *testing.T) {
+	line := splunkFormat(t, ``, "a=b,c", logrus.Fields{"q": "x;y|z", "nl": "one\ntwo"})
+
+	expected := `2024-01-01T10:00:00.123-07:00 level=info msg="a=b,c" nl="one\ntwo" q="x;y|z"` + "\n"
+	if line != expected {
+		t.Fatalf("\n%s\nexpected\n%s", line, expected)
+	}
+}
+
+func TestSplunkFormatterEventBreaker(t *testing.T) {
+	line := splunkFormat(t, `
+timestamp-format = "01/02/2006 15:04:05"
+event-breaker = "\n###\n"`, "done", nil)
+
+	if expected := "01/01/2024 10:00:00 level=info msg=done\n###\n"; line != expected {
+		t.Fatalf("%q, expected %q", line, expected)
+	}
+}
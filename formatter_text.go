@@ -1,6 +1,11 @@
 package logrus_mate
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/gogap/config"
 	"github.com/sirupsen/logrus"
 )
@@ -13,6 +18,8 @@ func NewTextFormatter(config config.Configuration) (formatter logrus.Formatter,
 
 	f := &logrus.TextFormatter{}
 
+	expandErrors := false
+
 	if config != nil {
 		f.ForceColors = config.GetBoolean("force-colors")
 		f.DisableColors = config.GetBoolean("disable-colors")
@@ -20,9 +27,88 @@ func NewTextFormatter(config config.Configuration) (formatter logrus.Formatter,
 		f.FullTimestamp = config.GetBoolean("full-timestamp")
 		f.TimestampFormat = config.GetString("timestamp-format")
 		f.DisableSorting = config.GetBoolean("disable-sorting")
+		expandErrors = config.GetBoolean("expand-errors")
+	}
+
+	if expandErrors {
+		formatter = &ExpandErrorsTextFormatter{TextFormatter: f}
+		return
 	}
 
 	formatter = f
 
 	return
 }
+
+type stackTracer interface {
+	StackTrace() string
+}
+
+// ExpandErrorsTextFormatter renders the error fields carrying stack, like errors of
+// github.com/pkg/errors (%+v) or github.com/gogap/errors (StackTrace), in a block
+// below the line, the line keeps the flat error message
+type ExpandErrorsTextFormatter struct {
+	*logrus.TextFormatter
+}
+
+func (p *ExpandErrorsTextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	expanded := map[string]string{}
+
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			if stack, ok := expandError(err); ok {
+				expanded[k] = stack
+			}
+		}
+	}
+
+	if len(expanded) == 0 {
+		return p.TextFormatter.Format(entry)
+	}
+
+	e := *entry
+	e.Data = make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if _, ok := expanded[k]; ok {
+			v = v.(error).Error()
+		}
+		e.Data[k] = v
+	}
+
+	line, err := p.TextFormatter.Format(&e)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(expanded))
+	for k := range expanded {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := bytes.NewBuffer(bytes.TrimRight(line, "\n"))
+	for _, k := range keys {
+		b.WriteString("\n\t")
+		b.WriteString(k)
+		b.WriteString(":\n\t\t")
+		b.WriteString(strings.Replace(strings.TrimRight(expanded[k], "\n"), "\n", "\n\t\t", -1))
+	}
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+func expandError(err error) (string, bool) {
+	if st, ok := err.(stackTracer); ok {
+		return err.Error() + "\n" + st.StackTrace(), true
+	}
+
+	if _, ok := err.(fmt.Formatter); ok {
+		s := fmt.Sprintf("%+v", err)
+		if s != err.Error() {
+			return s, true
+		}
+	}
+
+	return "", false
+}
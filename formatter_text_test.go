@@ -0,0 +1,81 @@
+package logrus_mate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stackError formats like the errors of github.com/pkg/errors, %+v adds the stack
+type stackError struct {
+	cause error
+	stack string
+}
+
+func (e *stackError) Error() string { return "wrapped: " + e.cause.Error() }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.Error()+"\n"+e.stack)
+		return
+	}
+	_, _ = io.WriteString(s, e.Error())
+}
+
+// tracedError carries the stack like the errors of github.com/gogap/errors
+type tracedError struct{}
+
+func (tracedError) Error() string      { return "traced" }
+func (tracedError) StackTrace() string { return "main.run()\n\tmain.go:7" }
+
+func TestTextExpandErrors(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "text"
+formatter.options { disable-colors = true, disable-timestamp = true, expand-errors = true }`)
+
+	logger.WithFields(map[string]interface{}{
+		"error":  &stackError{cause: errors.New("disk full"), stack: "main.save()\n\tmain.go:42\nmain.main()\n\tmain.go:10"},
+		"traced": tracedError{},
+		"plain":  errors.New("flat"),
+	}).Error("save failed")
+
+	expected := `level=error msg="save failed" error="wrapped: disk full" plain=flat traced=traced
+	error:
+		wrapped: disk full
+		main.save()
+			main.go:42
+		main.main()
+			main.go:10
+	traced:
+		traced
+		main.run()
+			main.go:7
+`
+	if out := buf.String(); out != expected {
+		t.Fatalf("\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestTextExpandErrorsWithoutStack(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "text"
+formatter.options { disable-colors = true, disable-timestamp = true, expand-errors = true }`)
+
+	logger.WithError(errors.New("flat")).Error("failed")
+
+	if out := buf.String(); out != "level=error msg=failed error=flat\n" {
+		t.Fatalf("the error without stack is expanded: %q", out)
+	}
+}
+
+func TestJSONKeepsErrorsCompact(t *testing.T) {
+	logger, buf := hijackString(t, `formatter.name = "json"`)
+
+	logger.WithError(&stackError{cause: errors.New("disk full"), stack: "main.save()"}).Error("failed")
+
+	if out := buf.String(); strings.Count(out, "\n") != 1 || !strings.Contains(out, `"error":"wrapped: disk full"`) {
+		t.Fatalf("json %q", out)
+	}
+}
@@ -1,8 +1,9 @@
 package logrus_mate
 
 import (
-	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gogap/config"
@@ -14,10 +15,6 @@ var (
 	newFormatterFuncs = make(map[string]NewFormatterFunc)
 )
 
-var (
-	errFormatterNotRegistered = errors.New("formatter not registerd")
-)
-
 type NewFormatterFunc func(config.Configuration) (formatter logrus.Formatter, err error)
 
 func RegisterFormatter(name string, newFormatterFunc NewFormatterFunc) {
@@ -39,9 +36,16 @@ func RegisterFormatter(name string, newFormatterFunc NewFormatterFunc) {
 	newFormatterFuncs[name] = newFormatterFunc
 }
 
+// Formatters returns the names of registered formatters sorted, the same registry
+// NewFormatter and the formatters of config resolve by
 func Formatters() []string {
 	formattersLocker.Lock()
 	defer formattersLocker.Unlock()
+	return formatterNames()
+}
+
+// formatterNames must be called with formattersLocker locked
+func formatterNames() []string {
 	var list []string
 	for name := range newFormatterFuncs {
 		list = append(list, name)
@@ -50,17 +54,57 @@ func Formatters() []string {
 	return list
 }
 
-func NewFormatter(name string, config config.Configuration) (formatter logrus.Formatter, err error) {
+// RegisteredFormatters is Formatters, for the config UIs and diagnosing the unknown formatters
+func RegisteredFormatters() []string {
+	return Formatters()
+}
+
+// errFormatterNotRegistered must be called with formattersLocker locked
+func errFormatterNotRegistered(name string) error {
+	// e.g. a typo, or the formatter package is not imported
+	return fmt.Errorf("logurs mate: formatter not registerd: %q, registered: [%s]", name, strings.Join(formatterNames(), ", "))
+}
+
+// checkFormatters returns the error of the formatter of loggerConf not registered,
+// the fallback included, so NewLogrusMate fails by the typo before the logger is created
+func checkFormatters(loggerConf config.Configuration) error {
+	formatterConf := loggerConf.GetConfig("formatter")
+	if formatterConf == nil {
+		return nil
+	}
+
+	names := []string{formatterConf.GetString("name", "text")}
+	if formatterConf.HasPath("fallback") {
+		names = append(names, formatterConf.GetString("fallback.name", "text"))
+	}
+
 	formattersLocker.Lock()
 	defer formattersLocker.Unlock()
 
-	if newFormatterFunc, exist := newFormatterFuncs[name]; !exist {
-		err = errFormatterNotRegistered
+	for _, name := range names {
+		if _, exist := newFormatterFuncs[name]; !exist {
+			return errFormatterNotRegistered(name)
+		}
+	}
+
+	return nil
+}
+
+func NewFormatter(name string, config config.Configuration) (formatter logrus.Formatter, err error) {
+	formattersLocker.Lock()
+	newFormatterFunc, exist := newFormatterFuncs[name]
+	if !exist {
+		err = errFormatterNotRegistered(name)
+	}
+	formattersLocker.Unlock()
+
+	// unlocked while creating, so formatter could be composed by other formatters
+	if !exist {
 		return
-	} else {
-		formatter, err = newFormatterFunc(config)
 	}
 
+	formatter, err = newFormatterFunc(config)
+
 	return
 }
 
@@ -0,0 +1,190 @@
+package msgpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var errShortRecord = errors.New("msgpack: short record")
+
+// Decoder reads the length framed records written by MsgpackFormatter
+type Decoder struct {
+	r *bufio.Reader
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next record, io.EOF at the end of stream
+func (p *Decoder) Decode() (record map[string]interface{}, err error) {
+	var header [4]byte
+	if _, err = io.ReadFull(p.r, header[:]); err != nil {
+		return
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err = io.ReadFull(p.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	v, _, err := Unmarshal(data)
+	if err != nil {
+		return
+	}
+
+	record, ok := v.(map[string]interface{})
+	if !ok {
+		err = fmt.Errorf("msgpack: record is not a map: %T", v)
+	}
+
+	return
+}
+
+// Unmarshal decodes one value from data, returns the rest bytes
+func Unmarshal(data []byte) (v interface{}, rest []byte, err error) {
+	if len(data) == 0 {
+		err = errShortRecord
+		return
+	}
+
+	c := data[0]
+	data = data[1:]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), data, nil
+	case c >= 0xe0:
+		return int64(int8(c)), data, nil
+	case c&0xe0 == 0xa0:
+		return readString(data, int(c&0x1f))
+	case c&0xf0 == 0x90:
+		return readArray(data, int(c&0x0f))
+	case c&0xf0 == 0x80:
+		return readMap(data, int(c&0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, data, nil
+	case 0xc2:
+		return false, data, nil
+	case 0xc3:
+		return true, data, nil
+	case 0xc4, 0xc5, 0xc6:
+		var l uint64
+		if l, data, err = readUint(data, 1<<(c-0xc4)); err != nil {
+			return
+		}
+		if uint64(len(data)) < l {
+			err = errShortRecord
+			return
+		}
+		return append([]byte(nil), data[:l]...), data[l:], nil
+	case 0xca:
+		var u uint64
+		if u, data, err = readUint(data, 4); err != nil {
+			return
+		}
+		return float64(math.Float32frombits(uint32(u))), data, nil
+	case 0xcb:
+		var u uint64
+		if u, data, err = readUint(data, 8); err != nil {
+			return
+		}
+		return math.Float64frombits(u), data, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		var u uint64
+		if u, data, err = readUint(data, 1<<(c-0xcc)); err != nil {
+			return
+		}
+		if u > math.MaxInt64 {
+			return u, data, nil
+		}
+		return int64(u), data, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		var u uint64
+		if u, data, err = readUint(data, size); err != nil {
+			return
+		}
+		// sign extend
+		shift := uint(64 - size*8)
+		return int64(u<<shift) >> shift, data, nil
+	case 0xd9, 0xda, 0xdb:
+		var l uint64
+		if l, data, err = readUint(data, 1<<(c-0xd9)); err != nil {
+			return
+		}
+		return readString(data, int(l))
+	case 0xdc, 0xdd:
+		var l uint64
+		if l, data, err = readUint(data, 2<<(c-0xdc)); err != nil {
+			return
+		}
+		return readArray(data, int(l))
+	case 0xde, 0xdf:
+		var l uint64
+		if l, data, err = readUint(data, 2<<(c-0xde)); err != nil {
+			return
+		}
+		return readMap(data, int(l))
+	}
+
+	err = fmt.Errorf("msgpack: unsupported type 0x%x", c)
+
+	return
+}
+
+func readUint(data []byte, size int) (v uint64, rest []byte, err error) {
+	if len(data) < size {
+		err = errShortRecord
+		return
+	}
+	for i := 0; i < size; i++ {
+		v = v<<8 | uint64(data[i])
+	}
+	return v, data[size:], nil
+}
+
+func readString(data []byte, l int) (v interface{}, rest []byte, err error) {
+	if len(data) < l {
+		err = errShortRecord
+		return
+	}
+	return string(data[:l]), data[l:], nil
+}
+
+func readArray(data []byte, l int) (v interface{}, rest []byte, err error) {
+	array := make([]interface{}, 0, l)
+	for i := 0; i < l; i++ {
+		var e interface{}
+		if e, data, err = Unmarshal(data); err != nil {
+			return
+		}
+		array = append(array, e)
+	}
+	return array, data, nil
+}
+
+func readMap(data []byte, l int) (v interface{}, rest []byte, err error) {
+	m := make(map[string]interface{}, l)
+	for i := 0; i < l; i++ {
+		var k, e interface{}
+		if k, data, err = Unmarshal(data); err != nil {
+			return
+		}
+		if e, data, err = Unmarshal(data); err != nil {
+			return
+		}
+		m[fmt.Sprint(k)] = e
+	}
+	return m, data, nil
+}
@@ -0,0 +1,269 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type MsgpackFormatterConfig struct {
+	TimestampFormat string
+	Framing         string
+}
+
+// MsgpackFormatter serializes entry as a MessagePack map of time, level, msg and fields.
+// With framing "length" (default) every record is prefixed by its uint32 big endian length,
+// so the stream could be split by Decoder whatever bytes the record contains.
+// It is binary, use strip-colors = false and max-size instead of max-lines for file hook.
+type MsgpackFormatter struct {
+	Config MsgpackFormatterConfig
+}
+
+func init() {
+	logrus_mate.RegisterFormatter("msgpack", NewMsgpackFormatter)
+}
+
+func NewMsgpackFormatter(config config.Configuration) (formatter logrus.Formatter, err error) {
+	conf := MsgpackFormatterConfig{
+		TimestampFormat: time.RFC3339Nano,
+		Framing:         "length",
+	}
+
+	if config != nil {
+		conf.TimestampFormat = config.GetString("timestamp-format", conf.TimestampFormat)
+		conf.Framing = config.GetString("framing", conf.Framing)
+	}
+
+	if conf.Framing != "length" && conf.Framing != "none" {
+		err = fmt.Errorf("logurs mate: msgpack framing should be length or none: %s", conf.Framing)
+		return
+	}
+
+	formatter = &MsgpackFormatter{Config: conf}
+
+	return
+}
+
+func (p *MsgpackFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		switch k {
+		case "time", "level", "msg":
+			k = "fields." + k
+		}
+		data[k] = v
+	}
+
+	data["time"] = entry.Time.Format(p.Config.TimestampFormat)
+	data["level"] = entry.Level.String()
+	data["msg"] = entry.Message
+
+	b := &bytes.Buffer{}
+	if p.Config.Framing == "length" {
+		b.Write([]byte{0, 0, 0, 0})
+	}
+
+	encode(b, data)
+
+	record := b.Bytes()
+	if p.Config.Framing == "length" {
+		binary.BigEndian.PutUint32(record, uint32(len(record)-4))
+	}
+
+	return record, nil
+}
+
+func encode(b *bytes.Buffer, v interface{}) {
+	switch n := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if n {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case int:
+		encodeInt(b, int64(n))
+	case int8:
+		encodeInt(b, int64(n))
+	case int16:
+		encodeInt(b, int64(n))
+	case int32:
+		encodeInt(b, int64(n))
+	case int64:
+		encodeInt(b, n)
+	case uint:
+		encodeUint(b, uint64(n))
+	case uint8:
+		encodeUint(b, uint64(n))
+	case uint16:
+		encodeUint(b, uint64(n))
+	case uint32:
+		encodeUint(b, uint64(n))
+	case uint64:
+		encodeUint(b, n)
+	case float32:
+		b.WriteByte(0xca)
+		writeUint(b, uint64(math.Float32bits(n)), 4)
+	case float64:
+		b.WriteByte(0xcb)
+		writeUint(b, math.Float64bits(n), 8)
+	case string:
+		encodeString(b, n)
+	case []byte:
+		encodeBytes(b, n)
+	case time.Time:
+		encodeString(b, n.Format(time.RFC3339Nano))
+	case error:
+		encodeString(b, n.Error())
+	case []string:
+		encodeArrayHeader(b, len(n))
+		for _, s := range n {
+			encodeString(b, s)
+		}
+	case []interface{}:
+		encodeArrayHeader(b, len(n))
+		for _, e := range n {
+			encode(b, e)
+		}
+	case logrus.Fields:
+		encode(b, map[string]interface{}(n))
+	case map[string]string:
+		m := make(map[string]interface{}, len(n))
+		for k, s := range n {
+			m[k] = s
+		}
+		encode(b, m)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		encodeMapHeader(b, len(keys))
+		for _, k := range keys {
+			encodeString(b, k)
+			encode(b, n[k])
+		}
+	case fmt.Stringer:
+		encodeString(b, n.String())
+	default:
+		encodeString(b, fmt.Sprintf("%v", n))
+	}
+}
+
+func writeUint(b *bytes.Buffer, v uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		b.WriteByte(byte(v >> (uint(i) * 8)))
+	}
+}
+
+func encodeInt(b *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0:
+		encodeUint(b, uint64(v))
+	case v >= -32:
+		b.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		b.WriteByte(0xd0)
+		writeUint(b, uint64(v), 1)
+	case v >= math.MinInt16:
+		b.WriteByte(0xd1)
+		writeUint(b, uint64(v), 2)
+	case v >= math.MinInt32:
+		b.WriteByte(0xd2)
+		writeUint(b, uint64(v), 4)
+	default:
+		b.WriteByte(0xd3)
+		writeUint(b, uint64(v), 8)
+	}
+}
+
+func encodeUint(b *bytes.Buffer, v uint64) {
+	switch {
+	case v <= 0x7f:
+		b.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		b.WriteByte(0xcc)
+		writeUint(b, v, 1)
+	case v <= math.MaxUint16:
+		b.WriteByte(0xcd)
+		writeUint(b, v, 2)
+	case v <= math.MaxUint32:
+		b.WriteByte(0xce)
+		writeUint(b, v, 4)
+	default:
+		b.WriteByte(0xcf)
+		writeUint(b, v, 8)
+	}
+}
+
+func encodeString(b *bytes.Buffer, s string) {
+	l := len(s)
+	switch {
+	case l < 32:
+		b.WriteByte(0xa0 | byte(l))
+	case l <= math.MaxUint8:
+		b.WriteByte(0xd9)
+		writeUint(b, uint64(l), 1)
+	case l <= math.MaxUint16:
+		b.WriteByte(0xda)
+		writeUint(b, uint64(l), 2)
+	default:
+		b.WriteByte(0xdb)
+		writeUint(b, uint64(l), 4)
+	}
+	b.WriteString(s)
+}
+
+func encodeBytes(b *bytes.Buffer, p []byte) {
+	l := len(p)
+	switch {
+	case l <= math.MaxUint8:
+		b.WriteByte(0xc4)
+		writeUint(b, uint64(l), 1)
+	case l <= math.MaxUint16:
+		b.WriteByte(0xc5)
+		writeUint(b, uint64(l), 2)
+	default:
+		b.WriteByte(0xc6)
+		writeUint(b, uint64(l), 4)
+	}
+	b.Write(p)
+}
+
+func encodeArrayHeader(b *bytes.Buffer, l int) {
+	switch {
+	case l < 16:
+		b.WriteByte(0x90 | byte(l))
+	case l <= math.MaxUint16:
+		b.WriteByte(0xdc)
+		writeUint(b, uint64(l), 2)
+	default:
+		b.WriteByte(0xdd)
+		writeUint(b, uint64(l), 4)
+	}
+}
+
+func encodeMapHeader(b *bytes.Buffer, l int) {
+	switch {
+	case l < 16:
+		b.WriteByte(0x80 | byte(l))
+	case l <= math.MaxUint16:
+		b.WriteByte(0xde)
+		writeUint(b, uint64(l), 2)
+	default:
+		b.WriteByte(0xdf)
+		writeUint(b, uint64(l), 4)
+	}
+}
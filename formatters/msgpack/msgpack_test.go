@@ -0,0 +1,128 @@
+package msgpack
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestFormatter(t *testing.T, conf string) logrus.Formatter {
+	t.Helper()
+
+	formatter, err := NewMsgpackFormatter(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return formatter
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	formatter := newTestFormatter(t, `timestamp-format = "2006-01-02T15:04:05Z07:00"`)
+
+	logger := logrus.New()
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	entries := []*logrus.Entry{
+		logger.WithFields(logrus.Fields{
+			"int":    -3,
+			"big":    int64(math.MinInt64),
+			"uint":   uint64(math.MaxUint64),
+			"float":  1.5,
+			"bool":   true,
+			"nil":    nil,
+			"err":    errors.New("boom"),
+			"list":   []string{"a", "b"},
+			"nested": map[string]interface{}{"k": uint8(200)},
+			"long":   strings.Repeat("x", 70000),
+			"msg":    "shadowed",
+		}).WithTime(at),
+		logger.WithTime(at),
+	}
+	entries[0].Level, entries[0].Message = logrus.ErrorLevel, "line\nbreak"
+	entries[1].Level, entries[1].Message = logrus.InfoLevel, ""
+
+	stream := &bytes.Buffer{}
+	for _, entry := range entries {
+		b, err := formatter.Format(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Write(b)
+	}
+
+	decoder := NewDecoder(stream)
+
+	first, err := decoder.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"time":       "2024-01-01T10:00:00Z",
+		"level":      "error",
+		"msg":        "line\nbreak",
+		"fields.msg": "shadowed",
+		"int":        int64(-3),
+		"big":        int64(math.MinInt64),
+		"uint":       uint64(math.MaxUint64),
+		"float":      1.5,
+		"bool":       true,
+		"nil":        nil,
+		"err":        "boom",
+		"list":       []interface{}{"a", "b"},
+		"nested":     map[string]interface{}{"k": int64(200)},
+		"long":       strings.Repeat("x", 70000),
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("decoded %v\nexpected %v", first, expected)
+	}
+
+	second, err := decoder.Decode()
+	if err != nil || second["level"] != "info" || second["msg"] != "" || len(second) != 3 {
+		t.Fatalf("decoded %v %v", second, err)
+	}
+
+	if _, err = decoder.Decode(); err != io.EOF {
+		t.Fatalf("expected EOF at the end: %v", err)
+	}
+}
+
+func TestMsgpackTruncatedRecord(t *testing.T) {
+	b, err := newTestFormatter(t, ``).Format(logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewDecoder(bytes.NewReader(b[:len(b)-1])).Decode(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected unexpected EOF: %v", err)
+	}
+}
+
+func TestMsgpackUnframed(t *testing.T) {
+	b, err := newTestFormatter(t, `framing = "none"`).Format(logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, rest, err := Unmarshal(b)
+	if err != nil || len(rest) != 0 {
+		t.Fatalf("unmarshal %v %v", rest, err)
+	}
+	if record, ok := v.(map[string]interface{}); !ok || record["level"] != "panic" {
+		t.Fatalf("record %v", v)
+	}
+}
+
+func TestMsgpackBadFraming(t *testing.T) {
+	if _, err := NewMsgpackFormatter(config.NewConfig(config.ConfigString(`framing = "newline"`))); err == nil {
+		t.Fatal("the unknown framing is accepted")
+	}
+}
@@ -0,0 +1,54 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// assertUnknownFormatter checks err names the misspelled formatter and lists the registered
+func assertUnknownFormatter(t *testing.T, err error) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatal("the misspelled formatter is accepted")
+	}
+	for _, s := range []string{`"jsom"`, "json", "text"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("%s not in the error: %s", s, err)
+		}
+	}
+}
+
+func TestUnknownFormatter(t *testing.T) {
+	_, err := NewFormatter("jsom", nil)
+	assertUnknownFormatter(t, err)
+}
+
+func TestUnknownFormatterHijack(t *testing.T) {
+	logger := logrus.New()
+	formatter := logger.Formatter
+
+	err := Hijack(logger, ConfigString(`formatter.name = "jsom"`))
+	assertUnknownFormatter(t, err)
+
+	// never falls back silently, the logger is kept as it was
+	if logger.Formatter != formatter {
+		t.Fatalf("formatter replaced by %T", logger.Formatter)
+	}
+}
+
+func TestUnknownFormatterMate(t *testing.T) {
+	// the loggers are created later by Logger(name), the typo fails the construction
+	for _, conf := range []string{
+		`mike { formatter.name = "jsom" }`,
+		`mike { formatter { name = "json", fallback.name = "jsom" } }`,
+	} {
+		_, err := NewLogrusMate(ConfigString(conf))
+		assertUnknownFormatter(t, err)
+		if !strings.Contains(err.Error(), "logger mike") {
+			t.Fatalf("the logger not in the error: %s", err)
+		}
+	}
+}
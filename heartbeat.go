@@ -0,0 +1,114 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// heartbeat logs the message through the logger at every interval, so the
+// downstream monitoring could alert when the heartbeats stop arriving.
+// It is added to the logger as a hook which fires nothing, so Close of the
+// hook, e.g. by CloseWithTimeout, stops it.
+type heartbeat struct {
+	logger   *logrus.Logger
+	interval time.Duration
+	message  string
+	level    logrus.Level
+
+	// newTicker is injectable for tests
+	newTicker func(d time.Duration) (<-chan time.Time, func())
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newHeartbeat(logger *logrus.Logger, conf config.Configuration) (hb *heartbeat, err error) {
+	hb = &heartbeat{
+		logger:    logger,
+		interval:  conf.GetTimeDuration("interval", time.Minute),
+		message:   conf.GetString("message", "heartbeat"),
+		newTicker: heartbeatTicker,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if hb.interval <= 0 {
+		err = fmt.Errorf("logurs mate: heartbeat interval should be positive, got %s", hb.interval)
+		return
+	}
+
+	if hb.level, err = ParseLevel(conf.GetString("level", "info")); err != nil {
+		return
+	}
+
+	return
+}
+
+// heartbeatTicker creates the ticker of the heartbeats hijacked, replaced by the tests
+var heartbeatTicker = newTimeTicker
+
+func newTimeTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+func (p *heartbeat) start() {
+	c, stop := p.newTicker(p.interval)
+
+	go func() {
+		defer close(p.done)
+		defer stop()
+
+		for {
+			select {
+			case <-c:
+				p.logger.Log(p.level, p.message)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *heartbeat) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel}
+}
+
+func (p *heartbeat) Fire(entry *logrus.Entry) error {
+	return nil
+}
+
+// closeHeartbeats stops the heartbeats of the hooks replaced, except the ones
+// kept, so hijacking a logger again leaves a single heartbeat running
+func closeHeartbeats(replaced, kept logrus.LevelHooks) {
+	for _, hook := range replaced[logrus.PanicLevel] {
+		hb, ok := hook.(*heartbeat)
+		if !ok || containsHook(kept[logrus.PanicLevel], hook) {
+			continue
+		}
+		_ = hb.Close()
+	}
+}
+
+func containsHook(hooks []logrus.Hook, hook logrus.Hook) bool {
+	for _, h := range hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the heartbeat and waits for the goroutine exit
+func (p *heartbeat) Close() error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+	return nil
+}
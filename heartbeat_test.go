@@ -0,0 +1,150 @@
+package logrus_mate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeTicker is the ticker of heartbeat ticked by the tests
+type fakeTicker struct {
+	c        chan time.Time
+	interval time.Duration
+	stopped  chan struct{}
+}
+
+func (p *fakeTicker) newTicker(d time.Duration) (<-chan time.Time, func()) {
+	p.interval = d
+	return p.c, func() { close(p.stopped) }
+}
+
+func TestHeartbeat(t *testing.T) {
+	logger := logrus.New()
+	hook := &recordHook{}
+	logger.Hooks.Add(hook)
+	logger.Out = &NullWriter{}
+
+	hb, err := newHeartbeat(logger, configOf(`interval = 30s, message = "alive", level = "warn"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticker := &fakeTicker{c: make(chan time.Time), stopped: make(chan struct{})}
+	hb.newTicker = ticker.newTicker
+	hb.start()
+
+	if ticker.interval != 30*time.Second {
+		t.Fatalf("ticker interval %s", ticker.interval)
+	}
+
+	// every tick is received once the previous heartbeat is logged
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		ticker.c <- now.Add(time.Duration(i) * ticker.interval)
+	}
+
+	if err = hb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ticker.stopped:
+	default:
+		t.Fatal("the ticker is not stopped by Close")
+	}
+
+	entries := hook.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("heartbeats %d, expected 3", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Level != logrus.WarnLevel || entry.Message != "alive" {
+			t.Fatalf("heartbeat %v", entry)
+		}
+	}
+
+	// Close is idempotent
+	if err = hb.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHeartbeatInvalid(t *testing.T) {
+	for _, conf := range []string{`interval = 0s`, `interval = -1s`, `level = "loud"`} {
+		if _, err := newHeartbeat(logrus.New(), configOf(conf)); err == nil {
+			t.Errorf("%s: expected error", conf)
+		}
+	}
+}
+
+func TestHeartbeatCloseWithTimeout(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+		mike {
+			out.name = "buffer"
+			heartbeat { interval = 10ms, message = "alive" }
+			hooks { test-record { id = "heartbeat" } }
+		}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("mike")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recordedBy(t, "heartbeat").Entries()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeat is logged")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err = mate.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// no heartbeat after close
+	count := len(recordedBy(t, "heartbeat").Entries())
+	time.Sleep(50 * time.Millisecond)
+	if after := len(recordedBy(t, "heartbeat").Entries()); after != count {
+		t.Fatalf("heartbeats after close: %d -> %d", count, after)
+	}
+}
+
+func TestHeartbeatHijackAgain(t *testing.T) {
+	var tickers []*fakeTicker
+	heartbeatTicker = func(d time.Duration) (<-chan time.Time, func()) {
+		ticker := &fakeTicker{c: make(chan time.Time), stopped: make(chan struct{})}
+		tickers = append(tickers, ticker)
+		return ticker.newTicker(d)
+	}
+	defer func() { heartbeatTicker = newTimeTicker }()
+
+	running := func() (n int) {
+		for _, ticker := range tickers {
+			select {
+			case <-ticker.stopped:
+			default:
+				n++
+			}
+		}
+		return
+	}
+
+	logger := logrus.New()
+	for i := 0; i < 3; i++ {
+		if err := Hijack(logger, ConfigString(`out.name = "buffer", heartbeat.interval = 1s`)); err != nil {
+			t.Fatal(err)
+		}
+		if len(tickers) != i+1 || running() != 1 {
+			t.Fatalf("hijack %d: %d tickers, %d running", i, len(tickers), running())
+		}
+	}
+
+	// the logger hijacked without heartbeat has none
+	if err := Hijack(logger, ConfigString(`out.name = "buffer"`)); err != nil {
+		t.Fatal(err)
+	}
+	if running() != 0 {
+		t.Fatalf("%d tickers running", running())
+	}
+}
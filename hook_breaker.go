@@ -0,0 +1,34 @@
+package logrus_mate
+
+import (
+	"github.com/gogap/logrus_mate/hooks/utils/breaker"
+	"github.com/sirupsen/logrus"
+)
+
+// breakerHook stops firing the failing hook for a while after the consecutive
+// failures, e.g. a network hook whose backend is down, the entries are dropped
+// from the hook meanwhile and the logging is not slowed by the retries
+type breakerHook struct {
+	hook    logrus.Hook
+	breaker *breaker.Breaker
+}
+
+func (p *breakerHook) Levels() []logrus.Level {
+	return p.hook.Levels()
+}
+
+func (p *breakerHook) Fire(entry *logrus.Entry) error {
+	err := p.breaker.Do(func() error { return p.hook.Fire(entry) })
+	if err == breaker.ErrOpen {
+		return nil
+	}
+	return err
+}
+
+// Close closes or flushes the inner hook if it could be, never stopped by the breaker
+func (p *breakerHook) Close() error {
+	if closeFunc := closeFuncOf(p.hook); closeFunc != nil {
+		return closeFunc()
+	}
+	return nil
+}
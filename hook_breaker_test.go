@@ -0,0 +1,141 @@
+package logrus_mate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// the flaky hook of tests fails every Fire while down, it counts the fires,
+// flushes and closes by its id
+func init() {
+	RegisterHook("test-flaky", func(conf config.Configuration) (logrus.Hook, error) {
+		hook := &flakyHook{down: true}
+		flakyHooks.Store(conf.GetString("id"), hook)
+		return hook, nil
+	})
+}
+
+var flakyHooks sync.Map
+
+func flakyOf(t *testing.T, id string) *flakyHook {
+	t.Helper()
+
+	hook, exist := flakyHooks.Load(id)
+	if !exist {
+		t.Fatalf("no test-flaky hook of id %q", id)
+	}
+	return hook.(*flakyHook)
+}
+
+type flakyHook struct {
+	locker  sync.Mutex
+	down    bool
+	fired   int
+	flushed int
+	closed  int
+}
+
+func (p *flakyHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (p *flakyHook) Fire(*logrus.Entry) error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.fired++
+	if p.down {
+		return errTestHook
+	}
+	return nil
+}
+
+func (p *flakyHook) Flush() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	p.flushed++
+	return nil
+}
+
+func (p *flakyHook) Close() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	p.closed++
+	return nil
+}
+
+func (p *flakyHook) counts() (fired, flushed, closed int) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	return p.fired, p.flushed, p.closed
+}
+
+func TestBreakerHookOpens(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+hooks.test-flaky { id = "breaker-open", breaker { failure-threshold = 2, open-duration = 1h } }`)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("entry")
+	}
+
+	// the entries after the threshold are dropped from the hook without firing it
+	if fired, _, _ := flakyOf(t, "breaker-open").counts(); fired != 2 {
+		t.Fatalf("fired %d, expected 2", fired)
+	}
+}
+
+func TestBreakerHookHalfOpenCloses(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+hooks.test-flaky { id = "breaker-half", breaker { failure-threshold = 1, open-duration = 20ms } }`)
+
+	flaky := flakyOf(t, "breaker-half")
+
+	logger.Info("opens")
+	logger.Info("dropped")
+	if fired, _, _ := flaky.counts(); fired != 1 {
+		t.Fatalf("fired %d while open", fired)
+	}
+
+	flaky.locker.Lock()
+	flaky.down = false
+	flaky.locker.Unlock()
+	time.Sleep(30 * time.Millisecond)
+
+	// the probe succeeds and closes it, the next entries fire again
+	logger.Info("probe")
+	logger.Info("closed")
+	if fired, _, _ := flaky.counts(); fired != 3 {
+		t.Fatalf("fired %d, expected 3", fired)
+	}
+}
+
+func TestBreakerHookClosedAndFlushed(t *testing.T) {
+	for id, hookConf := range map[string]string{
+		"breaker-close":      `breaker { failure-threshold = 1 }`,
+		"breaker-close-when": `breaker { failure-threshold = 1 }, when { user.equals = "mike" }`,
+	} {
+		mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer", hooks.test-flaky { id = "` + id + `", ` + hookConf + ` } }`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger := mate.Logger("mike")
+
+		// the open breaker never stops the flush and close
+		logger.WithField("user", "mike").Info("opens")
+
+		if err = mate.FlushAndRotate(); err != nil {
+			t.Fatal(err)
+		}
+		if err = mate.CloseWithTimeout(time.Second); err != nil {
+			t.Fatal(err)
+		}
+
+		if fired, flushed, closed := flakyOf(t, id).counts(); fired != 1 || flushed != 1 || closed != 1 {
+			t.Fatalf("%s: fired %d, flushed %d, closed %d", id, fired, flushed, closed)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldPredicate matches a field of entry data by equals, contains or matches
+type fieldPredicate struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (p *fieldPredicate) match(entry *logrus.Entry) bool {
+	v, exist := entry.Data[p.field]
+	if !exist {
+		return false
+	}
+
+	s := fmt.Sprint(v)
+
+	switch p.op {
+	case "equals":
+		return s == p.value
+	case "contains":
+		return strings.Contains(s, p.value)
+	case "matches":
+		return p.re.MatchString(s)
+	}
+
+	return false
+}
+
+// newFieldPredicates parses the `when` section of hook, e.g.
+// when { alert.equals = true, user.contains = "admin", path.matches = "^/api" }
+func newFieldPredicates(hookConf config.Configuration) (predicates []*fieldPredicate, err error) {
+	if !hookConf.IsObject("when") {
+		err = fmt.Errorf("logurs mate: when should be { field.equals|contains|matches = value }")
+		return
+	}
+
+	conf := hookConf.GetConfig("when")
+	for _, field := range conf.Keys() {
+		if !conf.IsObject(field) {
+			err = fmt.Errorf("logurs mate: when of field %s should be { equals|contains|matches = value }", field)
+			return
+		}
+		fieldConf := conf.GetConfig(field)
+
+		for _, op := range fieldConf.Keys() {
+			predicate := &fieldPredicate{field: field, op: op, value: fieldConf.GetString(op)}
+
+			switch op {
+			case "equals", "contains":
+			case "matches":
+				if predicate.re, err = regexp.Compile(predicate.value); err != nil {
+					err = fmt.Errorf("logurs mate: when of field %s: %s", field, err)
+					return
+				}
+			default:
+				err = fmt.Errorf("logurs mate: unknown when operator %s of field %s", op, field)
+				return
+			}
+
+			predicates = append(predicates, predicate)
+		}
+	}
+
+	return
+}
+
+// predicateHook fires the inner hook only when the entry matches all the predicates
+type predicateHook struct {
+	hook       logrus.Hook
+	predicates []*fieldPredicate
+}
+
+func (p *predicateHook) Levels() []logrus.Level {
+	return p.hook.Levels()
+}
+
+func (p *predicateHook) Fire(entry *logrus.Entry) error {
+	for _, predicate := range p.predicates {
+		if !predicate.match(entry) {
+			return nil
+		}
+	}
+
+	return p.hook.Fire(entry)
+}
+
+// Close closes or flushes the inner hook if it could be
+func (p *predicateHook) Close() error {
+	if closeFunc := closeFuncOf(p.hook); closeFunc != nil {
+		return closeFunc()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestPredicateHook(t *testing.T) {
+	logger, _ := hijackString(t, `
+		hooks {
+			test-record {
+				id = "predicate"
+				when { alert.equals = true, user.contains = "admin" }
+			}
+		}`)
+
+	logger.WithFields(logrus.Fields{"alert": true, "user": "sysadmin"}).Error("matched")
+	logger.WithFields(logrus.Fields{"alert": false, "user": "sysadmin"}).Error("alert false")
+	logger.WithFields(logrus.Fields{"alert": true, "user": "bob"}).Error("no admin")
+	logger.WithField("user", "admin").Error("no alert")
+	logger.Error("no fields")
+
+	entries := recordedBy(t, "predicate").Entries()
+	if len(entries) != 1 || entries[0].Message != "matched" {
+		t.Fatalf("only the entry matching all the predicates should fire: %v", entries)
+	}
+}
+
+func TestPredicateHookMatches(t *testing.T) {
+	logger, _ := hijackString(t, `
+		hooks {
+			test-record {
+				id = "predicate-matches"
+				when { path.matches = "^/api/" }
+			}
+		}`)
+
+	logger.WithField("path", "/api/users").Info("api")
+	logger.WithField("path", "/static/api/x").Info("static")
+
+	entries := recordedBy(t, "predicate-matches").Entries()
+	if len(entries) != 1 || entries[0].Message != "api" {
+		t.Fatalf("entries %v", entries)
+	}
+}
+
+func TestPredicateHookInvalid(t *testing.T) {
+	for name, conf := range map[string]string{
+		"unknown operator": `hooks { test-record { when { alert.like = true } } }`,
+		"bad pattern":      `hooks { test-record { when { path.matches = "(" } } }`,
+		"no operator":      `hooks { test-record { when { alert = true } } }`,
+		"scalar when":      `hooks { test-record { when = true } }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Errorf("%s: expected error", name)
+		} else if !strings.Contains(err.Error(), "when") {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
+
+func TestFieldPredicatesScalar(t *testing.T) {
+	for _, conf := range []string{`when { alert = true }`, `when { alert = "yes", user.equals = "bob" }`, `when = "alert"`} {
+		if _, err := newFieldPredicates(configOf(conf)); err == nil || !strings.Contains(err.Error(), "should be") {
+			t.Errorf("%s: %v", conf, err)
+		}
+	}
+
+	predicates, err := newFieldPredicates(configOf(`when { alert.equals = true }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predicates) != 1 || !predicates[0].match(&logrus.Entry{Data: logrus.Fields{"alert": true}}) {
+		t.Fatalf("predicates %v", predicates)
+	}
+}
+
+func TestPredicateHookClose(t *testing.T) {
+	inner := &closingHook{closed: make(chan struct{})}
+	hook := &predicateHook{hook: inner}
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-inner.closed:
+	default:
+		t.Fatal("the inner hook is not closed")
+	}
+}
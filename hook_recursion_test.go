@@ -0,0 +1,125 @@
+package logrus_mate
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterHook("test-recursive", func(config.Configuration) (logrus.Hook, error) {
+		return &recursiveHook{}, nil
+	})
+}
+
+// recursiveHook logs every entry it sees into the logger of the entry again
+type recursiveHook struct {
+	fired int32
+}
+
+func (p *recursiveHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *recursiveHook) Fire(entry *logrus.Entry) error {
+	atomic.AddInt32(&p.fired, 1)
+	entry.Logger.Warn("hook saw " + entry.Message)
+	return nil
+}
+
+// safeHookOf returns the guard of the hook registered by name
+func safeHookOf(t *testing.T, logger *logrus.Logger, name string) *safeHook {
+	t.Helper()
+
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if safe, ok := hook.(*safeHook); ok && safe.name == name {
+			return safe
+		}
+	}
+
+	t.Fatalf("no hook %s", name)
+	return nil
+}
+
+func TestHookRecursionGuarded(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+hooks.test-recursive {}
+hooks.test-record.id = "recursion"`)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Info("request")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging from the hook is deadlocked")
+	}
+
+	// the recursive entry is written and fired into the other hooks, not into the hook itself
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "hook saw request") || !strings.Contains(lines[1], "msg=request") {
+		t.Fatalf("lines %q", lines)
+	}
+
+	entries := recordedBy(t, "recursion").Entries()
+	if len(entries) != 2 {
+		t.Fatalf("the other hooks see %v", entries)
+	}
+
+	safe := safeHookOf(t, logger, "test-recursive")
+	if fired := atomic.LoadInt32(&safe.hook.(*recursiveHook).fired); fired != 1 {
+		t.Fatalf("the hook is fired %d times", fired)
+	}
+
+	// the recursion is reported once
+	logger.Info("again")
+	if atomic.LoadInt32(&safe.reentrance) != 1 {
+		t.Fatal("the recursion is not recorded")
+	}
+}
+
+func TestHookRecursionConcurrent(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+hooks.test-recursive {}`)
+
+	const goroutines, entries = 8, 50
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < entries; i++ {
+					logger.Info("entry")
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent logging with the recursive hook is deadlocked")
+	}
+
+	// the firing of other goroutines is not mistaken for the recursion
+	safe := safeHookOf(t, logger, "test-recursive")
+	if fired := atomic.LoadInt32(&safe.hook.(*recursiveHook).fired); fired != goroutines*entries {
+		t.Fatalf("the hook is fired %d times, expected %d", fired, goroutines*entries)
+	}
+}
@@ -0,0 +1,90 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// safeHook recovers panics raised by the wrapped hook's Fire, so one bad hook
+// could not break the logging call and the remaining hooks still fired.
+// In strict mode the panic is re-raised after it was reported.
+//
+// It also guards the reentrance: the entry logged by the hook itself into the
+// same logger while firing, e.g. a hook logging its errors, is not fired into
+// the hook again, or it would recurse until the stack overflows. The other
+// hooks and the output still get the entry, the recursion is reported once.
+//
+// The guard costs an atomic add per Fire, the stack is checked only while the
+// hook is firing already, e.g. by another goroutine. The stack tells a safe hook
+// is firing, not which one, so the entry logged by another hook while firing is
+// not fired into this one either if this one is firing on another goroutine then.
+type safeHook struct {
+	name   string
+	hook   logrus.Hook
+	strict bool
+
+	depth      int32 // the Fire in progress by all goroutines
+	reentrance int32
+}
+
+func newSafeHook(name string, hook logrus.Hook, strict bool) logrus.Hook {
+	return &safeHook{name: name, hook: hook, strict: strict}
+}
+
+func (p *safeHook) Levels() []logrus.Level {
+	return p.hook.Levels()
+}
+
+func (p *safeHook) Fire(entry *logrus.Entry) (err error) {
+	if isDropped(entry) {
+		return
+	}
+
+	depth := atomic.AddInt32(&p.depth, 1)
+	defer atomic.AddInt32(&p.depth, -1)
+
+	if depth > 1 && firingSafeHook() {
+		if atomic.CompareAndSwapInt32(&p.reentrance, 0, 1) {
+			_, _ = fmt.Fprintf(os.Stderr, "logurs mate: hook %s logged into its own logger while firing, the recursive entries are not fired into it\n", p.name)
+		}
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logurs mate: hook %s panic recovered: %v\n", p.name, r)
+			if p.strict {
+				panic(r)
+			}
+			err = nil
+		}
+	}()
+
+	return p.hook.Fire(entry)
+}
+
+// safeHookFire is the function name of Fire of safeHook in the stack frames
+var safeHookFire = reflect.TypeOf(safeHook{}).PkgPath() + ".(*safeHook).Fire"
+
+// firingSafeHook reports whether the goroutine is inside the Fire of a safe hook,
+// besides the Fire calling it
+func firingSafeHook() bool {
+	var pcs [64]uintptr
+	// skip runtime.Callers, firingSafeHook and the Fire calling it
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+
+	for {
+		frame, more := frames.Next()
+		if frame.Function == safeHookFire {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
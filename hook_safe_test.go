@@ -0,0 +1,94 @@
+package logrus_mate
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSafeHookRecoversPanic(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+formatter.name = "text"
+hooks {
+    test-panic {}
+    test-record { id = "safe" }
+}`)
+
+	logger.Info("survived")
+
+	if !strings.Contains(buf.String(), "msg=survived") {
+		t.Fatalf("the output is lost by the panic: %q", buf.String())
+	}
+
+	if entries := recordedBy(t, "safe").Entries(); len(entries) != 1 || entries[0].Message != "survived" {
+		t.Fatalf("the hook after the panicking one did not fire: %v", entries)
+	}
+}
+
+func TestSafeHookStrictRepanics(t *testing.T) {
+	logger, _ := hijackString(t, `
+strict-hooks = true
+hooks.test-panic {}`)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("the panic of hook is not re-raised in strict mode")
+		}
+	}()
+
+	logger.Info("boom")
+}
+
+// nopHook fires nothing, the benchmarks measure the safe hook around it
+type nopHook struct{}
+
+func (nopHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (nopHook) Fire(*logrus.Entry) error {
+	return nil
+}
+
+func benchmarkHook(b *testing.B, hook logrus.Hook, parallel bool) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Formatter = &logrus.JSONFormatter{}
+	logger.Hooks.Add(hook)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	if !parallel {
+		for i := 0; i < b.N; i++ {
+			logger.Info("benchmark")
+		}
+		return
+	}
+
+	// the hook fired by several goroutines at once checks the stack for the recursion
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("benchmark")
+		}
+	})
+}
+
+func BenchmarkHookUnguarded(b *testing.B) {
+	benchmarkHook(b, nopHook{}, false)
+}
+
+func BenchmarkHookSafe(b *testing.B) {
+	benchmarkHook(b, newSafeHook("nop", nopHook{}, false), false)
+}
+
+func BenchmarkHookUnguardedParallel(b *testing.B) {
+	benchmarkHook(b, nopHook{}, true)
+}
+
+func BenchmarkHookSafeParallel(b *testing.B) {
+	benchmarkHook(b, newSafeHook("nop", nopHook{}, false), true)
+}
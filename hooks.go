@@ -1,8 +1,9 @@
 package logrus_mate
 
 import (
-	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gogap/config"
@@ -18,7 +19,7 @@ type NewHookFunc func(config.Configuration) (hook logrus.Hook, err error)
 
 func RegisterHook(name string, newHookFunc NewHookFunc) {
 	hooksLocker.Lock()
-	hooksLocker.Unlock()
+	defer hooksLocker.Unlock()
 
 	if name == "" {
 		panic("logurs mate: Register hook name is empty")
@@ -35,9 +36,21 @@ func RegisterHook(name string, newHookFunc NewHookFunc) {
 	newHookFuncs[name] = newHookFunc
 }
 
+// Hooks returns the names of registered hooks sorted, the same registry NewHook and
+// the hooks of config resolve by, the hooks are registered by importing their packages
 func Hooks() []string {
 	hooksLocker.Lock()
 	defer hooksLocker.Unlock()
+	return hookNames()
+}
+
+// RegisteredHooks is Hooks, for the config UIs and diagnosing the unknown hooks
+func RegisteredHooks() []string {
+	return Hooks()
+}
+
+// hookNames must be called with hooksLocker locked
+func hookNames() []string {
 	var list []string
 	for name := range newHookFuncs {
 		list = append(list, name)
@@ -51,7 +64,8 @@ func NewHook(name string, config config.Configuration) (hook logrus.Hook, err er
 	defer hooksLocker.Unlock()
 
 	if newHookFunc, exist := newHookFuncs[name]; !exist {
-		err = errors.New("logurs mate: hook not registerd: " + name)
+		// the hook package is usually not imported
+		err = fmt.Errorf("logurs mate: hook not registerd: %q, registered: [%s]", name, strings.Join(hookNames(), ", "))
 		return
 	} else {
 		hook, err = newHookFunc(config)
@@ -47,7 +47,7 @@ func NewBearyChatHook(config config.Configuration) (hook logrus.Hook, err error)
 
 	if conf.Levels != nil {
 		for _, level := range conf.Levels {
-			if lv, e := logrus.ParseLevel(level); e != nil {
+			if lv, e := logrus_mate.ParseLevel(level); e != nil {
 				err = e
 				return
 			} else {
@@ -0,0 +1,100 @@
+package buildinfo
+
+import (
+	"sync/atomic"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type BuildInfoHookConfig struct {
+	VersionField   string
+	CommitField    string
+	BuildTimeField string
+}
+
+func init() {
+	logrus_mate.RegisterHook("buildinfo", NewBuildInfoHook)
+}
+
+var allLevels = []logrus.Level{
+	logrus.PanicLevel,
+	logrus.FatalLevel,
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+	logrus.InfoLevel,
+	logrus.DebugLevel,
+	logrus.TraceLevel,
+}
+
+func NewBuildInfoHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := BuildInfoHookConfig{
+		VersionField:   "version",
+		CommitField:    "commit",
+		BuildTimeField: "build_time",
+	}
+
+	if config != nil {
+		conf.VersionField = config.GetString("version-field", conf.VersionField)
+		conf.CommitField = config.GetString("commit-field", conf.CommitField)
+		conf.BuildTimeField = config.GetString("build-time-field", conf.BuildTimeField)
+	}
+
+	hook = &BuildInfoHook{Config: conf}
+
+	return
+}
+
+// BuildInfoHook adds the build metadata of logrus_mate.SetBuildInfo to every entry,
+// the empty values are omitted. The fields are built once for the registered
+// build info and reused, the fields given by WithFields take precedence.
+type BuildInfoHook struct {
+	Config BuildInfoHookConfig
+
+	cached atomic.Value // *buildFields
+}
+
+type buildFields struct {
+	info   logrus_mate.BuildInfo
+	fields [][2]string
+}
+
+func (p *BuildInfoHook) Levels() []logrus.Level {
+	return allLevels
+}
+
+func (p *BuildInfoHook) Fire(entry *logrus.Entry) (err error) {
+	for _, field := range p.fields() {
+		if _, exist := entry.Data[field[0]]; !exist {
+			entry.Data[field[0]] = field[1]
+		}
+	}
+
+	return
+}
+
+// fields returns the cached fields, rebuilt only when SetBuildInfo was called again
+func (p *BuildInfoHook) fields() [][2]string {
+	info := logrus_mate.GetBuildInfo()
+
+	if cached, ok := p.cached.Load().(*buildFields); ok && cached.info == info {
+		return cached.fields
+	}
+
+	cached := &buildFields{info: info}
+	for _, field := range [][2]string{
+		{p.Config.VersionField, info.Version},
+		{p.Config.CommitField, info.Commit},
+		{p.Config.BuildTimeField, info.BuildTime},
+	} {
+		if len(field[0]) > 0 && len(field[1]) > 0 {
+			cached.fields = append(cached.fields, field)
+		}
+	}
+
+	p.cached.Store(cached)
+
+	return cached.fields
+}
@@ -0,0 +1,103 @@
+package buildinfo
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+func newTestHook(t *testing.T, conf string) *BuildInfoHook {
+	t.Helper()
+
+	hook, err := NewBuildInfoHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook.(*BuildInfoHook)
+}
+
+func fire(hook *BuildInfoHook, fields logrus.Fields) logrus.Fields {
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	_ = hook.Fire(entry)
+	return entry.Data
+}
+
+func TestBuildInfoFields(t *testing.T) {
+	logrus_mate.SetBuildInfo("1.2.0", "9d90bac", "2024-01-01T10:00:00Z")
+
+	data := fire(newTestHook(t, ``), logrus.Fields{"user": "bob"})
+	for key, expected := range map[string]string{
+		"version":    "1.2.0",
+		"commit":     "9d90bac",
+		"build_time": "2024-01-01T10:00:00Z",
+		"user":       "bob",
+	} {
+		if data[key] != expected {
+			t.Fatalf("field %s is %v, expected %s, data %v", key, data[key], expected, data)
+		}
+	}
+}
+
+func TestBuildInfoConfiguredFields(t *testing.T) {
+	logrus_mate.SetBuildInfo("1.2.0", "9d90bac", "")
+
+	hook := newTestHook(t, `version-field = "app_version", commit-field = "git_sha", build-time-field = "built"`)
+
+	// the empty build time is omitted, the given version takes precedence
+	data := fire(hook, logrus.Fields{"app_version": "override"})
+	if len(data) != 2 || data["app_version"] != "override" || data["git_sha"] != "9d90bac" {
+		t.Fatalf("data %v", data)
+	}
+	if _, exist := data["built"]; exist {
+		t.Fatalf("the empty build time is attached: %v", data)
+	}
+}
+
+func TestBuildInfoCached(t *testing.T) {
+	logrus_mate.SetBuildInfo("1.2.0", "9d90bac", "")
+
+	hook := newTestHook(t, ``)
+	first := hook.fields()
+	if second := hook.fields(); &first[0] != &second[0] {
+		t.Fatal("the fields are rebuilt for the same build info")
+	}
+
+	// rebuilt after the build info is registered again
+	logrus_mate.SetBuildInfo("1.3.0", "a604f45", "")
+	if data := fire(hook, nil); data["version"] != "1.3.0" || data["commit"] != "a604f45" {
+		t.Fatalf("data after SetBuildInfo %v", data)
+	}
+}
+
+func TestBuildInfoByConfig(t *testing.T) {
+	logrus_mate.SetBuildInfo("1.2.0", "9d90bac", "")
+
+	logger := logrus.New()
+	if err := logrus_mate.Hijack(logger, logrus_mate.ConfigString(`hooks.buildinfo.commit-field = "sha"`)); err != nil {
+		t.Fatal(err)
+	}
+	logger.Out = ioutil.Discard
+
+	var data logrus.Fields
+	logger.AddHook(fieldsHook(func(fields logrus.Fields) { data = fields }))
+	logger.Info("started")
+
+	if data["version"] != "1.2.0" || data["sha"] != "9d90bac" {
+		t.Fatalf("data %v", data)
+	}
+}
+
+type fieldsHook func(logrus.Fields)
+
+func (fieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p fieldsHook) Fire(entry *logrus.Entry) error {
+	p(entry.Data)
+	return nil
+}
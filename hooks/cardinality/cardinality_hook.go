@@ -0,0 +1,172 @@
+package cardinality
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type FieldCardinality struct {
+	// distinct values kept of the field, the further are bucketed
+	Max int
+	// the value not seen for ResetAfter is forgotten, 0 keeps it forever
+	ResetAfter time.Duration
+}
+
+type CardinalityHookConfig struct {
+	Fields      map[string]FieldCardinality
+	Placeholder string
+}
+
+func init() {
+	logrus_mate.RegisterHook("cardinality", NewCardinalityHook)
+}
+
+var allLevels = []logrus.Level{
+	logrus.PanicLevel,
+	logrus.FatalLevel,
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+	logrus.InfoLevel,
+	logrus.DebugLevel,
+	logrus.TraceLevel,
+}
+
+// NewCardinalityHook creates the hook from config like:
+// fields { url = 1000, user_agent { max = 200, reset-after = 1h } }
+func NewCardinalityHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := CardinalityHookConfig{
+		Fields:      make(map[string]FieldCardinality),
+		Placeholder: "<high-cardinality>",
+	}
+
+	if config != nil {
+		conf.Placeholder = config.GetString("placeholder", conf.Placeholder)
+
+		if fieldsConf := config.GetConfig("fields"); fieldsConf != nil {
+			for _, field := range fieldsConf.Keys() {
+				var c FieldCardinality
+				if fieldsConf.IsObject(field) {
+					fieldConf := fieldsConf.GetConfig(field)
+					c.Max = int(fieldConf.GetInt32("max"))
+					c.ResetAfter = fieldConf.GetTimeDuration("reset-after", 0)
+				} else {
+					c.Max = int(fieldsConf.GetInt32(field))
+				}
+
+				if c.Max <= 0 {
+					err = fmt.Errorf("logurs mate: cardinality max of field %s should be positive: %d", field, c.Max)
+					return
+				}
+
+				conf.Fields[field] = c
+			}
+		}
+	}
+
+	h := &CardinalityHook{Config: conf, fields: make(map[string]*valueLRU), now: time.Now}
+	for field, c := range conf.Fields {
+		h.fields[field] = newValueLRU(c)
+	}
+
+	hook = h
+
+	return
+}
+
+// CardinalityHook keeps at most Max distinct values of each configured field,
+// the distinct values beyond are replaced by the placeholder. The kept values
+// are a bounded LRU, with ResetAfter the idle ones are evicted to make room.
+type CardinalityHook struct {
+	Config CardinalityHookConfig
+
+	locker sync.Mutex
+	fields map[string]*valueLRU
+	now    func() time.Time
+}
+
+func (p *CardinalityHook) Levels() []logrus.Level {
+	return allLevels
+}
+
+func (p *CardinalityHook) Fire(entry *logrus.Entry) (err error) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	now := p.now()
+
+	for field, lru := range p.fields {
+		v, exist := entry.Data[field]
+		if !exist || v == nil {
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			s = fmt.Sprint(v)
+		}
+
+		if !lru.admit(s, now) {
+			entry.Data[field] = p.Config.Placeholder
+		}
+	}
+
+	return
+}
+
+type lruValue struct {
+	value    string
+	lastSeen time.Time
+}
+
+// valueLRU holds the admitted values, the front is the latest seen
+type valueLRU struct {
+	max        int
+	resetAfter time.Duration
+	order      *list.List
+	values     map[string]*list.Element
+}
+
+func newValueLRU(c FieldCardinality) *valueLRU {
+	return &valueLRU{
+		max:        c.Max,
+		resetAfter: c.ResetAfter,
+		order:      list.New(),
+		values:     make(map[string]*list.Element),
+	}
+}
+
+// admit reports whether the value is kept, the seen value is always kept and
+// the new one only while there is room
+func (p *valueLRU) admit(value string, now time.Time) bool {
+	if elem, exist := p.values[value]; exist {
+		elem.Value.(*lruValue).lastSeen = now
+		p.order.MoveToFront(elem)
+		return true
+	}
+
+	if p.resetAfter > 0 {
+		for back := p.order.Back(); back != nil; back = p.order.Back() {
+			v := back.Value.(*lruValue)
+			if now.Sub(v.lastSeen) < p.resetAfter {
+				break
+			}
+			p.order.Remove(back)
+			delete(p.values, v.value)
+		}
+	}
+
+	if p.order.Len() >= p.max {
+		return false
+	}
+
+	p.values[value] = p.order.PushFront(&lruValue{value: value, lastSeen: now})
+
+	return true
+}
@@ -0,0 +1,101 @@
+package cardinality
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestHook(t *testing.T, conf string) *CardinalityHook {
+	t.Helper()
+
+	hook, err := NewCardinalityHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook.(*CardinalityHook)
+}
+
+func fire(hook *CardinalityHook, fields logrus.Fields) logrus.Fields {
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	_ = hook.Fire(entry)
+	return entry.Data
+}
+
+func TestCardinalityBucketsBeyondCap(t *testing.T) {
+	hook := newTestHook(t, `fields { url = 3 }`)
+
+	for i := 0; i < 3; i++ {
+		url := fmt.Sprintf("/users/%d", i)
+		if data := fire(hook, logrus.Fields{"url": url}); data["url"] != url {
+			t.Fatalf("value %d under the cap is bucketed: %v", i, data["url"])
+		}
+	}
+
+	// the new distinct values are bucketed after the cap, the seen ones are kept
+	for i := 3; i < 10; i++ {
+		if data := fire(hook, logrus.Fields{"url": fmt.Sprintf("/users/%d", i)}); data["url"] != "<high-cardinality>" {
+			t.Fatalf("value %d beyond the cap: %v", i, data["url"])
+		}
+	}
+	if data := fire(hook, logrus.Fields{"url": "/users/1"}); data["url"] != "/users/1" {
+		t.Fatalf("the seen value is bucketed: %v", data["url"])
+	}
+
+	// the unconfigured fields are untouched
+	if data := fire(hook, logrus.Fields{"path": "/users/99"}); data["path"] != "/users/99" {
+		t.Fatalf("the unconfigured field: %v", data["path"])
+	}
+}
+
+func TestCardinalityNonStringValues(t *testing.T) {
+	hook := newTestHook(t, `placeholder = "<other>", fields { code = 2 }`)
+
+	fire(hook, logrus.Fields{"code": 200})
+	fire(hook, logrus.Fields{"code": 404})
+
+	if data := fire(hook, logrus.Fields{"code": 500}); data["code"] != "<other>" {
+		t.Fatalf("the third code %v", data["code"])
+	}
+	if data := fire(hook, logrus.Fields{"code": 200}); data["code"] != 200 {
+		t.Fatalf("the seen code %v", data["code"])
+	}
+}
+
+func TestCardinalityResetAfter(t *testing.T) {
+	hook := newTestHook(t, `fields { agent { max = 2, reset-after = 1h } }`)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	fire(hook, logrus.Fields{"agent": "a"})
+	now = now.Add(30 * time.Minute)
+	fire(hook, logrus.Fields{"agent": "b"})
+
+	if data := fire(hook, logrus.Fields{"agent": "c"}); data["agent"] != "<high-cardinality>" {
+		t.Fatalf("c is admitted into the full lru: %v", data["agent"])
+	}
+
+	// a is idle for an hour and evicted to make room, b is still kept
+	now = now.Add(30 * time.Minute)
+	if data := fire(hook, logrus.Fields{"agent": "c"}); data["agent"] != "c" {
+		t.Fatalf("c after a is idle: %v", data["agent"])
+	}
+	if data := fire(hook, logrus.Fields{"agent": "b"}); data["agent"] != "b" {
+		t.Fatalf("b is evicted: %v", data["agent"])
+	}
+	if data := fire(hook, logrus.Fields{"agent": "a"}); data["agent"] != "<high-cardinality>" {
+		t.Fatalf("the evicted a is admitted again into the full lru: %v", data["agent"])
+	}
+}
+
+func TestCardinalityInvalidMax(t *testing.T) {
+	for _, conf := range []string{`fields { url = 0 }`, `fields { url { max = -1 } }`} {
+		if _, err := NewCardinalityHook(config.NewConfig(config.ConfigString(conf))); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+	"github.com/gogap/logrus_mate/hooks/utils/dispatcher"
+)
+
+// Event is the structured copy of a log entry
+type Event struct {
+	Level   logrus.Level
+	Message string
+	Fields  logrus.Fields
+	Time    time.Time
+}
+
+type Publisher func(Event)
+
+var (
+	publishersLocker = sync.Mutex{}
+	publishers       = make(map[string]Publisher)
+)
+
+// RegisterPublisher registers the callback which could be referenced by
+// the publisher option of event hook config
+func RegisterPublisher(name string, publisher Publisher) {
+	publishersLocker.Lock()
+	defer publishersLocker.Unlock()
+
+	if name == "" {
+		panic("logurs mate: Register event publisher name is empty")
+	}
+
+	if publisher == nil {
+		panic("logurs mate: Register event publisher is nil")
+	}
+
+	publishers[name] = publisher
+}
+
+func init() {
+	logrus_mate.RegisterHook("event", NewEventHookByConfig)
+}
+
+func NewEventHookByConfig(config config.Configuration) (hook logrus.Hook, err error) {
+	name := ""
+	level := "error"
+	bufferSize := 1000
+
+	if config != nil {
+		name = config.GetString("publisher")
+		level = config.GetString("level", level)
+		bufferSize = int(config.GetInt32("buffer-size", int32(bufferSize)))
+	}
+
+	publishersLocker.Lock()
+	publisher, exist := publishers[name]
+	publishersLocker.Unlock()
+
+	if !exist {
+		err = fmt.Errorf("logurs mate: event publisher not registered: %s", name)
+		return
+	}
+
+	lvl, err := logrus_mate.ParseLevel(level)
+	if err != nil {
+		return
+	}
+
+	hook = NewEventHook(publisher, lvl, bufferSize)
+
+	return
+}
+
+// NewEventHook publishes the entries at level or more severe, publishing never
+// blocks the logging, the events are dropped while the buffer is full
+func NewEventHook(publisher Publisher, level logrus.Level, bufferSize int) *EventHook {
+	hook := &EventHook{
+		level:     level,
+		publisher: publisher,
+		events:    make(chan Event, bufferSize),
+	}
+
+	go hook.dispatch()
+
+	return hook
+}
+
+type EventHook struct {
+	level     logrus.Level
+	publisher Publisher
+	events    chan Event
+	dropped   uint64
+
+	publishStats dispatcher.FlushStats
+}
+
+func (p *EventHook) Levels() []logrus.Level {
+	var levels []logrus.Level
+	for _, lvl := range logrus.AllLevels {
+		if lvl <= p.level {
+			levels = append(levels, lvl)
+		}
+	}
+	return levels
+}
+
+func (p *EventHook) Fire(entry *logrus.Entry) (err error) {
+	fields := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+
+	e := Event{
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  fields,
+		Time:    entry.Time,
+	}
+
+	select {
+	case p.events <- e:
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+	}
+
+	return
+}
+
+// Dropped returns the count of events dropped by full buffer
+func (p *EventHook) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Stats returns the buffered events, drops and the latency of publisher
+func (p *EventHook) Stats() (stats dispatcher.Stats) {
+	stats = dispatcher.Stats{
+		QueueDepth: len(p.events),
+		Capacity:   cap(p.events),
+		Dropped:    p.Dropped(),
+	}
+	p.publishStats.Fill(&stats)
+	return
+}
+
+func (p *EventHook) dispatch() {
+	for e := range p.events {
+		start := time.Now()
+		p.publisher(e)
+		p.publishStats.Observe(time.Since(start))
+	}
+}
@@ -0,0 +1,108 @@
+package event
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestEventHookPublishes(t *testing.T) {
+	events := make(chan Event, 10)
+	RegisterPublisher("test-events", func(e Event) { events <- e })
+
+	hook, err := NewEventHookByConfig(config.NewConfig(config.ConfigString(`publisher = "test-events", level = "warn"`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.AddHook(hook)
+
+	logger.WithField("user", "bob").Error("failed")
+	logger.Info("ignored")
+	logger.Warn("slow")
+
+	for _, expected := range []Event{
+		{Level: logrus.ErrorLevel, Message: "failed", Fields: logrus.Fields{"user": "bob"}},
+		{Level: logrus.WarnLevel, Message: "slow", Fields: logrus.Fields{}},
+	} {
+		select {
+		case e := <-events:
+			if e.Level != expected.Level || e.Message != expected.Message || len(e.Fields) != len(expected.Fields) ||
+				e.Fields["user"] != expected.Fields["user"] || e.Time.IsZero() {
+				t.Fatalf("event %+v, expected %+v", e, expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%q is not published", expected.Message)
+		}
+	}
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventHookNonBlocking(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	hook := NewEventHook(func(Event) { <-release }, logrus.InfoLevel, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			_ = hook.Fire(logrus.NewEntry(logrus.New()))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fire is blocked by the publisher")
+	}
+
+	// one in publisher, one in buffer at most
+	if dropped := hook.Dropped(); dropped < 8 {
+		t.Fatalf("%d dropped", dropped)
+	}
+}
+
+func TestEventHookUnknownPublisher(t *testing.T) {
+	if _, err := NewEventHookByConfig(config.NewConfig(config.ConfigString(`publisher = "missing"`))); err == nil {
+		t.Fatal("the unknown publisher is accepted")
+	}
+}
+
+func TestEventHookStats(t *testing.T) {
+	release := make(chan struct{})
+	published := make(chan struct{}, 1)
+
+	hook := NewEventHook(func(Event) {
+		select {
+		case published <- struct{}{}:
+		default:
+		}
+		<-release
+	}, logrus.InfoLevel, 2)
+
+	_ = hook.Fire(logrus.NewEntry(logrus.New()))
+	<-published
+
+	for i := 0; i < 6; i++ {
+		_ = hook.Fire(logrus.NewEntry(logrus.New()))
+	}
+
+	// one in publisher, two in buffer, four dropped
+	if stats := hook.Stats(); stats.QueueDepth != 2 || stats.Capacity != 2 || stats.Dropped != 4 {
+		t.Fatalf("stats of the full buffer %+v", stats)
+	}
+
+	close(release)
+}
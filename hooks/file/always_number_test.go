@@ -0,0 +1,42 @@
+package logrus_file
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAlwaysNumber(t *testing.T) {
+	for _, c := range []struct {
+		alwaysNumber bool
+		once         []string
+		twice        []string
+	}{
+		// the first is named without the number, then renamed to .001 by the second
+		{
+			false,
+			[]string{"logs/app.2024-01-01.log", "logs/app.log"},
+			[]string{"logs/app.2024-01-01.001.log", "logs/app.2024-01-01.002.log", "logs/app.log"},
+		},
+		{
+			true,
+			[]string{"logs/app.2024-01-01.001.log", "logs/app.log"},
+			[]string{"logs/app.2024-01-01.001.log", "logs/app.2024-01-01.002.log", "logs/app.log"},
+		},
+	} {
+		fs := newMemFS()
+		clock := newFakeClock(day1)
+		w := newMemWriter(t, fs, clock, fmt.Sprintf(`{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0,"always_number":%v}`, c.alwaysNumber))
+
+		writeLines(t, w, "1", "2", "3")
+		assertNames(t, fs, c.once...)
+		if s := readMem(t, fs, c.once[0]); s != "1\n2\n" {
+			t.Fatalf("always_number %v: %s is %q", c.alwaysNumber, c.once[0], s)
+		}
+
+		writeLines(t, w, "4", "5")
+		assertNames(t, fs, c.twice...)
+		if s := readMem(t, fs, "logs/app.2024-01-01.002.log"); s != "3\n4\n" {
+			t.Fatalf("always_number %v: the second is %q", c.alwaysNumber, s)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package logrus_file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// chainSuffix is appended to the log filename for the sidecar keeping the last
+// hash of the chain, it is never rotated, so the chain continues after restart
+const chainSuffix = ".chain"
+
+// the sidecar is rewritten at most once per interval by the records, the rotate,
+// flush and close save it at once
+const chainSaveInterval = time.Second
+
+// prevHashKey is the field of every record carrying the hash of the previous record
+const prevHashKey = "prev_hash"
+
+func newChainHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("invalid audit_hash %q: expected sha256 or sha512", algorithm)
+}
+
+// loadChain restores the last hash of the chain by the last record of the file,
+// the sidecar saved by chainSaveInterval is stale after a crash. The file empty,
+// e.g. just rotated, continues from the sidecar, which the rotate saved at once.
+// The chain of a new file starts from the empty hash.
+func (w *fileLogWriter) loadChain() error {
+	last, err := w.lastRecord()
+	if err != nil {
+		return fmt.Errorf("read last record err: %s", err)
+	}
+	if len(last) > 0 {
+		h := w.chainNew()
+		h.Write(last)
+		w.chainHash = hex.EncodeToString(h.Sum(nil))
+		return nil
+	}
+
+	fd, err := w.fs.Open(w.Filename + chainSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("open chain err: %s", err)
+	}
+	defer fd.Close()
+
+	data, err := ioutil.ReadAll(fd)
+	if err != nil {
+		return fmt.Errorf("read chain err: %s", err)
+	}
+
+	w.chainHash = string(bytes.TrimSpace(data))
+	w.chainSaved = w.chainHash
+
+	return nil
+}
+
+// lastRecord returns the last chained record of the file without the terminator,
+// the record is ended by the line ending with its prev_hash, e.g. the last line of
+// a block. The markers between the records and the partial record of a crash are skipped.
+func (w *fileLogWriter) lastRecord() ([]byte, error) {
+	if w.Fd > 0 {
+		return nil, nil
+	}
+
+	fd, err := w.fs.Open(w.activeFilename())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	marker := []byte(`{"` + markerKey + `"`)
+
+	var last, record, pending []byte
+	buf := make([]byte, 32768) // 32k
+
+	for {
+		c, err := fd.Read(buf)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		pending = append(pending, buf[:c]...)
+
+		// the complete lines are taken, the bytes after them wait for the next read
+		rest := pending
+		for i := bytes.Index(rest, w.terminator); i >= 0; i = bytes.Index(rest, w.terminator) {
+			line := rest[:i]
+			rest = rest[i+len(w.terminator):]
+
+			if len(record) == 0 && bytes.HasPrefix(line, marker) {
+				continue
+			}
+
+			if len(record) > 0 {
+				record = append(record, w.terminator...)
+			}
+			record = append(record, line...)
+
+			if w.endsRecord(line) {
+				last = append(last[:0], record...)
+				record = record[:0]
+			}
+		}
+		pending = pending[:copy(pending, rest)]
+
+		if err == io.EOF {
+			return last, nil
+		}
+	}
+}
+
+// endsRecord reports whether the line ends by prev_hash as chained, the json
+// `"prev_hash":"<hex>"}` or the text ` prev_hash=<hex>`, the hex of the first record is empty
+func (w *fileLogWriter) endsRecord(line []byte) bool {
+	jsonKey, textKey := []byte(`"`+prevHashKey+`":"`), []byte(" "+prevHashKey+"=")
+
+	for _, size := range []int{0, w.chainNew().Size() * 2} {
+		if n := len(line) - size - 2; n >= 0 && bytes.HasSuffix(line, []byte(`"}`)) &&
+			isHex(line[n:n+size]) && bytes.HasSuffix(line[:n], jsonKey) {
+			return true
+		}
+		if n := len(line) - size; n >= 0 && isHex(line[n:]) && bytes.HasSuffix(line[:n], textKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHex(b []byte) bool {
+	for _, c := range b {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// chain adds the prev_hash field before the terminator of msg, a json object gets
+// it as its last key, other lines get ` prev_hash=<hex>`. It returns the hash of the
+// record chained, which is the prev_hash of the next. It must be called with w locked.
+func (w *fileLogWriter) chain(msg []byte) ([]byte, string) {
+	body := bytes.TrimSuffix(msg, w.terminator)
+
+	chained := make([]byte, 0, len(msg)+len(prevHashKey)+len(w.chainHash)+8)
+	if bytes.HasPrefix(body, []byte{'{'}) && bytes.HasSuffix(body, []byte{'}'}) {
+		chained = append(chained, body[:len(body)-1]...)
+		if len(bytes.TrimSpace(body[1:len(body)-1])) > 0 {
+			chained = append(chained, ',')
+		}
+		chained = append(chained, '"')
+		chained = append(chained, prevHashKey...)
+		chained = append(chained, `":`...)
+		chained = strconv.AppendQuote(chained, w.chainHash)
+		chained = append(chained, '}')
+	} else {
+		chained = append(chained, body...)
+		chained = append(chained, ' ')
+		chained = append(chained, prevHashKey...)
+		chained = append(chained, '=')
+		chained = append(chained, w.chainHash...)
+	}
+
+	h := w.chainNew()
+	h.Write(chained)
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	return append(chained, w.terminator...), sum
+}
+
+// chainAdvance moves the chain to the hash of the record written, the sidecar is
+// saved by chainSaveInterval instead of per record. It must be called with w locked.
+func (w *fileLogWriter) chainAdvance(sum string) {
+	w.chainHash = sum
+
+	if w.now().Sub(w.chainSavedAt) >= chainSaveInterval {
+		w.saveChain()
+	}
+}
+
+// saveChain writes the last hash into the sidecar when it moved since saved. It
+// must be called with w locked.
+func (w *fileLogWriter) saveChain() {
+	if !w.AuditChain || w.Fd > 0 || w.chainHash == w.chainSaved {
+		return
+	}
+	w.chainSavedAt = w.now()
+
+	perm, err := parsePerm("perm", w.Perm)
+	if err != nil {
+		return
+	}
+
+	fd, err := w.fs.OpenFile(w.Filename+chainSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm&0600)
+	if err == nil {
+		_, err = fd.Write([]byte(w.chainHash + "\n"))
+		_ = fd.Close()
+	}
+	if err == nil {
+		w.chainSaved = w.chainHash
+	} else {
+		w.diag.printf("chain:"+err.Error(), "%d %v chain FileLogWriter(%q): %s", GoId(), w.now(), w.Filename, err)
+	}
+}
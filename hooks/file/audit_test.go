@@ -0,0 +1,181 @@
+package logrus_file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// assertChain checks every record carries the hash of the previous as prev_hash,
+// from prev the hash before the first, and returns the hash of the last
+func assertChain(t *testing.T, prev string, records ...string) string {
+	t.Helper()
+
+	for _, record := range records {
+		i := strings.LastIndex(record, " "+prevHashKey+"=")
+		if i < 0 {
+			t.Fatalf("record %q has no %s", record, prevHashKey)
+		}
+		if got := record[i+len(prevHashKey)+2:]; got != prev {
+			t.Fatalf("record %q chains %q, expected %q", record, got, prev)
+		}
+
+		sum := sha256.Sum256([]byte(record))
+		prev = hex.EncodeToString(sum[:])
+	}
+
+	return prev
+}
+
+func recordsOf(t *testing.T, fs *memFS, name string) []string {
+	t.Helper()
+	return strings.Split(strings.TrimSuffix(readMem(t, fs, name), "\n"), "\n")
+}
+
+func TestAuditChainAcrossRotation(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":true,"hourly":false,"audit_chain":true}`)
+
+	writeLines(t, w, "a", "b")
+	clock.Add(24 * time.Hour)
+	writeLines(t, w, "c")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log", "logs/app.log.chain")
+
+	// the new file continues from the last record of the rotated
+	last := assertChain(t, "", recordsOf(t, fs, "logs/app.2024-01-01.log")...)
+	if s := readMem(t, fs, "logs/app.log.chain"); s != last+"\n" {
+		t.Fatalf("chain saved by rotate %q, expected %q", s, last)
+	}
+	last = assertChain(t, last, recordsOf(t, fs, "logs/app.log")...)
+
+	w.Flush()
+	if s := readMem(t, fs, "logs/app.log.chain"); s != last+"\n" {
+		t.Fatalf("chain saved by flush %q, expected %q", s, last)
+	}
+}
+
+func TestAuditChainSavedByInterval(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"audit_chain":true}`)
+
+	// the first record saves at once, the next within the interval are kept in memory
+	writeLines(t, w, "a", "b", "c")
+	first := assertChain(t, "", recordsOf(t, fs, "logs/app.log")[0])
+	if s := readMem(t, fs, "logs/app.log.chain"); s != first+"\n" {
+		t.Fatalf("chain %q, expected the first %q", s, first)
+	}
+
+	clock.Add(chainSaveInterval)
+	writeLines(t, w, "d")
+	last := assertChain(t, "", recordsOf(t, fs, "logs/app.log")...)
+	if s := readMem(t, fs, "logs/app.log.chain"); s != last+"\n" {
+		t.Fatalf("chain %q, expected the last %q", s, last)
+	}
+}
+
+func TestAuditChainAcrossRestart(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	config := `{"filename":"logs/app.log","rotate":false,"audit_chain":true}`
+
+	w := newMemWriter(t, fs, clock, config)
+	writeLines(t, w, "a", "b")
+	w.Destroy()
+
+	w = newMemWriter(t, fs, clock, config)
+	writeLines(t, w, "c")
+
+	assertChain(t, "", recordsOf(t, fs, "logs/app.log")...)
+}
+
+func TestAuditChainRestartWithoutFlush(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	config := `{"filename":"logs/app.log","rotate":false,"audit_chain":true}`
+
+	// killed within the interval, the sidecar keeps the hash of the first record only
+	w := newMemWriter(t, fs, clock, config)
+	writeLines(t, w, "a", "b")
+	if err := w.WriteBlock(clock.Now(), "dump", []string{"x", "y"}); err != nil {
+		t.Fatal(err)
+	}
+	first := assertChain(t, "", recordsOf(t, fs, "logs/app.log")[0])
+	if s := readMem(t, fs, "logs/app.log.chain"); s != first+"\n" {
+		t.Fatalf("chain %q, expected the stale %q", s, first)
+	}
+
+	// the restart continues from the last record of the file, the block
+	w = newMemWriter(t, fs, clock, config)
+	writeLines(t, w, "c")
+
+	records := recordsOf(t, fs, "logs/app.log")
+	if len(records) != 6 {
+		t.Fatalf("records %q", records)
+	}
+	last := assertChain(t, "", records[:2]...)
+	last = assertChain(t, last, strings.Join(records[2:5], "\n"))
+	assertChain(t, last, records[5])
+}
+
+func TestAuditLastRecord(t *testing.T) {
+	h1, h2 := strings.Repeat("1", 64), strings.Repeat("2", 64)
+
+	for content, expected := range map[string]string{
+		"":                 "",
+		"a prev_hash=\r\n": "a prev_hash=",
+		"a prev_hash=" + h1 + "\r\nb prev_hash=" + h2 + "\r\n": "b prev_hash=" + h2,
+		// the block ends by the line of prev_hash
+		"a prev_hash=\r\nhead\r\n\tx\r\n\ty prev_hash=" + h1 + "\r\n": "head\r\n\tx\r\n\ty prev_hash=" + h1,
+		`{"msg":"a","prev_hash":"` + h1 + `"}` + "\r\n":               `{"msg":"a","prev_hash":"` + h1 + `"}`,
+		// the markers and the partial record of a crash are skipped
+		"a prev_hash=\r\n{\"_marker\":\"flush\"}\r\nb prev_hash=" + h1 + "\r\n{\"_marker\":\"flush\"}\r\nhead\r\n\tpart": "b prev_hash=" + h1,
+		"a prev_hash=" + h1[1:] + "\r\n": "",
+		"no chain\r\n":                   "",
+	} {
+		fs := newMemFS()
+		f, _ := fs.OpenFile("logs/app.log", os.O_WRONLY|os.O_CREATE, 0640)
+		_, _ = f.Write([]byte(content))
+		_ = f.Close()
+
+		w := newDefaultWriter()
+		w.fs = fs
+		w.Filename = "logs/app.log"
+		w.terminator = []byte("\r\n")
+		w.chainNew = sha256.New
+
+		last, err := w.lastRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(last) != expected {
+			t.Errorf("last record of %q is %q, expected %q", content, last, expected)
+		}
+	}
+}
+
+func TestAuditChainJSON(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"audit_chain":true,"audit_hash":"sha512"}`)
+
+	writeLines(t, w, `{"msg":"a"}`, `{}`)
+
+	records := recordsOf(t, fs, "logs/app.log")
+	if records[0] != `{"msg":"a","prev_hash":""}` || !strings.HasPrefix(records[1], `{"prev_hash":"`) || len(records[1]) != len(`{"prev_hash":""}`)+128 {
+		t.Fatalf("records %q", records)
+	}
+}
+
+func TestAuditChainInvalidHash(t *testing.T) {
+	w := newDefaultWriter()
+	w.fs = newMemFS()
+	if err := w.Init(`{"filename":"logs/app.log","audit_chain":true,"audit_hash":"md5"}`); err == nil {
+		t.Fatal("md5 is accepted")
+	}
+}
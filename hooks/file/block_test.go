@@ -0,0 +1,91 @@
+package logrus_file
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteBlockCountsAsOne(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":3,"maxsize":0,"count_blocks_as_one":true}`)
+
+	if err := w.WriteBlock(w.now(), "dump", []string{"a", "b\n"}); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "x", "y", "z")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "dump\n\ta\n\tb\nx\ny\n" {
+		t.Fatalf("rotated %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "z\n" {
+		t.Fatalf("current %q", s)
+	}
+}
+
+func TestWriteBlockCountsLines(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":3,"maxsize":0}`)
+
+	if err := w.WriteBlock(w.now(), "dump", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "x", "y")
+
+	// the block of 3 lines reaches maxlines, it is never split by rotate
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "dump\n\ta\n\tb\n" {
+		t.Fatalf("rotated %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "x\ny\n" {
+		t.Fatalf("current %q", s)
+	}
+}
+
+func TestWriteBlockNotInterleaved(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false}`)
+
+	const goroutines, blocks, lines = 8, 50, 5
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < blocks; i++ {
+				detail := make([]string, lines)
+				for k := range detail {
+					detail[k] = fmt.Sprintf("%d-%d-%d", g, i, k)
+				}
+				if err := w.WriteBlock(w.now(), fmt.Sprintf("block %d-%d", g, i), detail); err != nil {
+					t.Error(err)
+				}
+				_ = w.WriteMsg(w.now(), "single\n")
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	all := strings.Split(strings.TrimSuffix(readMem(t, fs, "logs/app.log"), "\n"), "\n")
+	if len(all) != goroutines*blocks*(lines+2) {
+		t.Fatalf("%d lines", len(all))
+	}
+
+	for i := 0; i < len(all); i++ {
+		if !strings.HasPrefix(all[i], "block ") {
+			continue
+		}
+		id := strings.TrimPrefix(all[i], "block ")
+		for k := 0; k < lines; k++ {
+			if expected := fmt.Sprintf("\t%s-%d", id, k); all[i+1+k] != expected {
+				t.Fatalf("line %d of block %s is %q, interleaved", k, id, all[i+1+k])
+			}
+		}
+	}
+}
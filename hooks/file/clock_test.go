@@ -0,0 +1,67 @@
+package logrus_file
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestFormatTimeHeaderFrozen(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 10, 4, 5, 123456789, time.UTC)
+
+	first, d, h := formatTimeHeader(frozen)
+	if string(first) != "2024/01/01 10:04:05.123 " || d != 1 || h != 10 {
+		t.Fatalf("header %q day %d hour %d", first, d, h)
+	}
+
+	for i := 0; i < 3; i++ {
+		if header, _, _ := formatTimeHeader(frozen); string(header) != string(first) {
+			t.Fatalf("header %q, the first %q", header, first)
+		}
+	}
+}
+
+func TestSetClockFreezesTheFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	hook, err := NewFileHook(config.NewConfig(config.ConfigString(`filename = "` + filename + `", level = 6, daily = true, hourly = false, rotate = true`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileHook := hook.(*FileHook)
+	defer fileHook.W.Destroy()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local))
+	fileHook.SetClock(clock.Now)
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Formatter = &logrus.TextFormatter{DisableColors: true}
+	logger.AddHook(hook)
+
+	logger.Info("day one")
+	clock.Add(24 * time.Hour)
+	logger.Info("day two")
+
+	// the entry time is written by the logrus formatter, the rotate reads the frozen clock only
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	sort.Strings(names)
+	if len(names) != 2 || filepath.Base(names[0]) != "app.2024-01-01.log" || names[1] != filename {
+		t.Fatalf("files %v", names)
+	}
+
+	data, err := ioutil.ReadFile(names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); !strings.Contains(s, "day one") || strings.Contains(s, "day two") {
+		t.Fatalf("rotated %q", s)
+	}
+}
@@ -0,0 +1,158 @@
+package logrus_file
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/gogap/logrus_mate"
+)
+
+// fileHook creates the file hook of the options, the file is in a temp dir
+func fileHook(t *testing.T, options string) *FileHook {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	hook, err := NewFileHook(config.NewConfig(config.ConfigString(`filename = "` + filepath.ToSlash(filename) + `", ` + options)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hook == nil {
+		t.Fatalf("no file hook of %s", options)
+	}
+	return hook.(*FileHook)
+}
+
+// mateWriter creates the logger of the file hook options by a mate, and returns
+// the writer of the file
+func mateWriter(t *testing.T, options string) (*logrus_mate.LogrusMate, *fileLogWriter) {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	mate, err := logrus_mate.NewLogrusMate(logrus_mate.ConfigString(`
+mike {
+    out.name = "nil"
+    hooks.file { filename = "` + filepath.ToSlash(filename) + `", level = 6, ` + options + ` }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("mike").Info("before close")
+
+	absFilename, _ := filepath.Abs(filename)
+
+	instanceLocker.Lock()
+	w := instanceByPath[absFilename]
+	instanceLocker.Unlock()
+
+	if w == nil {
+		t.Fatalf("no writer of %s", absFilename)
+	}
+	return mate, w
+}
+
+func closed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestCloseStopsDiskGuard(t *testing.T) {
+	hook := fileHook(t, `min-free-bytes = 1, check-interval = 1h`)
+	w := hook.W
+	if w.guard == nil {
+		t.Fatal("no disk guard")
+	}
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !closed(w.guard.stop) {
+		t.Fatal("the disk guard is not stopped by Close")
+	}
+
+	// destroyed again, e.g. by the tests, never closes the stopped guard twice
+	w.Destroy()
+}
+
+func TestCloseSharedWriter(t *testing.T) {
+	conf := `filename = "` + filepath.ToSlash(filepath.Join(t.TempDir(), "app.log")) + `", min-free-bytes = 1, check-interval = 1h`
+
+	var hooks []*FileHook
+	for i := 0; i < 2; i++ {
+		hook, err := NewFileHook(config.NewConfig(config.ConfigString(conf)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hooks = append(hooks, hook.(*FileHook))
+	}
+	w := hooks[0].W
+	if hooks[1].W != w {
+		t.Fatal("the hooks of the same config get different writers")
+	}
+
+	// the writer is destroyed by the last hook closed, Close is idempotent
+	for i := 0; i < 2; i++ {
+		if err := hooks[0].Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if closed(w.guard.stop) {
+		t.Fatal("the writer is destroyed while a hook still uses it")
+	}
+
+	if err := hooks[1].Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !closed(w.guard.stop) {
+		t.Fatal("the writer is not destroyed by the last hook closed")
+	}
+
+	// a later hook of the config creates a new writer
+	hook, err := NewFileHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.(*FileHook).Close()
+
+	if hook.(*FileHook).W == w {
+		t.Fatal("the destroyed writer is reused")
+	}
+}
+
+func TestCloseStopsCron(t *testing.T) {
+	hook := fileHook(t, `rotate-cron = "0 3 * * *"`)
+	w := hook.W
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !closed(w.cronStop) {
+		t.Fatal("the cron rotation is not stopped by Close")
+	}
+}
+
+func TestCloseWithTimeoutStopsMarkers(t *testing.T) {
+	mate, w := mateWriter(t, `marker-interval = 1h`)
+
+	if err := mate.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if !closed(w.markerStop) {
+		t.Fatal("the markers are not stopped by CloseWithTimeout")
+	}
+
+	instanceLocker.Lock()
+	_, exist := instanceByPath[w.instancePath]
+	instanceLocker.Unlock()
+	if exist {
+		t.Fatal("the closed writer is still an instance")
+	}
+}
@@ -0,0 +1,62 @@
+package logrus_file
+
+import (
+	"time"
+)
+
+// the coalesced messages are written after it when coalesce_interval is not set
+const defaultCoalesceInterval = 100 * time.Millisecond
+
+// write writes msg into the file, with CoalesceBytes the messages are kept in
+// memory and written by one call when the bytes reach it, after CoalesceInterval,
+// or by flushPending before the file is synced, rotated, reopened or closed.
+// The error of the coalesced write is returned to the message triggering it,
+// the other messages coalesced are lost with it. It must be called with w locked.
+func (w *fileLogWriter) write(msg []byte) error {
+	if w.CoalesceBytes <= 0 {
+		return w.writeFile(msg)
+	}
+
+	w.pending = append(w.pending, msg...)
+	if len(w.pending) >= w.CoalesceBytes {
+		return w.writePending()
+	}
+
+	if w.pendingTimer == nil {
+		w.pendingTimer = time.AfterFunc(w.CoalesceInterval, func() {
+			w.Lock()
+			w.flushPending("interval")
+			w.Unlock()
+		})
+	}
+
+	return nil
+}
+
+// writePending writes the coalesced bytes at once. It must be called with w locked.
+func (w *fileLogWriter) writePending() error {
+	if w.pendingTimer != nil {
+		w.pendingTimer.Stop()
+		w.pendingTimer = nil
+	}
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	// not reused, the write abandoned by WriteTimeout may still read it
+	pending := w.pending
+	w.pending = nil
+
+	return w.writeFile(pending)
+}
+
+// flushPending is writePending reporting the error by diagnostics, then the chain
+// is saved after the records it covers. It is called before the file is synced,
+// rotated, reopened or closed. It must be called with w locked.
+func (w *fileLogWriter) flushPending(by string) {
+	if err := w.writePending(); err != nil {
+		w.diag.printf("coalesce:"+err.Error(), "%d %v %s FileLogWriter(%q): coalesced write failed: %s", GoId(), w.now(), by, w.Filename, err)
+	}
+	w.saveChain()
+}
@@ -0,0 +1,104 @@
+package logrus_file
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writesOf(fs *memFS) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.writes
+}
+
+func TestCoalesceBytes(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"coalesce_bytes":9,"coalesce_interval":3600000000000}`)
+
+	writeLines(t, w, "aaa", "bbb")
+	if s := readMem(t, fs, "logs/app.log"); s != "" || writesOf(fs) != 0 {
+		t.Fatalf("written %q by %d writes before the bytes", s, writesOf(fs))
+	}
+
+	// the bytes reached are written by one call
+	writeLines(t, w, "c")
+	if s := readMem(t, fs, "logs/app.log"); s != "aaa\nbbb\nc\n" || writesOf(fs) != 1 {
+		t.Fatalf("written %q by %d writes", s, writesOf(fs))
+	}
+}
+
+func TestCoalesceFlushAndDestroy(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"coalesce_bytes":4096,"coalesce_interval":3600000000000}`)
+
+	writeLines(t, w, "a", "b", "c")
+	w.Flush()
+	if s := readMem(t, fs, "logs/app.log"); s != "a\nb\nc\n" {
+		t.Fatalf("flushed %q", s)
+	}
+
+	writeLines(t, w, "d", "e")
+	w.Destroy()
+	if s := readMem(t, fs, "logs/app.log"); s != "a\nb\nc\nd\ne\n" {
+		t.Fatalf("destroyed %q", s)
+	}
+	if n := writesOf(fs); n != 2 {
+		t.Fatalf("%d writes, expected 2", n)
+	}
+}
+
+func TestCoalesceInterval(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"coalesce_bytes":4096,"coalesce_interval":10000000}`)
+
+	writeLines(t, w, "a", "b")
+	waitContent(t, fs, "logs/app.log", "a\nb\n")
+
+	if n := writesOf(fs); n != 1 {
+		t.Fatalf("%d writes, expected 1", n)
+	}
+}
+
+func TestCoalesceRotate(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0,"coalesce_bytes":4096,"coalesce_interval":3600000000000}`)
+
+	// the coalesced lines are written into the file before it is rotated
+	writeLines(t, w, "1", "2", "3")
+	w.Flush()
+
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "1\n2\n" {
+		t.Fatalf("rotated %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "3\n" {
+		t.Fatalf("active %q", s)
+	}
+}
+
+func BenchmarkCoalesce(b *testing.B) {
+	msg := strings.Repeat("x", 120) + "\n"
+
+	for _, coalesceBytes := range []int{0, 4096, 65536} {
+		b.Run(fmt.Sprintf("bytes-%d", coalesceBytes), func(b *testing.B) {
+			fs := newMemFS()
+			clock := newFakeClock(day1)
+			w := newMemWriter(b, fs, clock, fmt.Sprintf(`{"filename":"logs/app.log","rotate":false,"coalesce_bytes":%d,"coalesce_interval":%d}`, coalesceBytes, time.Hour))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = w.WriteMsg(clock.Now(), msg)
+			}
+			b.StopTimer()
+
+			// the writes per message are the syscalls of the os file
+			b.ReportMetric(float64(writesOf(fs))/float64(b.N), "writes/op")
+		})
+	}
+}
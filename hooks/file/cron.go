@@ -0,0 +1,169 @@
+package logrus_file
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a minimal cron expression: "minute hour day-of-month month day-of-week",
+// every field supports `*`, `5`, `1-5`, `1,3,5` and steps like `*/15` or `0-30/10`.
+// @hourly, @daily and @midnight are also accepted.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	domStar, dowStar bool
+}
+
+type cronBounds struct {
+	min, max int
+}
+
+var cronFieldBounds = []cronBounds{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, 0 and 7 are sunday
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+}
+
+func parseCron(spec string) (schedule *cronSchedule, err error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, exist := cronDescriptors[spec]; exist {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		err = fmt.Errorf("cron: expected 5 fields, found %d: %q", len(fields), spec)
+		return
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		if bits[i], err = parseCronField(field, cronFieldBounds[i]); err != nil {
+			err = fmt.Errorf("cron: %q: %s", spec, err)
+			return
+		}
+	}
+
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	schedule = &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+
+	return
+}
+
+func parseCronField(field string, bounds cronBounds) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		hasStep := false
+		if i := strings.Index(part, "/"); i >= 0 {
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				err = fmt.Errorf("bad step %q", part)
+				return
+			}
+			part = part[:i]
+			hasStep = true
+		}
+
+		min, max := bounds.min, bounds.max
+
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			ends := strings.SplitN(part, "-", 2)
+			if min, err = strconv.Atoi(ends[0]); err != nil {
+				return
+			}
+			if max, err = strconv.Atoi(ends[1]); err != nil {
+				return
+			}
+		default:
+			if min, err = strconv.Atoi(part); err != nil {
+				return
+			}
+			if !hasStep {
+				max = min
+			}
+		}
+
+		if min < bounds.min || max > bounds.max || min > max {
+			err = fmt.Errorf("value %q out of range [%d, %d]", part, bounds.min, bounds.max)
+			return
+		}
+
+		for v := min; v <= max; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return
+}
+
+func (p *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := p.dom&(1<<uint(t.Day())) != 0
+	dowMatch := p.dow&(1<<uint(t.Weekday())) != 0
+
+	if p.domStar || p.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// next returns the first scheduled minute after t
+func (p *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// a valid schedule matches within 5 years, also covers Feb 29
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if p.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !p.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if p.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if p.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func newTimeTimer(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTimer(d)
+	return t.C, func() { t.Stop() }
+}
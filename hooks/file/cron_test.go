@@ -0,0 +1,123 @@
+package logrus_file
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeTimers hands the timers requested by the writer to the test, which fires them
+type fakeTimers chan fakeTimer
+
+type fakeTimer struct {
+	d time.Duration
+	c chan time.Time
+}
+
+func (timers fakeTimers) newTimer(d time.Duration) (<-chan time.Time, func()) {
+	c := make(chan time.Time, 1)
+	timers <- fakeTimer{d: d, c: c}
+	return c, func() {}
+}
+
+func (timers fakeTimers) next(t *testing.T) fakeTimer {
+	t.Helper()
+
+	select {
+	case timer := <-timers:
+		return timer
+	case <-time.After(5 * time.Second):
+		t.Fatal("no timer requested")
+	}
+	return fakeTimer{}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"0 3 * * *", "*/15 * * * 1-5", "0,30 8-18/2 1 * *", "@daily", "@hourly"} {
+		if _, err := parseCron(spec); err != nil {
+			t.Errorf("%q: %s", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"", "0 3 * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "@weekly"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	schedule, err := parseCron("30 3 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-01 is monday
+	from := time.Date(2024, 1, 1, 3, 30, 0, 0, time.Local)
+	expected := time.Date(2024, 1, 8, 3, 30, 0, 0, time.Local)
+
+	if next := schedule.next(from); !next.Equal(expected) {
+		t.Fatalf("next %v, expected %v", next, expected)
+	}
+}
+
+func TestCronRotateAtScheduledMinute(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock(time.Date(2024, 1, 1, 2, 59, 30, 0, time.Local))
+	timers := make(fakeTimers, 10)
+
+	w := startWriter(t, `{"filename":"`+filepath.ToSlash(filepath.Join(dir, "app.log"))+`","rotate_cron":"0 3 * * *","daily":false,"hourly":false}`,
+		func(w *fileLogWriter) {
+			w.now = clock.Now
+			w.newTimer = timers.newTimer
+		})
+
+	if err := w.WriteMsg(clock.Now(), "a\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	timer := timers.next(t)
+	if timer.d != 30*time.Second {
+		t.Fatalf("waits %v, expected 30s to 03:00", timer.d)
+	}
+
+	clock.Add(30 * time.Second)
+	timer.c <- clock.Now()
+
+	// the next timer is requested after the rotation
+	if timer = timers.next(t); timer.d != 24*time.Hour {
+		t.Fatalf("waits %v, expected 24h to the next 03:00", timer.d)
+	}
+	rotated := rotatedFiles(t, dir)
+	if len(rotated) != 1 {
+		t.Fatalf("rotated %v", rotated)
+	}
+
+	if err := w.WriteMsg(clock.Now(), "b\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the frozen clock has not reached the schedule, no rotation
+	timer.c <- clock.Now()
+	timers.next(t)
+
+	if files := rotatedFiles(t, dir); len(files) != 1 {
+		t.Fatalf("rotated %v", files)
+	}
+	for name, expected := range map[string]string{rotated[0]: "a\n", filepath.Join(dir, "app.log"): "b\n"} {
+		if s := readFile(t, name); s != expected {
+			t.Fatalf("%s %q, expected %q", name, s, expected)
+		}
+	}
+}
+
+// rotatedFiles returns the rotated files of app.log in dir
+func rotatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "app.*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
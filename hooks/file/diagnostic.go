@@ -0,0 +1,65 @@
+package logrus_file
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const diagnosticInterval = time.Minute
+
+// diagnostic prints the internal diagnostics to stderr, the same message is printed
+// at most once per interval, with the count of repeats suppressed meanwhile
+type diagnostic struct {
+	locker   sync.Mutex
+	interval time.Duration
+	reported map[string]*diagnosticRecord
+	now      func() time.Time
+	out      io.Writer
+}
+
+type diagnosticRecord struct {
+	at      time.Time
+	repeats int
+}
+
+func newDiagnostic(interval time.Duration) *diagnostic {
+	return &diagnostic{
+		interval: interval,
+		reported: make(map[string]*diagnosticRecord),
+		now:      time.Now,
+		out:      os.Stderr,
+	}
+}
+
+// printf dedupes the message by key, key should not contain the changing values like time
+func (p *diagnostic) printf(key string, format string, args ...interface{}) {
+	now := p.now()
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	record, exist := p.reported[key]
+	if exist && now.Sub(record.at) < p.interval {
+		record.repeats++
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if exist && record.repeats > 0 {
+		msg = fmt.Sprintf("%s (repeated %d times in last %v)", msg, record.repeats, now.Sub(record.at).Truncate(time.Second))
+	}
+
+	_, _ = fmt.Fprintln(p.out, msg)
+
+	// forget the stale keys
+	for k, r := range p.reported {
+		if now.Sub(r.at) >= p.interval {
+			delete(p.reported, k)
+		}
+	}
+
+	p.reported[key] = &diagnosticRecord{at: now}
+}
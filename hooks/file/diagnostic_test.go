@@ -0,0 +1,44 @@
+package logrus_file
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnosticThrottle(t *testing.T) {
+	clock := newFakeClock(day1)
+	out := &bytes.Buffer{}
+
+	diag := newDiagnostic(time.Minute)
+	diag.now = clock.Now
+	diag.out = out
+
+	for i := 0; i < 5; i++ {
+		diag.printf("rotate:read-only", "rotate failed %d", i)
+		clock.Add(time.Second)
+	}
+	diag.printf("open:denied", "open failed")
+
+	if s := out.String(); s != "rotate failed 0\nopen failed\n" {
+		t.Fatalf("output in the interval %q", s)
+	}
+
+	out.Reset()
+	clock.Add(time.Minute)
+	diag.printf("rotate:read-only", "rotate failed again")
+
+	if s := out.String(); !strings.HasPrefix(s, "rotate failed again (repeated 4 times in last 1m5s)") {
+		t.Fatalf("output after the interval %q", s)
+	}
+
+	// the repeats are counted from the last report
+	out.Reset()
+	clock.Add(2 * time.Minute)
+	diag.printf("rotate:read-only", "rotate failed later")
+
+	if s := out.String(); s != "rotate failed later\n" {
+		t.Fatalf("output without repeats %q", s)
+	}
+}
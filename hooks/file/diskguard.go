@@ -0,0 +1,120 @@
+package logrus_file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+var errDiskSpaceUnsupported = errors.New("disk space check is not supported on this platform")
+
+// freeSpaceFunc returns the free and total bytes of the filesystem which contains path
+type freeSpaceFunc func(path string) (free, total uint64, err error)
+
+// diskGuard periodically checks the free space of the log filesystem,
+// while it is below the threshold the messages are dropped instead of written.
+type diskGuard struct {
+	minFreeBytes   uint64
+	minFreePercent float64
+	interval       time.Duration
+	freeSpace      freeSpaceFunc
+
+	dropping int32
+	dropped  uint64
+
+	stop chan struct{}
+	now  func() time.Time
+}
+
+func newDiskGuard(minFreeBytes int64, minFreePercent float64, interval time.Duration) *diskGuard {
+	if minFreeBytes <= 0 && minFreePercent <= 0 {
+		return nil
+	}
+
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	return &diskGuard{
+		minFreeBytes:   uint64(minFreeBytes),
+		minFreePercent: minFreePercent,
+		interval:       interval,
+		freeSpace:      diskFreeSpace,
+		stop:           make(chan struct{}),
+		now:            time.Now,
+	}
+}
+
+func (g *diskGuard) start(filename string) {
+	dir := filepath.Dir(filename)
+	if !g.check(dir) {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !g.check(dir) {
+					return
+				}
+			case <-g.stop:
+				return
+			}
+		}
+	}()
+}
+
+// check updates the drop mode, it returns false if the space could never be checked
+func (g *diskGuard) check(dir string) bool {
+	free, total, err := g.freeSpace(dir)
+	if err == errDiskSpaceUnsupported {
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v diskGuard(%q): %s, guard disabled\n", GoId(), g.now(), dir, err)
+		return false
+	} else if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v diskGuard(%q): %s\n", GoId(), g.now(), dir, err)
+		return true
+	}
+
+	low := (g.minFreeBytes > 0 && free < g.minFreeBytes) ||
+		(g.minFreePercent > 0 && total > 0 && float64(free)*100/float64(total) < g.minFreePercent)
+
+	if low {
+		if atomic.CompareAndSwapInt32(&g.dropping, 0, 1) {
+			_, _ = fmt.Fprintf(os.Stderr, "%d %v diskGuard(%q): free space %d of %d bytes is low, dropping messages\n", GoId(), g.now(), dir, free, total)
+		}
+	} else if atomic.CompareAndSwapInt32(&g.dropping, 1, 0) {
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v diskGuard(%q): free space recovered, %d messages dropped\n", GoId(), g.now(), dir, atomic.LoadUint64(&g.dropped))
+	}
+
+	return true
+}
+
+// drop returns true and counts the message if the guard is in drop mode
+func (g *diskGuard) drop() bool {
+	if g == nil || atomic.LoadInt32(&g.dropping) == 0 {
+		return false
+	}
+	atomic.AddUint64(&g.dropped, 1)
+	return true
+}
+
+func (g *diskGuard) Dropped() uint64 {
+	if g == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&g.dropped)
+}
+
+func (g *diskGuard) close() {
+	if g == nil {
+		return
+	}
+	close(g.stop)
+}
@@ -0,0 +1,108 @@
+package logrus_file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeSpace is the free space checker of tests
+type fakeSpace struct {
+	free, total uint64
+	err         error
+}
+
+func (s *fakeSpace) freeSpace(string) (uint64, uint64, error) {
+	return s.free, s.total, s.err
+}
+
+func TestDiskGuardMinFreeBytes(t *testing.T) {
+	space := &fakeSpace{free: 100, total: 1000}
+	g := newDiskGuard(200, 0, 0)
+	g.freeSpace = space.freeSpace
+
+	if !g.check("logs") || !g.drop() || !g.drop() {
+		t.Fatal("expected dropping below min free bytes")
+	}
+
+	space.free = 500
+	g.check("logs")
+	if g.drop() {
+		t.Fatal("expected writing after the space recovered")
+	}
+
+	if g.Dropped() != 2 {
+		t.Fatalf("dropped %d, expected 2", g.Dropped())
+	}
+}
+
+func TestDiskGuardMinFreePercent(t *testing.T) {
+	space := &fakeSpace{free: 40, total: 1000}
+	g := newDiskGuard(0, 5, 0)
+	g.freeSpace = space.freeSpace
+
+	g.check("logs")
+	if !g.drop() {
+		t.Fatal("expected dropping below 5% free")
+	}
+
+	space.free = 60
+	g.check("logs")
+	if g.drop() {
+		t.Fatal("expected writing above 5% free")
+	}
+}
+
+func TestDiskGuardUnsupported(t *testing.T) {
+	g := newDiskGuard(200, 0, 0)
+	g.freeSpace = (&fakeSpace{err: errDiskSpaceUnsupported}).freeSpace
+
+	if g.check("logs") {
+		t.Fatal("the guard of unsupported platform should stop checking")
+	}
+	if g.drop() {
+		t.Fatal("the unchecked guard never drops")
+	}
+}
+
+func TestDiskGuardDisabled(t *testing.T) {
+	g := newDiskGuard(0, 0, 0)
+	if g != nil || g.drop() || g.Dropped() != 0 {
+		t.Fatal("the guard without thresholds is nil and never drops")
+	}
+}
+
+func TestDiskGuardDropsWrites(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	w, err := newFileWriter(`{"filename":"` + filepath.ToSlash(filename) + `","daily":false,"hourly":false}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	space := &fakeSpace{free: 10, total: 1000}
+	w.guard = newDiskGuard(100, 0, 0)
+	w.guard.freeSpace = space.freeSpace
+	w.guard.check(dir)
+
+	_ = w.WriteMsg(time.Now(), "dropped\n")
+
+	space.free = 1000
+	w.guard.check(dir)
+	if err := w.WriteMsg(time.Now(), "written\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "written\n" {
+		t.Fatalf("file %q", b)
+	}
+	if w.guard.Dropped() != 1 {
+		t.Fatalf("dropped %d, expected 1", w.guard.Dropped())
+	}
+}
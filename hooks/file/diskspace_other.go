@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+// +build !linux,!darwin,!freebsd,!dragonfly,!windows
+
+package logrus_file
+
+func diskFreeSpace(path string) (free, total uint64, err error) {
+	err = errDiskSpaceUnsupported
+	return
+}
@@ -0,0 +1,289 @@
+package logrus_file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// encSuffix is appended to the rotated file compressed and encrypted, see encryptRotated
+const encSuffix = ".gz.enc"
+
+// the magic of the encrypted file, followed by the nonce prefix and the chunks
+const encMagic = "LMGE1"
+
+const (
+	encChunkSize   = 64 * 1024
+	encNoncePrefix = 8
+)
+
+var errEncTruncated = errors.New("encrypted log is truncated")
+
+// encryptKey is the AES key written as base64 in config, it is marshaled and
+// formatted as <redacted>, so the key is never printed with the writer
+type encryptKey []byte
+
+// redacted is the text of the key set, empty when not set
+const redacted = "<redacted>"
+
+func (k *encryptKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if len(s) == 0 {
+		*k = nil
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid encrypt_key: expected base64: %s", err)
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return fmt.Errorf("invalid encrypt_key: expected 16, 24 or 32 bytes, but got %d", len(key))
+	}
+
+	*k = key
+	return nil
+}
+
+func (k encryptKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k encryptKey) String() string {
+	if len(k) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// Format prints String by every verb, e.g. %x and %#v would print the bytes
+func (k encryptKey) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, k.String())
+}
+
+// encryptRotated gzips then encrypts the rotated file into name.gz.enc, the file
+// is removed after, the partial output is removed when failed
+func (w *fileLogWriter) encryptRotated(name string, perm os.FileMode) {
+	tmpName := name + encSuffix + ".tmp"
+
+	err := func() error {
+		src, err := w.fs.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := w.fs.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm&0600)
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		enc, err := newEncWriter(dst, w.EncryptKey)
+		if err != nil {
+			return err
+		}
+
+		zw := gzip.NewWriter(enc)
+		if _, err = io.Copy(zw, src); err != nil {
+			return err
+		}
+		if err = zw.Close(); err != nil {
+			return err
+		}
+		if err = enc.Close(); err != nil {
+			return err
+		}
+
+		return dst.Sync()
+	}()
+
+	if err == nil {
+		err = w.fs.Rename(tmpName, name+encSuffix)
+	}
+
+	if err != nil {
+		_ = w.fs.Remove(tmpName)
+		w.diag.printf("encrypt:"+err.Error(), "%d %v encrypt FileLogWriter(%q) %s: %s", GoId(), w.now(), w.Filename, name, err)
+		return
+	}
+
+	_ = w.fs.Chmod(name+encSuffix, perm)
+	_ = w.fs.Remove(name)
+}
+
+// encWriter seals the stream by AES-GCM in chunks, the nonce of a chunk is the
+// random prefix and its counter, and the last chunk is marked in the additional
+// data, so the chunks could not be reordered, dropped or truncated unnoticed
+type encWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	buf     []byte
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newEncWriter(w io.Writer, key []byte) (*encWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce[:encNoncePrefix]); err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(append([]byte(encMagic), nonce[:encNoncePrefix]...)); err != nil {
+		return nil, err
+	}
+
+	return &encWriter{w: w, aead: aead, nonce: nonce}, nil
+}
+
+func (p *encWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	// the chunk is sealed when more follows, so the last is known at Close
+	for len(p.buf) > encChunkSize {
+		if err := p.seal(p.buf[:encChunkSize], false); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[encChunkSize:]
+	}
+
+	return len(b), nil
+}
+
+func (p *encWriter) Close() error {
+	return p.seal(p.buf, true)
+}
+
+func (p *encWriter) seal(chunk []byte, last bool) error {
+	binary.BigEndian.PutUint32(p.nonce[encNoncePrefix:], p.counter)
+	p.counter++
+
+	sealed := p.aead.Seal(nil, p.nonce, chunk, encAdditional(last))
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sealed)))
+	if _, err := p.w.Write(size[:]); err != nil {
+		return err
+	}
+
+	_, err := p.w.Write(sealed)
+	return err
+}
+
+func encAdditional(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// Decrypt writes the log decrypted and decompressed from src, the file rotated
+// with encrypt-key, key is the decoded bytes of encrypt-key, e.g.
+// key, _ := base64.StdEncoding.DecodeString(os.Getenv("LOG_KEY"))
+func Decrypt(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(src)
+
+	header := make([]byte, len(encMagic)+encNoncePrefix)
+	if _, err = io.ReadFull(r, header); err != nil || string(header[:len(encMagic)]) != encMagic {
+		return errors.New("not an encrypted log")
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[len(encMagic):])
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		zr, err := gzip.NewReader(pr)
+		if err == nil {
+			_, err = io.Copy(dst, zr)
+		}
+		// drain, so the decrypting never blocks on the failed decompress
+		_, _ = io.Copy(ioutil.Discard, pr)
+		done <- err
+	}()
+
+	err = decryptChunks(pw, r, aead, nonce)
+	_ = pw.CloseWithError(err)
+
+	if zerr := <-done; err == nil {
+		err = zerr
+	}
+
+	return err
+}
+
+func decryptChunks(w io.Writer, r *bufio.Reader, aead cipher.AEAD, nonce []byte) error {
+	var counter uint32
+	var size [4]byte
+
+	for {
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return errEncTruncated
+		}
+
+		n := binary.BigEndian.Uint32(size[:])
+		if n > uint32(encChunkSize+aead.Overhead()) {
+			return fmt.Errorf("decrypt chunk %d: invalid size %d", counter, n)
+		}
+
+		sealed := make([]byte, n)
+		if _, err := io.ReadFull(r, sealed); err != nil {
+			return errEncTruncated
+		}
+
+		binary.BigEndian.PutUint32(nonce[encNoncePrefix:], counter)
+		counter++
+
+		_, peekErr := r.Peek(1)
+		last := peekErr == io.EOF
+
+		chunk, err := aead.Open(nil, nonce, sealed, encAdditional(last))
+		if err != nil {
+			if last {
+				// the chunk sealed as not last, the chunks after it are cut
+				return errEncTruncated
+			}
+			return fmt.Errorf("decrypt chunk %d: %s", counter-1, err)
+		}
+
+		if _, err = w.Write(chunk); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package logrus_file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func encryptConfig() string {
+	return `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0,"encrypt_key":"` + base64.StdEncoding.EncodeToString(testKey) + `"}`
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, encryptConfig())
+
+	writeLines(t, w, "secret 1", "secret 2", "3")
+	w.encrypting.Wait()
+
+	// the plain rotated file is removed, the encrypted is always numbered
+	assertNames(t, fs, "logs/app.2024-01-01.001.log.gz.enc", "logs/app.log")
+
+	sealed := readMem(t, fs, "logs/app.2024-01-01.001.log.gz.enc")
+	if strings.Contains(sealed, "secret") {
+		t.Fatalf("the rotated file is plain: %q", sealed)
+	}
+
+	var plain bytes.Buffer
+	if err := Decrypt(&plain, strings.NewReader(sealed), testKey); err != nil {
+		t.Fatalf("decrypt: %s", err)
+	}
+	if s := plain.String(); s != "secret 1\nsecret 2\n" {
+		t.Fatalf("decrypted %q", s)
+	}
+}
+
+func TestEncryptRoundTripChunks(t *testing.T) {
+	// random, so the gzip stream is more than one chunk and the last is partial
+	data := make([]byte, encChunkSize*2+100)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	var sealed bytes.Buffer
+	enc, err := newEncWriter(&sealed, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(enc)
+	if _, err = zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var plain bytes.Buffer
+	if err = Decrypt(&plain, &sealed, testKey); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plain.Bytes(), data) {
+		t.Fatalf("decrypted %d bytes, expected %d", plain.Len(), len(data))
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, encryptConfig())
+
+	writeLines(t, w, "1", "2", "3")
+	w.encrypting.Wait()
+	sealed := []byte(readMem(t, fs, "logs/app.2024-01-01.001.log.gz.enc"))
+
+	var plain bytes.Buffer
+	if err := Decrypt(&plain, bytes.NewReader(sealed), []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Fatal("decrypted by the wrong key")
+	}
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-1] ^= 1
+	if err := Decrypt(&plain, bytes.NewReader(flipped), testKey); err == nil {
+		t.Fatal("decrypted the flipped")
+	}
+
+	if err := Decrypt(&plain, bytes.NewReader(sealed[:len(sealed)-1]), testKey); err == nil {
+		t.Fatal("decrypted the truncated")
+	}
+}
+
+func TestEncryptKeyRedacted(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, encryptConfig())
+
+	leaks := []string{string(testKey), base64.StdEncoding.EncodeToString(testKey), hex.EncodeToString(testKey)}
+
+	for _, s := range []string{
+		fmt.Sprint(w),
+		fmt.Sprintf("%v", w),
+		fmt.Sprintf("%+v", w),
+		fmt.Sprintf("%v", w.EncryptKey),
+		fmt.Sprintf("%s", w.EncryptKey),
+		fmt.Sprintf("%x", w.EncryptKey),
+		fmt.Sprintf("%q", w.EncryptKey),
+		fmt.Sprintf("%#v", w.EncryptKey),
+	} {
+		for _, leak := range leaks {
+			if strings.Contains(s, leak) {
+				t.Fatalf("the key is printed: %s", s)
+			}
+		}
+	}
+
+	if s := fmt.Sprint(w.EncryptKey); s != redacted {
+		t.Fatalf("key printed as %q", s)
+	}
+	if s := fmt.Sprint(encryptKey(nil)); s != "" {
+		t.Fatalf("no key printed as %q", s)
+	}
+}
+
+func TestEncryptKeyInvalid(t *testing.T) {
+	for _, key := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		w := newDefaultWriter()
+		w.fs = newMemFS()
+		if err := w.Init(`{"filename":"logs/app.log","encrypt_key":"` + key + `"}`); err == nil {
+			t.Fatalf("key %q is accepted", key)
+		}
+	}
+}
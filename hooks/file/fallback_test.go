@@ -0,0 +1,54 @@
+package logrus_file
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStderrFallback(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := newFileWriter(`{"filename":"` + filepath.ToSlash(filename) + `","stderr_fallback":true,"daily":false,"hourly":false}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	stderr := &bytes.Buffer{}
+	w.stderr = stderr
+
+	if err := w.WriteMsg(time.Now(), "before\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file fails mid-run, like the disk becomes full
+	_ = w.fileWriter.Close()
+	for i := 0; i < stderrFallbackLimit+5; i++ {
+		_ = w.WriteMsg(time.Now(), fmt.Sprintf("lost %d\n", i))
+	}
+
+	if n := strings.Count(stderr.String(), "lost "); n != stderrFallbackLimit {
+		t.Fatalf("%d messages on stderr in a second, expected the limit %d: %q", n, stderrFallbackLimit, stderr.String())
+	}
+
+	// the next window
+	w.fallbackWindow = w.fallbackWindow.Add(-time.Second)
+	stderr.Reset()
+	_ = w.WriteMsg(time.Now(), "next\n")
+
+	if s := stderr.String(); !strings.Contains(s, "dropped 5 messages") || !strings.Contains(s, "next\n") {
+		t.Fatalf("the next window reports the dropped and writes: %q", s)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "before\n" {
+		t.Fatalf("file %q", b)
+	}
+}
@@ -0,0 +1,21 @@
+package logrus_file
+
+import (
+	"fmt"
+	"os"
+)
+
+// openFd opens the pre-opened descriptor passed by the supervisor, e.g. fd 3,
+// it is checked writable by a zero length write
+func openFd(fd int) (*os.File, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid fd %d", fd)
+	}
+
+	if _, err := f.Write(nil); err != nil {
+		return nil, fmt.Errorf("fd %d is not writable: %s", fd, err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,101 @@
+//go:build linux || darwin || freebsd || dragonfly
+// +build linux darwin freebsd dragonfly
+
+package logrus_file
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// pipeFd returns the reader of a pipe and a dup of its write end, owned by the writer under test
+func pipeFd(t *testing.T) (*bufio.Reader, int) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+	_ = r.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bufio.NewReader(r), fd
+}
+
+func readLine(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read pipe: %s", err)
+	}
+	return line
+}
+
+func TestFdPipe(t *testing.T) {
+	r, fd := pipeFd(t)
+
+	// rotate is skipped for the descriptor
+	hook, err := NewFileHook(config.NewConfig(config.ConfigString(fmt.Sprintf(`fd = %d, level = 5, max-lines = 1, rotate = true, daily = true`, fd))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(hook.(*FileHook).W.Destroy)
+
+	logger := logrus.New()
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true, DisableColors: true}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		if err = hook.Fire(logrus.NewEntry(logger).WithField("msg_id", msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		if line := readLine(t, r); !strings.Contains(line, "msg_id="+msg) {
+			t.Fatalf("line %q, expected %s", line, msg)
+		}
+	}
+
+	if w := hook.(*FileHook).W; w.Rotate {
+		t.Fatal("the descriptor should not rotate")
+	}
+	if _, err = os.Stat(fmt.Sprintf("fd%d", fd)); !os.IsNotExist(err) {
+		t.Fatalf("a file is created for the descriptor: %v", err)
+	}
+}
+
+func TestFdNotWritable(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	defer r.Close()
+
+	fd, err := syscall.Dup(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the dup is owned by the file of the failed writer, closed when collected
+
+	if _, err = openFd(fd); err == nil || !strings.Contains(err.Error(), "not writable") {
+		t.Fatalf("the read end should not be writable: %v", err)
+	}
+}
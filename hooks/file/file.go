@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -48,54 +50,199 @@ type fileLogWriter struct {
 	sync.RWMutex // write log order by order and  atomic incr maxLinesCurLines and maxSizeCurSize
 	// The opened file
 	Filename   string `json:"filename"`
-	fileWriter *os.File
+	fileWriter logFile
+
+	// Write to the pre-opened descriptor instead of Filename, it is never rotated or reopened
+	Fd int `json:"fd"`
+
+	fs fileSystem
 
 	// Rotate at line
 	MaxLines         int `json:"maxlines"`
 	maxLinesCurLines int
 
+	// Count the block of WriteBlock as one line, the count after reopen is by the lines in file
+	CountBlocksAsOne bool `json:"count_blocks_as_one"`
+
 	// Rotate at size
 	MaxSize        int `json:"maxsize"`
 	maxSizeCurSize int
 
 	StripColors bool `json:"stripcolors"`
 
+	// Strip the messages longer by a byte scan instead of the regexp, 0 always uses the regexp
+	StripMaxLen int `json:"strip_max_len"`
+
+	// Terminate every line by it instead of "\n", e.g. "\r\n" or "\x00", maxlines counts it
+	LineTerminator string `json:"line_terminator"`
+	terminator     []byte
+
 	Hourly         bool `json:"hourly"`
 	HourlyOpenDate int  `json:"hourly_open"`
 
 	// Rotate daily
 	Daily         bool  `json:"daily"`
 	MaxDays       int64 `json:"maxdays"`
+	MaxFiles      int   `json:"maxfiles"`
 	DailyOpenDate int   `json:"daily_open"`
 	dailyOpenTime time.Time
 
 	Rotate bool `json:"rotate"`
 
+	// Write Slots files in cycle with RotateMode round-robin, like xx.0.log ... xx.3.log, see rotateSlot
+	RotateMode   string `json:"rotate_mode"`
+	Slots        int    `json:"slots"`
+	slot         int
+	truncateSlot bool
+
+	// Name every rotated file with the number, the first included, like xx.2013-01-01.001.log
+	AlwaysNumber bool `json:"always_number"`
+
+	// Compress and encrypt the rotated files into xx.2013-01-01.001.log.gz.enc, see encryptRotated
+	EncryptKey encryptKey `json:"encrypt_key"`
+	encrypting sync.WaitGroup
+
 	Level int `json:"level"`
 
 	Perm string `json:"perm"`
 
 	RotatePerm string `json:"rotateperm"`
 
+	// Truncate the file at the initial open instead of append
+	Truncate bool `json:"truncate"`
+
+	// Retry the initial open failed, the backoff is doubled after each failure, see startLoggerRetry
+	OpenRetries int           `json:"open_retries"`
+	OpenBackoff time.Duration `json:"open_backoff"`
+	sleep       func(d time.Duration)
+
+	// Reopen the file after it has been open for the duration
+	MaxOpenAge time.Duration `json:"max_open_age"`
+	openedAt   time.Time
+
+	// Write the sentinel record at the interval, see writeMarkers
+	MarkerInterval time.Duration `json:"marker_interval"`
+	markerStop     chan struct{}
+	newTicker      func(d time.Duration) (<-chan time.Time, func())
+
+	// Record the offset of every IndexEvery lines into the sidecar Filename.idx, see openIndex
+	Index       bool `json:"index"`
+	IndexEvery  int  `json:"index_every"`
+	indexWriter logFile
+	indexLines  int
+	indexNext   int
+
+	// Chain every record to the previous by its hash in the field prev_hash, see chain
+	AuditChain   bool   `json:"audit_chain"`
+	AuditHash    string `json:"audit_hash"`
+	chainNew     func() hash.Hash
+	chainHash    string
+	chainSaved   string
+	chainSavedAt time.Time
+
+	// Abandon the write after the timeout, see write
+	WriteTimeout time.Duration `json:"write_timeout"`
+	inflight     chan error
+
+	// Hold the messages up to ReadOnlyBuffer bytes while the filesystem is read-only, see enterReadOnly
+	ReadOnlyBuffer int           `json:"readonly_buffer"`
+	ReadOnlyProbe  time.Duration `json:"readonly_probe"`
+	readOnly       *readOnlyState
+
+	// Write the messages at once by CoalesceBytes or after CoalesceInterval, see write
+	CoalesceBytes    int           `json:"coalesce_bytes"`
+	CoalesceInterval time.Duration `json:"coalesce_interval"`
+	pending          []byte
+	pendingTimer     *time.Timer
+
+	// Write the message to stderr when writing into file failed
+	StderrFallback  bool `json:"stderr_fallback"`
+	stderr          io.Writer
+	fallbackWindow  time.Time
+	fallbackCount   int
+	fallbackDropped int
+
+	// Drop messages while the free space of log filesystem is below the threshold
+	MinFreeBytes   int64         `json:"min_free_bytes"`
+	MinFreePercent float64       `json:"min_free_percent"`
+	CheckInterval  time.Duration `json:"check_interval"`
+	guard          *diskGuard
+	stopOnce       sync.Once
+
+	// Rotate at the times of cron expression, e.g. "0 3 * * *"
+	RotateCron string `json:"rotate_cron"`
+	cronStop   chan struct{}
+	now        func() time.Time
+	newTimer   func(d time.Duration) (<-chan time.Time, func())
+
+	// the hooks sharing the writer by its instance keys, the last closed destroys it, see release
+	refs         int
+	instanceKey  string
+	instancePath string
+
+	// last used rotate number of the date, so doRotate needn't scan from 1
+	rotateNumKey string
+	rotateNum    int
+
+	// dedupe the repeated diagnostics while rotate keeps failing
+	diag *diagnostic
+
 	fileNameOnly, suffix string // like "project.log", project is fileNameOnly and .log is suffix
 }
 
-var instance map[string]*fileLogWriter
+var (
+	// the loggers are created by Logger(name) from any goroutine
+	instanceLocker sync.Mutex
+
+	// writers by config, the hooks of the same config share the writer
+	instance = make(map[string]*fileLogWriter)
+
+	// writers by absolute filename, the writers of different configs must not rotate the same file
+	instanceByPath = make(map[string]*fileLogWriter)
+)
 
 // newFileWriter create a FileLogWriter returning as LoggerInterface.
-func newFileWriter(jsonConfig string) *fileLogWriter {
+// The same config of a file shares the writer, another config of the file is an error.
+func newFileWriter(jsonConfig string) (*fileLogWriter, error) {
+	instanceLocker.Lock()
+	defer instanceLocker.Unlock()
+
+	key, absFilename := instanceKey(jsonConfig)
+
+	if value, ok := instance[key]; ok {
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: newFileWriter use exist %v\n", GoId(), value.now(), value)
+		value.refs++
+		return value, nil
+	}
 
-	if instance == nil {
-		instance = make(map[string]*fileLogWriter)
+	if _, ok := instanceByPath[absFilename]; ok {
+		return nil, fmt.Errorf("logurs mate: file hook of %s: the file is written by another config", absFilename)
 	}
 
-	if value, ok := instance[jsonConfig]; ok {
-		_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: newFileWriter use exist %v\n", GoId(), time.Now(), value)
-		return value
+	w := newDefaultWriter()
+
+	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: newFileWriter create new %v\n", GoId(), w.now(), w)
+
+	err := w.Init(jsonConfig)
+	if err != nil {
+		return nil, fmt.Errorf("logurs mate: file hook of %s: %s", w.Filename, err)
 	}
 
+	w.refs, w.instanceKey, w.instancePath = 1, key, absFilename
+	instance[key] = w
+	if len(absFilename) > 0 {
+		instanceByPath[absFilename] = w
+	}
+
+	return w, nil
+}
+
+// newDefaultWriter returns the writer of default options on the disk, it is not
+// started until Init
+func newDefaultWriter() *fileLogWriter {
 	w := &fileLogWriter{
 		StripColors: true,
+		StripMaxLen: defaultStripMaxLen,
 		Daily:       true,
 		Hourly:      true,
 		MaxDays:     7,
@@ -103,18 +250,68 @@ func newFileWriter(jsonConfig string) *fileLogWriter {
 		RotatePerm:  "0440",
 		Level:       LevelDebug,
 		Perm:        "0660",
+		stderr:      os.Stderr,
+		IndexEvery:  1000,
+		now:         time.Now,
+		newTimer:    newTimeTimer,
+		newTicker:   newTimeTicker,
+		fs:          osFS{},
+		sleep:       time.Sleep,
+		diag:        newDiagnostic(diagnosticInterval),
 	}
 
-	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: newFileWriter create new %v\n", GoId(), time.Now(), w)
+	// the diagnostics follow the clock of writer, see FileHook.SetClock
+	w.diag.now = func() time.Time { return w.now() }
 
-	err := w.Init(jsonConfig)
+	return w
+}
+
+// release drops a hook of the writer, the last one removes it from the instances
+// and destroys it, so a later hook of the config creates a new writer
+func (w *fileLogWriter) release() {
+	instanceLocker.Lock()
+	w.refs--
+	last := w.refs <= 0
+	if last {
+		if instance[w.instanceKey] == w {
+			delete(instance, w.instanceKey)
+		}
+		if instanceByPath[w.instancePath] == w {
+			delete(instanceByPath, w.instancePath)
+		}
+	}
+	instanceLocker.Unlock()
+
+	if last {
+		w.Destroy()
+	}
+}
+
+// instanceKey returns the config by the absolute filename, so the same config of
+// the file by another path, e.g. logs/./app.log, shares the writer
+func instanceKey(jsonConfig string) (key, absFilename string) {
+	var conf map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonConfig), &conf); err != nil {
+		return jsonConfig, ""
+	}
+
+	filename, _ := conf["filename"].(string)
+	if len(filename) == 0 {
+		return jsonConfig, ""
+	}
+
+	absFilename, err := filepath.Abs(filename)
 	if err != nil {
-		return nil
+		return jsonConfig, ""
 	}
 
-	instance[jsonConfig] = w
+	conf["filename"] = absFilename
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return jsonConfig, absFilename
+	}
 
-	return w
+	return string(data), absFilename
 }
 
 func (w fileLogWriter) String() string {
@@ -152,8 +349,85 @@ func (w *fileLogWriter) Init(jsonConfig string) error {
 	if w.suffix == "" {
 		w.suffix = ".log"
 	}
-	err = w.startLogger()
-	return err
+	if w.Fd > 0 {
+		// a descriptor can't be renamed or reopened
+		w.Rotate = false
+		w.RotateCron = ""
+		w.MaxOpenAge = 0
+		w.Truncate = false
+		w.Index = false
+		w.RotateMode = ""
+	}
+	switch w.RotateMode {
+	case "":
+		w.Slots = 0
+	case rotateRoundRobin:
+		if w.Slots < 2 {
+			return fmt.Errorf("invalid slots %d: expected 2 or more with rotate_mode %s", w.Slots, w.RotateMode)
+		}
+		if len(w.EncryptKey) > 0 {
+			return fmt.Errorf("rotate_mode %s can't encrypt the slots", w.RotateMode)
+		}
+		w.slot = w.lastSlot()
+	default:
+		return fmt.Errorf("invalid rotate_mode %q: expected %s", w.RotateMode, rotateRoundRobin)
+	}
+	if _, err = parsePerm("perm", w.Perm); err != nil {
+		return err
+	}
+	if w.IndexEvery <= 0 {
+		w.IndexEvery = 1
+	}
+	if w.CoalesceInterval <= 0 {
+		w.CoalesceInterval = defaultCoalesceInterval
+	}
+	if w.ReadOnlyProbe <= 0 {
+		w.ReadOnlyProbe = defaultReadOnlyProbe
+	}
+	if len(w.LineTerminator) == 0 {
+		w.LineTerminator = "\n"
+	}
+	w.terminator = []byte(w.LineTerminator)
+	if _, err = parsePerm("rotateperm", w.RotatePerm); err != nil {
+		return err
+	}
+	if w.AuditChain {
+		if w.chainNew, err = newChainHash(w.AuditHash); err != nil {
+			return err
+		}
+		if err = w.loadChain(); err != nil {
+			return err
+		}
+	}
+	if w.OpenBackoff <= 0 {
+		w.OpenBackoff = defaultOpenBackoff
+	}
+	err = w.startLoggerRetry()
+	if err != nil {
+		return err
+	}
+
+	w.guard = newDiskGuard(w.MinFreeBytes, w.MinFreePercent, w.CheckInterval)
+	if w.guard != nil {
+		w.guard.now = func() time.Time { return w.now() }
+		w.guard.start(w.Filename)
+	}
+
+	if len(w.RotateCron) > 0 {
+		schedule, err := parseCron(w.RotateCron)
+		if err != nil {
+			return err
+		}
+		w.cronStop = make(chan struct{})
+		go w.cronRotate(schedule)
+	}
+
+	if w.MarkerInterval > 0 {
+		w.markerStop = make(chan struct{})
+		go w.writeMarkers()
+	}
+
+	return nil
 }
 
 // start file logger. create log file and set to locker-inside file writer.
@@ -163,12 +437,42 @@ func (w *fileLogWriter) startLogger() error {
 		return err
 	}
 	if w.fileWriter != nil {
+		w.flushPending("reopen")
 		_ = w.fileWriter.Close()
 	}
 	w.fileWriter = file
+	w.openedAt = w.now()
 	return w.initFd()
 }
 
+// the first backoff of open_retries when open_backoff is not set
+const defaultOpenBackoff = 100 * time.Millisecond
+
+// startLoggerRetry is startLogger retried by OpenRetries, so the transient failure
+// while starting, e.g. the mount is not ready yet, does not fail the hook. The
+// reopen by rotate is never retried, it must not sleep under the lock.
+func (w *fileLogWriter) startLoggerRetry() error {
+	backoff := w.OpenBackoff
+
+	err := w.startLogger()
+	for i := 0; err != nil && i < w.OpenRetries; i++ {
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v FileLogWriter(%q): open failed, retry %d/%d in %v: %s\n", GoId(), w.now(), w.Filename, i+1, w.OpenRetries, backoff, err)
+
+		w.sleep(backoff)
+		backoff *= 2
+
+		err = w.startLogger()
+	}
+
+	return err
+}
+
+func (w *fileLogWriter) setOpenTime(t time.Time) {
+	w.dailyOpenTime = t
+	w.DailyOpenDate = t.Day()
+	w.HourlyOpenDate = t.Hour()
+}
+
 func (w *fileLogWriter) needRotate(size int, day int, hour int) bool {
 
 	return (w.MaxLines > 0 && w.maxLinesCurLines >= w.MaxLines) ||
@@ -204,23 +508,60 @@ func GoId() int {
 
 // WriteMsg write logger message into file.
 func (w *fileLogWriter) WriteMsg(when time.Time, msg string) error {
-	_, d, h := formatTimeHeader(when)
+	return w.writeBytes(when, []byte(msg), 1)
+}
+
+// WriteBlock writes the header and the indented lines as one write, so the
+// block is never interleaved by others or split by rotate. It counts as one
+// line of maxlines with CountBlocksAsOne, else as its lines.
+func (w *fileLogWriter) WriteBlock(when time.Time, header string, lines []string) error {
+	b := &bytes.Buffer{}
+
+	b.WriteString(strings.TrimSuffix(header, "\n"))
+	b.Write(w.terminator)
+
+	for _, line := range lines {
+		b.WriteByte('\t')
+		b.WriteString(strings.TrimSuffix(line, "\n"))
+		b.Write(w.terminator)
+	}
+
+	count := 1 + len(lines)
+	if w.CountBlocksAsOne {
+		count = 1
+	}
+
+	return w.writeBytes(when, b.Bytes(), count)
+}
+
+// writeBytes is WriteMsg without the string copy, when rotate, stripcolors,
+// max open age and disk guard are all off, it is a direct Write under lock.
+// lines is counted for maxlines.
+func (w *fileLogWriter) writeBytes(when time.Time, msg []byte, lines int) error {
+	if w.guard.drop() {
+		return nil
+	}
 
 	if w.StripColors {
-		msg = Strip(msg)
+		msg = w.strip(msg)
 	}
 
+	msg = w.terminate(msg)
+
 	if w.Rotate {
+		_, d, h := formatTimeHeader(when)
+
+		// the read-only filesystem can't rename, rotate after recovered
 		w.RLock()
-		if w.needRotate(len(msg), d, h) {
+		if w.readOnly == nil && w.needRotate(len(msg), d, h) {
 			w.RUnlock()
 			w.Lock()
 
-			_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: WriteMsg day %d, hour %d, %v\n", GoId(), time.Now(), d, h, w)
+			w.diag.printf("WriteMsg", "%d %v rotate: WriteMsg day %d, hour %d, %v", GoId(), w.now(), d, h, w)
 
-			if w.needRotate(len(msg), d, h) {
-				if err := w.doRotate(when); err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "%d %v WriteMsg FileLogWriter(%q): %s\n", GoId(), when, w.Filename, err)
+			if w.readOnly == nil && w.needRotate(len(msg), d, h) {
+				if err := w.doRotate(when, false); err != nil {
+					w.diag.printf("WriteMsg:"+err.Error(), "%d %v WriteMsg FileLogWriter(%q): %s", GoId(), when, w.Filename, err)
 				}
 			}
 
@@ -230,28 +571,151 @@ func (w *fileLogWriter) WriteMsg(when time.Time, msg string) error {
 		}
 	}
 
+	if w.MaxOpenAge > 0 {
+		w.RLock()
+		expired := w.openExpired()
+		w.RUnlock()
+
+		if expired {
+			w.Lock()
+			if w.openExpired() {
+				if err := w.startLogger(); err != nil {
+					w.diag.printf("reopen:"+err.Error(), "%d %v reopen FileLogWriter(%q): %s", GoId(), when, w.Filename, err)
+				}
+			}
+			w.Unlock()
+		}
+	}
+
 	w.Lock()
-	_, err := w.fileWriter.Write([]byte(msg))
-	if err == nil {
-		w.maxLinesCurLines++
-		w.maxSizeCurSize += len(msg)
+	var err error
+	if w.readOnly != nil {
+		// held until the filesystem is writable again
+		w.readOnly.hold(w, msg, lines)
+	} else if err = w.writeLocked(msg, lines); err != nil {
+		if w.ReadOnlyBuffer > 0 && isReadOnly(err) {
+			w.enterReadOnly(msg, lines)
+			err = nil
+		} else if w.StderrFallback {
+			w.fallbackToStderr(string(msg))
+		}
 	}
 	w.Unlock()
 
+	if err == errWriteDegraded {
+		// reported once by the timed out write, not per message
+		return nil
+	}
+
 	return err
 }
 
-func (w *fileLogWriter) createLogFile() (*os.File, error) {
+// writeLocked writes the message terminated, and counts it for rotate, index and
+// chain when written. It must be called with w locked.
+func (w *fileLogWriter) writeLocked(msg []byte, lines int) error {
+	var sum string
+	if w.AuditChain {
+		// chained under the lock, so the chain follows the order of writes
+		msg, sum = w.chain(msg)
+	}
+
+	if err := w.write(msg); err != nil {
+		return err
+	}
+
+	if w.AuditChain {
+		w.chainAdvance(sum)
+	}
+	w.indexAdvance(w.maxSizeCurSize, msg)
+	w.maxLinesCurLines += lines
+	w.maxSizeCurSize += len(msg)
+
+	return nil
+}
+
+// terminate replaces the trailing "\n" added by the formatter with the line terminator
+func (w *fileLogWriter) terminate(msg []byte) []byte {
+	if w.LineTerminator == "\n" || bytes.HasSuffix(msg, w.terminator) {
+		return msg
+	}
+
+	msg = bytes.TrimSuffix(msg, []byte{'\n'})
+
+	terminated := make([]byte, 0, len(msg)+len(w.terminator))
+	terminated = append(terminated, msg...)
+
+	return append(terminated, w.terminator...)
+}
+
+func (w *fileLogWriter) openExpired() bool {
+	return w.MaxOpenAge > 0 && w.now().Sub(w.openedAt) >= w.MaxOpenAge
+}
+
+// max messages per second written to stderr by fallbackToStderr
+const stderrFallbackLimit = 10
+
+// fallbackToStderr write msg to stderr, it is throttled to avoid a flood
+// while the file keeps failing, e.g. disk is full.
+// It must be called with w locked.
+func (w *fileLogWriter) fallbackToStderr(msg string) {
+	now := w.now()
+	if now.Sub(w.fallbackWindow) >= time.Second {
+		if w.fallbackDropped > 0 {
+			_, _ = fmt.Fprintf(w.stderr, "%d %v FileLogWriter(%q): stderr fallback dropped %d messages\n", GoId(), now, w.Filename, w.fallbackDropped)
+		}
+		w.fallbackWindow = now
+		w.fallbackCount = 0
+		w.fallbackDropped = 0
+	}
+
+	if w.fallbackCount >= stderrFallbackLimit {
+		w.fallbackDropped++
+		return
+	}
+
+	w.fallbackCount++
+	_, _ = io.WriteString(w.stderr, msg)
+}
+
+// parsePerm parses octal permission like "0660", "660" or "0o660"
+func parsePerm(name, value string) (os.FileMode, error) {
+	s := strings.TrimSpace(value)
+	if strings.HasPrefix(s, "0o") || strings.HasPrefix(s, "0O") {
+		s = s[2:]
+	}
+
+	perm, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || len(s) == 0 || perm > 0777 {
+		return 0, fmt.Errorf("invalid %s %q: expected octal file mode in range 0000-0777, like \"0660\"", name, value)
+	}
+
+	return os.FileMode(perm), nil
+}
+
+func (w *fileLogWriter) createLogFile() (logFile, error) {
+	if w.Fd > 0 {
+		return openFd(w.Fd)
+	}
+
 	// Open the log file
-	perm, err := strconv.ParseInt(w.Perm, 8, 64)
+	perm, err := parsePerm("perm", w.Perm)
 	if err != nil {
 		return nil, err
 	}
 
-	fd, err := os.OpenFile(w.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(perm))
+	flag := os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	if w.Truncate && w.fileWriter == nil || w.truncateSlot {
+		// only the initial open or the slot reused, the reopen after rotate must keep the content
+		flag = os.O_WRONLY | os.O_TRUNC | os.O_CREATE
+	}
+
+	// create with the owner bits only, so the new file is never wider than perm
+	// before the chmod, then set perm through the fd which is not affected by umask
+	// and can't be redirected by replacing the path
+	fd, err := w.fs.OpenFile(w.activeFilename(), flag, perm&0600)
 	if err == nil {
-		// Make sure file perm is user set perm cause of `os.OpenFile` will obey umask
-		_ = os.Chmod(w.Filename, os.FileMode(perm))
+		_ = fd.Chmod(perm)
+		w.truncateSlot = false
 	}
 	return fd, err
 }
@@ -264,10 +728,18 @@ func (w *fileLogWriter) initFd() error {
 	}
 
 	w.maxSizeCurSize = int(fInfo.Size())
-	w.dailyOpenTime = fInfo.ModTime()
-	w.DailyOpenDate = w.dailyOpenTime.Day()
-	w.HourlyOpenDate = w.dailyOpenTime.Hour()
+	if fInfo.Size() > 0 {
+		w.setOpenTime(fInfo.ModTime())
+	} else {
+		// the new file is opened now by the clock, its mtime is the real time
+		w.setOpenTime(w.now())
+	}
 	w.maxLinesCurLines = 0
+	if w.Index {
+		if err = w.openIndex(fInfo.Size()); err != nil {
+			return err
+		}
+	}
 	if w.Rotate {
 		if fInfo.Size() > 0 && w.MaxLines > 0 {
 			count, err := w.lines()
@@ -281,23 +753,37 @@ func (w *fileLogWriter) initFd() error {
 }
 
 func (w *fileLogWriter) lines() (int, error) {
-	fd, err := os.Open(w.Filename)
+	fd, err := w.fs.Open(w.activeFilename())
 	if err != nil {
 		return 0, err
 	}
 	defer fd.Close()
 
-	buf := make([]byte, 32768) // 32k
+	// the terminator of more bytes could be split by the reads, so the
+	// bytes after the last one counted are kept for the next read
+	sep := len(w.terminator) - 1
+	buf := make([]byte, sep+32768) // 32k
 	count := 0
-	lineSep := []byte{'\n'}
+	kept := 0
 
 	for {
-		c, err := fd.Read(buf)
+		c, err := fd.Read(buf[kept:])
 		if err != nil && err != io.EOF {
 			return count, err
 		}
 
-		count += bytes.Count(buf[:c], lineSep)
+		data := buf[:kept+c]
+		count += bytes.Count(data, w.terminator)
+
+		end := 0
+		if last := bytes.LastIndex(data, w.terminator); last >= 0 {
+			end = last + len(w.terminator)
+		}
+		kept = len(data) - end
+		if kept > sep {
+			kept = sep
+		}
+		copy(buf, data[len(data)-kept:])
 
 		if err == io.EOF {
 			break
@@ -308,16 +794,22 @@ func (w *fileLogWriter) lines() (int, error) {
 }
 
 // DoRotate means it need to write file in new file.
-// new file name like xx.2013-01-01.log (daily) or xx.001.log (by line or size)
-func (w *fileLogWriter) doRotate(logTime time.Time) error {
-	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: doRotate logTime %v, %v\n", GoId(), time.Now(), logTime, w)
+// new file name like xx.2013-01-01.log (daily) or xx.001.log (by line or size),
+// or always xx.2013-01-01.001.log with AlwaysNumber.
+// forced rotates even if the file of the date exists, e.g. by cron.
+func (w *fileLogWriter) doRotate(logTime time.Time, forced bool) error {
+	w.diag.printf("doRotate", "%d %v rotate: doRotate logTime %v, %v", GoId(), w.now(), logTime, w)
+
+	if w.Slots > 0 {
+		return w.rotateSlot()
+	}
 
 	// file exists
 	// Find the next available number
 	maxSuffixNum := 999
 	num := 1
 	fName := ""
-	rotatePerm, err := strconv.ParseInt(w.RotatePerm, 8, 64)
+	rotatePerm, err := parsePerm("rotateperm", w.RotatePerm)
 	if err != nil {
 		return err
 	}
@@ -327,41 +819,53 @@ func (w *fileLogWriter) doRotate(logTime time.Time) error {
 		timeFormat = "2006-01-02-15"
 	}
 
-	_, err = os.Lstat(w.Filename)
+	_, err = w.fs.Lstat(w.Filename)
 	if err != nil {
 		//even if the file is not exist or other, we should RESTART the logger
 		return w.restartLogger(err)
 	}
 
+	dateKey := w.dailyOpenTime.Format(timeFormat)
+	if w.rotateNumKey == dateKey && w.rotateNum > 0 {
+		// resume from the last used number, fall back to scan from 1 while the cache is cold
+		num = w.rotateNum + 1
+	}
+	rotateNum := 0
+
 	for ; err == nil && num <= maxSuffixNum; num++ {
-		fName = fmt.Sprintf("%s.%s.%03d%s", w.fileNameOnly, w.dailyOpenTime.Format(timeFormat), num, w.suffix)
-		_, err = os.Lstat(fName)
+		rotateNum = num
+		fName = fmt.Sprintf("%s.%s.%03d%s", w.fileNameOnly, dateKey, num, w.suffix)
+		_, err = w.lstatRotated(fName)
 		// if file exist, try next
 		if err == nil {
-			_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: file exist %s, %v\n", GoId(), time.Now(), fName, w)
+			_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: file exist %s, %v\n", GoId(), w.now(), fName, w)
 			continue
 		}
 
-		// for the fist log, we don't want the num suffix
-		if num == 1 {
-			withoutNumName := fmt.Sprintf("%s.%s%s", w.fileNameOnly, w.dailyOpenTime.Format(timeFormat), w.suffix)
-			_, err = os.Lstat(withoutNumName)
+		// for the fist log, we don't want the num suffix unless AlwaysNumber,
+		// the encrypted file can't be renamed to the numbered later
+		if num == 1 && !w.AlwaysNumber && len(w.EncryptKey) == 0 {
+			withoutNumName := fmt.Sprintf("%s.%s%s", w.fileNameOnly, dateKey, w.suffix)
+			_, err = w.fs.Lstat(withoutNumName)
 			if err == nil {
 
-				if w.MaxLines == 0 && w.MaxSize == 0 {
+				if w.MaxLines == 0 && w.MaxSize == 0 && len(w.RotateCron) == 0 && !forced {
 					// skip rotate file, dest file exist and new message come. do nothing, write to current file.
-					_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: skip rotate file %s, %v\n", GoId(), time.Now(), withoutNumName, w)
+					_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: skip rotate file %s, %v\n", GoId(), w.now(), withoutNumName, w)
 					return w.restartLogger(err)
 				}
 
-				err = os.Rename(withoutNumName, fName)
+				err = w.fs.Rename(withoutNumName, fName)
 				if err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename %s to %s failed, %v\n", GoId(), time.Now(), withoutNumName, fName, err)
+					_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename %s to %s failed, %v\n", GoId(), w.now(), withoutNumName, fName, err)
+				} else if w.Index {
+					_ = w.fs.Rename(withoutNumName+indexSuffix, fName+indexSuffix)
 				}
-				_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename %s to %s ok, %v\n", GoId(), time.Now(), withoutNumName, fName, w)
+				_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename %s to %s ok, %v\n", GoId(), w.now(), withoutNumName, fName, w)
 			} else {
 				fName = withoutNumName
-				_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: use file name %s, %v\n", GoId(), time.Now(), fName, w)
+				rotateNum = 0
+				_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: use file name %s, %v\n", GoId(), w.now(), fName, w)
 				break
 			}
 		}
@@ -372,23 +876,115 @@ func (w *fileLogWriter) doRotate(logTime time.Time) error {
 		return fmt.Errorf("rotate: Cannot find free log number to rename %s", w.Filename)
 	}
 
-	// close fileWriter before rename
+	// close fileWriter before rename, the coalesced bytes belong to it
+	w.flushPending("rotate")
 	w.fileWriter.Close()
 
-	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename log %s to %s ok, %v\n", GoId(), time.Now(), w.Filename, fName, w)
+	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: Rename log %s to %s ok, %v\n", GoId(), w.now(), w.Filename, fName, w)
 
 	// Rename the file to its new found name
 	// even if occurs error,we MUST guarantee to restart new logger
-	err = os.Rename(w.Filename, fName)
+	err = w.fs.Rename(w.Filename, fName)
 	if err != nil {
 		return w.restartLogger(err)
 	}
 
-	err = os.Chmod(fName, os.FileMode(rotatePerm))
+	w.rotateNumKey = dateKey
+	w.rotateNum = rotateNum
+
+	err = w.fs.Chmod(fName, rotatePerm)
+
+	if len(w.EncryptKey) > 0 {
+		w.encrypting.Add(1)
+		go func() {
+			defer w.encrypting.Done()
+			w.encryptRotated(fName, rotatePerm)
+		}()
+	}
+
+	if w.indexWriter != nil {
+		// the index follows its log file, the new file gets a new index
+		_ = w.indexWriter.Close()
+		w.indexWriter = nil
+		if w.fs.Rename(w.Filename+indexSuffix, fName+indexSuffix) == nil {
+			_ = w.fs.Chmod(fName+indexSuffix, rotatePerm)
+		}
+	}
 
 	return w.restartLogger(err)
 }
 
+// cronRotate rotate the file at every scheduled time, regardless of the size and lines
+func (w *fileLogWriter) cronRotate(schedule *cronSchedule) {
+	for {
+		now := w.now()
+		next := schedule.next(now)
+		if next.IsZero() {
+			return
+		}
+
+		c, stop := w.newTimer(next.Sub(now))
+		select {
+		case <-c:
+		case <-w.cronStop:
+			stop()
+			return
+		}
+
+		// the timer is by the real time, the clock of writer may be frozen or
+		// behind, e.g. by SetClock, wait until it reaches the scheduled time
+		if w.now().Before(next) {
+			continue
+		}
+
+		w.Lock()
+		_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: cronRotate %s at %v, %v\n", GoId(), w.now(), w.RotateCron, next, w)
+		if err := w.doRotate(next, true); err != nil {
+			w.diag.printf("cronRotate:"+err.Error(), "%d %v cronRotate FileLogWriter(%q): %s", GoId(), next, w.Filename, err)
+		}
+		w.Unlock()
+	}
+}
+
+// flushAndRotate syncs the file and rotates it under lock, so the messages
+// written before are all in the rotated file and the later in the new file.
+// The file written nothing since opened is synced only, so the writers shared
+// by several hooks are not rotated twice.
+func (w *fileLogWriter) flushAndRotate() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.inflight != nil {
+		// the timed out write would straddle the boundary
+		select {
+		case <-w.inflight:
+			w.inflight = nil
+		case <-time.After(w.WriteTimeout):
+			return errWriteDegraded
+		}
+	}
+
+	w.flushPending("flush")
+	if err := w.fileWriter.Sync(); err != nil {
+		return err
+	}
+
+	if w.Fd > 0 || w.maxSizeCurSize == 0 {
+		return nil
+	}
+
+	return w.doRotate(w.now(), true)
+}
+
+// lstatRotated is Lstat of the rotated name, or of its encrypted file when it is encrypted
+func (w *fileLogWriter) lstatRotated(name string) (os.FileInfo, error) {
+	info, err := w.fs.Lstat(name)
+	if err != nil && len(w.EncryptKey) > 0 {
+		return w.fs.Lstat(name + encSuffix)
+	}
+	return info, err
+}
+
 func (w *fileLogWriter) restartLogger(err error) error {
 
 	startLoggerErr := w.startLogger()
@@ -406,8 +1002,19 @@ func (w *fileLogWriter) restartLogger(err error) error {
 }
 
 func (w *fileLogWriter) deleteOldLog() {
+	if w.Slots > 0 {
+		// the slots are the bound of files, none is old
+		return
+	}
+
 	dir := filepath.Dir(w.Filename)
-	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) (returnErr error) {
+
+	// the rotated files kept by age, pruned by count at last
+	var kept []rotatedFile
+	// the rotated file being encrypted and its encrypted file are counted once
+	seen := make(map[string]bool)
+
+	_ = w.fs.Walk(dir, func(path string, info os.FileInfo, err error) (returnErr error) {
 		defer func() {
 			if r := recover(); r != nil {
 				_, _ = fmt.Fprintf(os.Stderr, "Unable to delete old log '%s', error: %v\n", path, r)
@@ -418,25 +1025,110 @@ func (w *fileLogWriter) deleteOldLog() {
 			return
 		}
 
-		if !info.IsDir() && info.ModTime().Add(24 * time.Hour * time.Duration(w.MaxDays)).Before(time.Now()) {
-			if strings.HasPrefix(filepath.Base(path), filepath.Base(w.fileNameOnly)) &&
-				strings.HasSuffix(filepath.Base(path), w.suffix) {
-				_ = os.Remove(path)
+		// only the rotated files in the log dir, never descend into sub dirs
+		if info.IsDir() {
+			if path != dir {
+				returnErr = filepath.SkipDir
 			}
+			return
+		}
+
+		// skip the active file, and the files being written by others like temp or link
+		if !info.Mode().IsRegular() || filepath.Clean(path) == filepath.Clean(w.Filename) {
+			return
+		}
+
+		// the prefix ends at the dot, so app.12.log never matches the files of app.123.log
+		if !strings.HasPrefix(filepath.Base(path), filepath.Base(w.fileNameOnly)+".") ||
+			!strings.HasSuffix(strings.TrimSuffix(filepath.Base(path), encSuffix), w.suffix) {
+			return
+		}
+
+		name := strings.TrimSuffix(path, encSuffix)
+		if seen[name] {
+			return
 		}
+		seen[name] = true
+
+		if info.ModTime().Add(24 * time.Hour * time.Duration(w.MaxDays)).Before(w.now()) {
+			w.removeRotated(name)
+			return
+		}
+
+		kept = append(kept, rotatedFile{name: name, modTime: info.ModTime()})
 		return
 	})
+
+	if w.MaxFiles <= 0 || len(kept) <= w.MaxFiles {
+		return
+	}
+
+	// newest first, the same mtime is ordered by name which carries the date and number
+	sort.Slice(kept, func(i, j int) bool {
+		if !kept[i].modTime.Equal(kept[j].modTime) {
+			return kept[i].modTime.After(kept[j].modTime)
+		}
+		return kept[i].name > kept[j].name
+	})
+
+	for _, f := range kept[w.MaxFiles:] {
+		w.removeRotated(f.name)
+	}
+}
+
+// rotatedFile is the rotated file by the name before encrypted
+type rotatedFile struct {
+	name    string
+	modTime time.Time
+}
+
+// removeRotated removes the rotated file, its encrypted file and its index,
+// the file may be in encrypting, so both of them are removed
+func (w *fileLogWriter) removeRotated(name string) {
+	for _, path := range []string{name, name + encSuffix} {
+		if err := w.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			_, _ = fmt.Fprintf(os.Stderr, "Unable to delete old log '%s', error: %v\n", path, err)
+		}
+	}
+	_ = w.fs.Remove(name + indexSuffix)
 }
 
 // Destroy close the file description, close file writer.
 func (w *fileLogWriter) Destroy() {
+	w.Lock()
+	if w.readOnly != nil {
+		close(w.readOnly.stop)
+		w.readOnly = nil
+	}
+	w.Unlock()
+	// the background goroutines are stopped once, Destroy could be called again
+	w.stopOnce.Do(func() {
+		w.guard.close()
+		if w.cronStop != nil {
+			close(w.cronStop)
+		}
+		if w.markerStop != nil {
+			close(w.markerStop)
+		}
+	})
+	if w.indexWriter != nil {
+		w.indexWriter.Close()
+	}
+	w.Lock()
+	w.flushPending("destroy")
+	w.Unlock()
 	w.fileWriter.Close()
+	// never leave the partial encrypted files behind
+	w.encrypting.Wait()
 }
 
 // Flush flush file logger.
-// there are no buffering messages in file logger in memory.
-// flush file means sync file from disk.
+// the messages coalesced in memory are written, then the file is synced to disk.
 func (w *fileLogWriter) Flush() {
+	w.Lock()
+	defer w.Unlock()
+
+	w.flushPending("flush")
 	_ = w.fileWriter.Sync()
 }
 
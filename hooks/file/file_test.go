@@ -0,0 +1,631 @@
+package logrus_file
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeClock is the clock of writer moved by the tests
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock(t time.Time) *fakeClock {
+	return &fakeClock{t: t}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// startWriter starts the writer of jsonConfig by the defaults of newFileWriter, it is
+// not shared by the config like the writers of newFileWriter, setup runs before the start
+func startWriter(t testing.TB, jsonConfig string, setup ...func(w *fileLogWriter)) *fileLogWriter {
+	t.Helper()
+
+	w := newDefaultWriter()
+
+	for _, f := range setup {
+		f(w)
+	}
+
+	if err := w.Init(jsonConfig); err != nil {
+		t.Fatalf("init: %s", err)
+	}
+	t.Cleanup(w.Destroy)
+
+	return w
+}
+
+// newMemWriter starts the writer of jsonConfig on fs by the clock, it is not shared
+// by filename like the writers of newFileWriter, setup runs before the start
+func newMemWriter(t testing.TB, fs *memFS, clock *fakeClock, jsonConfig string, setup ...func(w *fileLogWriter)) *fileLogWriter {
+	t.Helper()
+
+	fs.now = clock.Now
+
+	w := newDefaultWriter()
+	w.fs = fs
+	w.now = clock.Now
+	w.sleep = func(time.Duration) {}
+
+	for _, f := range setup {
+		f(w)
+	}
+
+	if err := w.Init(jsonConfig); err != nil {
+		t.Fatalf("init: %s", err)
+	}
+	t.Cleanup(w.Destroy)
+
+	return w
+}
+
+func writeLines(t *testing.T, w *fileLogWriter, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if err := w.WriteMsg(w.now(), line+"\n"); err != nil {
+			t.Fatalf("write %q: %s", line, err)
+		}
+	}
+}
+
+func readMem(t *testing.T, fs *memFS, name string) string {
+	t.Helper()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	d, exist := fs.files[name]
+	if !exist {
+		t.Fatalf("%s not exist, files: %v", name, fs.namesLocked())
+	}
+	return string(d.data)
+}
+
+func (m *memFS) namesLocked() []string {
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	return names
+}
+
+// touchMem creates the empty file modified at modTime
+func touchMem(fs *memFS, name string, modTime time.Time) {
+	f, _ := fs.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0440)
+	_ = f.Close()
+	fs.Chtime(name, modTime)
+}
+
+func assertNames(t *testing.T, fs *memFS, expected ...string) {
+	t.Helper()
+
+	if names := fs.Names(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("files %v, expected %v", names, expected)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+var day1 = time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+
+func TestRotateDaily(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":true,"hourly":false,"maxlines":0,"maxsize":0}`)
+
+	writeLines(t, w, "a", "b")
+	clock.Add(24 * time.Hour)
+	writeLines(t, w, "c")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "a\nb\n" {
+		t.Fatalf("rotated %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "c\n" {
+		t.Fatalf("active %q", s)
+	}
+}
+
+func TestRotateHourly(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":true,"maxlines":0,"maxsize":0}`)
+
+	writeLines(t, w, "a")
+	clock.Add(time.Hour)
+	writeLines(t, w, "b")
+	clock.Add(time.Hour)
+	writeLines(t, w, "c")
+
+	assertNames(t, fs, "logs/app.2024-01-01-10.log", "logs/app.2024-01-01-11.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01-11.log"); s != "b\n" {
+		t.Fatalf("rotated %q", s)
+	}
+}
+
+func TestRotateMaxLines(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0}`)
+
+	writeLines(t, w, "1", "2", "3", "4", "5")
+
+	// the first rotated file is renamed to .001 when the second comes
+	assertNames(t, fs, "logs/app.2024-01-01.001.log", "logs/app.2024-01-01.002.log", "logs/app.log")
+	for name, expected := range map[string]string{
+		"logs/app.2024-01-01.001.log": "1\n2\n",
+		"logs/app.2024-01-01.002.log": "3\n4\n",
+		"logs/app.log":                "5\n",
+	} {
+		if s := readMem(t, fs, name); s != expected {
+			t.Fatalf("%s is %q, expected %q", name, s, expected)
+		}
+	}
+}
+
+func TestRotateMaxSize(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":0,"maxsize":8}`)
+
+	writeLines(t, w, "aaaa", "bbbb", "cccc")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "aaaa\nbbbb\n" {
+		t.Fatalf("rotated %q", s)
+	}
+}
+
+func TestRotateExactBoundary(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":0,"maxsize":5}`)
+
+	// the file reaching maxsize exactly is rotated by the next write, never split
+	writeLines(t, w, "aaaa")
+	assertNames(t, fs, "logs/app.log")
+
+	writeLines(t, w, "b")
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+}
+
+func TestDeleteOldLogMaxDays(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","maxdays":2}`)
+
+	for i, age := range []int{1, 3, 5} {
+		name := fmt.Sprintf("logs/app.2023-12-2%d.log", i)
+		touchMem(fs, name, day1.Add(-time.Duration(age)*24*time.Hour))
+	}
+	// not a rotated file of app.log
+	touchMem(fs, "logs/other.log", day1.Add(-30*24*time.Hour))
+
+	w.deleteOldLog()
+
+	assertNames(t, fs, "logs/app.2023-12-20.log", "logs/app.log", "logs/other.log")
+}
+
+func TestDeleteOldLogMaxFiles(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","maxdays":30,"maxfiles":10}`)
+
+	var expected []string
+	for i := 1; i <= 15; i++ {
+		name := fmt.Sprintf("logs/app.2024-01-01.%03d.log", i)
+		touchMem(fs, name, day1.Add(-time.Duration(16-i)*time.Hour))
+		if i > 5 {
+			expected = append(expected, name)
+		}
+	}
+	expected = append(expected, "logs/app.log")
+
+	w.deleteOldLog()
+
+	assertNames(t, fs, expected...)
+}
+
+func TestDeleteOldLogPrefix(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.12.log","maxdays":1}`)
+
+	touchMem(fs, "logs/app.123.2023-12-01.log", day1.Add(-30*24*time.Hour))
+
+	w.deleteOldLog()
+
+	if names := fs.Names(); !strings.Contains(strings.Join(names, " "), "app.123.2023-12-01.log") {
+		t.Fatalf("the file of another log is deleted: %v", names)
+	}
+}
+
+// benchmarkRotate rotates among 100 numbered files of the day, cold drops
+// the cached number before every rotate like a restarted process
+func benchmarkRotate(b *testing.B, cold bool) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(b, fs, clock, `{"filename":"logs/app.log","daily":true,"hourly":false,"maxlines":0,"maxsize":0}`)
+
+	for i := 1; i <= 100; i++ {
+		touchMem(fs, fmt.Sprintf("logs/app.2024-01-01.%03d.log", i), day1)
+	}
+
+	// the scan reports every existing file
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	w.Lock()
+	defer w.Unlock()
+
+	w.rotateNumKey, w.rotateNum = "2024-01-01", 100
+	fs.lstats = 0
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if cold {
+			w.rotateNum = 0
+		}
+		if err := w.doRotate(clock.Now(), true); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		_ = fs.Remove("logs/app.2024-01-01.101.log")
+		w.rotateNum = 100
+		b.StartTimer()
+	}
+
+	b.ReportMetric(float64(fs.lstats)/float64(b.N), "lstats/op")
+}
+
+func BenchmarkRotateWarmNumber(b *testing.B) {
+	benchmarkRotate(b, false)
+}
+
+func BenchmarkRotateColdNumber(b *testing.B) {
+	benchmarkRotate(b, true)
+}
+
+func TestRotateResumeNumber(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"filename":"` + filepath.ToSlash(filepath.Join(dir, "app.log")) + `","daily":true,"hourly":false,"maxlines":1}`
+	w := startWriter(t, config)
+
+	writeLines(t, w, "a", "b", "c")
+	w.Destroy()
+
+	// the restarted writer scans the existing numbers once
+	w = startWriter(t, config)
+	date := w.dailyOpenTime.Format("2006-01-02")
+	writeLines(t, w, "d")
+	if w.rotateNumKey != date || w.rotateNum != 3 {
+		t.Fatalf("cached number %s %d after the scan", w.rotateNumKey, w.rotateNum)
+	}
+
+	// the warm rotate resumes from the cached number
+	writeLines(t, w, "e")
+	if w.rotateNum != 4 {
+		t.Fatalf("cached number %d after the warm rotate", w.rotateNum)
+	}
+
+	for i, expected := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		if s := readFile(t, filepath.Join(dir, fmt.Sprintf("app.%s.%03d.log", date, i+1))); s != expected {
+			t.Fatalf("%03d is %q, expected %q", i+1, s, expected)
+		}
+	}
+}
+
+func TestParsePerm(t *testing.T) {
+	for value, expected := range map[string]os.FileMode{
+		"0660":  0660,
+		"660":   0660,
+		"0o640": 0640,
+		"0O600": 0600,
+		" 0440": 0440,
+		"0":     0,
+	} {
+		perm, err := parsePerm("perm", value)
+		if err != nil || perm != expected {
+			t.Fatalf("%q parsed %v %v, expected %v", value, perm, err, expected)
+		}
+	}
+
+	for _, value := range []string{"", "0o", "0x660", "rw-rw----", "0680", "01777"} {
+		_, err := parsePerm("perm", value)
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%q", value)) {
+			t.Fatalf("%q: %v", value, err)
+		}
+	}
+}
+
+func TestInitInvalidPerm(t *testing.T) {
+	for _, config := range []string{
+		`{"filename":"logs/app.log","perm":"0o999"}`,
+		`{"filename":"logs/app.log","rotateperm":"rw"}`,
+	} {
+		// the perms are checked before the file is opened
+		w := &fileLogWriter{Perm: "0660", RotatePerm: "0440"}
+
+		err := w.Init(config)
+		if err == nil || !strings.Contains(err.Error(), "perm") {
+			t.Fatalf("%s: %v", config, err)
+		}
+	}
+}
+
+func TestTruncateOnOpen(t *testing.T) {
+	for _, truncate := range []bool{false, true} {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "app.log")
+		if err := os.WriteFile(filename, []byte("old1\nold2\n"), 0660); err != nil {
+			t.Fatal(err)
+		}
+
+		w := startWriter(t, fmt.Sprintf(`{"filename":%q,"daily":false,"hourly":false,"maxlines":3,"truncate":%v}`, filepath.ToSlash(filename), truncate))
+
+		writeLines(t, w, "new1", "new2")
+
+		if truncate {
+			// the counters restart from the truncated file, the rotate reopen appends
+			if rotated := rotatedFiles(t, dir); len(rotated) != 0 {
+				t.Fatalf("rotated %v", rotated)
+			}
+			writeLines(t, w, "new3", "new4")
+			if rotated := rotatedFiles(t, dir); len(rotated) != 1 || readFile(t, rotated[0]) != "new1\nnew2\nnew3\n" {
+				t.Fatalf("rotated %v", rotated)
+			}
+			if s := readFile(t, filename); s != "new4\n" {
+				t.Fatalf("active %q", s)
+			}
+		} else {
+			if rotated := rotatedFiles(t, dir); len(rotated) != 1 || readFile(t, rotated[0]) != "old1\nold2\nnew1\n" {
+				t.Fatalf("rotated %v", rotated)
+			}
+			if s := readFile(t, filename); s != "new2\n" {
+				t.Fatalf("active %q", s)
+			}
+		}
+	}
+}
+
+func TestRotateEncryptMaxLines(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	// the key is "0123456789abcdef"
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":true,"hourly":false,"maxlines":2,"maxsize":0,"maxfiles":2,"encrypt_key":"MDEyMzQ1Njc4OWFiY2RlZg=="}`)
+
+	writeLines(t, w, "1", "2", "3", "4", "5", "6", "7", "8", "9")
+
+	w.encrypting.Wait()
+	w.deleteOldLog()
+
+	// the plain file in encrypting and its encrypted file counted once,
+	// the counters restart at every fresh file
+	assertNames(t, fs, "logs/app.2024-01-01.003.log.gz.enc", "logs/app.2024-01-01.004.log.gz.enc", "logs/app.log")
+	if w.maxLinesCurLines != 1 {
+		t.Fatalf("%d lines of the active file", w.maxLinesCurLines)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "9\n" {
+		t.Fatalf("active %q", s)
+	}
+
+	for name, expected := range map[string]string{
+		"logs/app.2024-01-01.003.log.gz.enc": "5\n6\n",
+		"logs/app.2024-01-01.004.log.gz.enc": "7\n8\n",
+	} {
+		plain := &bytes.Buffer{}
+		if err := Decrypt(plain, strings.NewReader(readMem(t, fs, name)), []byte("0123456789abcdef")); err != nil {
+			t.Fatalf("decrypt %s: %s", name, err)
+		}
+		if plain.String() != expected {
+			t.Fatalf("%s decrypted %q, expected %q", name, plain, expected)
+		}
+	}
+}
+func TestMaxOpenAgeReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	clock := newFakeClock(day1)
+	w := startWriter(t, `{"filename":"`+filepath.ToSlash(filename)+`","daily":false,"hourly":false,"max_open_age":60000000000}`,
+		func(w *fileLogWriter) { w.now = clock.Now })
+
+	writeLines(t, w, "a")
+
+	// the file is moved away by others, the open fd keeps writing into it
+	if err := os.Rename(filename, filename+".shipped"); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(59 * time.Second)
+	writeLines(t, w, "b")
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatalf("reopened before max-open-age: %v", err)
+	}
+
+	clock.Add(time.Second)
+	writeLines(t, w, "c")
+
+	if s := readFile(t, filename+".shipped"); s != "a\nb\n" {
+		t.Fatalf("shipped %q", s)
+	}
+	if s := readFile(t, filename); s != "c\n" {
+		t.Fatalf("reopened %q", s)
+	}
+}
+
+func TestSharedWriterBySameFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "shared.log")
+
+	w1, err := newFileWriter(fmt.Sprintf(`{"filename":%q,"maxsize":1024}`, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same config of the file by a different path
+	w2, err := newFileWriter(fmt.Sprintf(`{"filename":%q,"maxsize":1024}`, dir+"/./shared.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w1 != w2 {
+		t.Fatal("the same config of the file gets different writers")
+	}
+
+	// another config of the same file would rotate it by its own maxsize
+	if _, err = newFileWriter(fmt.Sprintf(`{"filename":%q,"maxsize":2048}`, dir+"/./shared.log")); err == nil ||
+		!strings.Contains(err.Error(), "written by another config") {
+		t.Fatalf("another config of the same file: %v", err)
+	}
+
+	other, err := newFileWriter(fmt.Sprintf(`{"filename":%q,"maxsize":2048}`, filepath.Join(dir, "other.log")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == w1 {
+		t.Fatal("the different files share the writer")
+	}
+}
+
+func TestSharedWriterConcurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "concurrent.log")
+	jsonConfig := fmt.Sprintf(`{"filename":%q,"maxsize":1024}`, filename)
+
+	// the loggers created by Logger(name) from several goroutines
+	writers := make([]*fileLogWriter, 8)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w, err := newFileWriter(jsonConfig)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			writers[i] = w
+		}(i)
+	}
+	wg.Wait()
+
+	for _, w := range writers {
+		if w != writers[0] {
+			t.Fatal("the same config gets different writers")
+		}
+	}
+}
+
+func TestWriteDirectPath(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","level":6,"rotate":false}`)
+
+	fs.lstats = 0
+	hook := &FileHook{W: w}
+	entry := logrus.NewEntry(logrus.New())
+	entry.Logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	entry.Level = logrus.InfoLevel
+	entry.Message = "direct"
+
+	if err := hook.Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "raw")
+
+	if got := readMem(t, fs, "logs/app.log"); got != "level=info msg=direct\nraw\n" {
+		t.Fatalf("content %q", got)
+	}
+	if fs.lstats != 0 {
+		t.Fatalf("the writes without rotation stat the file %d times", fs.lstats)
+	}
+}
+
+func benchmarkFileHook(b *testing.B, jsonConfig string) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(b, fs, clock, jsonConfig)
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Formatter = &logrus.JSONFormatter{}
+	logger.Hooks.Add(&FileHook{W: w})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.WithField("i", i).Info("benchmark")
+
+		// the memory file grows unbounded otherwise
+		if i%1024 == 0 {
+			b.StopTimer()
+			fs.mu.Lock()
+			fs.files["logs/app.log"].data = nil
+			fs.mu.Unlock()
+			b.StartTimer()
+		}
+	}
+}
+
+func BenchmarkFileHookDirect(b *testing.B) {
+	benchmarkFileHook(b, `{"filename":"logs/app.log","level":6,"rotate":false}`)
+}
+
+func BenchmarkFileHookStripColors(b *testing.B) {
+	benchmarkFileHook(b, `{"filename":"logs/app.log","level":6,"rotate":false,"stripColors":true}`)
+}
+
+func BenchmarkFileHookRotate(b *testing.B) {
+	benchmarkFileHook(b, `{"filename":"logs/app.log","level":6,"rotate":true,"daily":true,"hourly":false,"maxlines":0,"maxsize":0}`)
+}
+
+func TestDeleteOldLogMaxFilesAndMaxDays(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","maxdays":2,"maxfiles":2}`)
+
+	// the active file is never pruned, however old it is
+	fs.Chtime("logs/app.log", day1.Add(-30*24*time.Hour))
+
+	// older than maxdays
+	touchMem(fs, "logs/app.2023-12-25.log", day1.Add(-7*24*time.Hour))
+	// the same mtime is ordered by the number
+	for i := 1; i <= 3; i++ {
+		touchMem(fs, fmt.Sprintf("logs/app.2024-01-01.%03d.log", i), day1)
+	}
+
+	w.deleteOldLog()
+
+	assertNames(t, fs, "logs/app.2024-01-01.002.log", "logs/app.2024-01-01.003.log", "logs/app.log")
+}
@@ -0,0 +1,65 @@
+package logrus_file
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// logFile is the opened log file
+type logFile interface {
+	io.ReadWriteCloser
+	Stat() (os.FileInfo, error)
+	Chmod(mode os.FileMode) error
+	Sync() error
+}
+
+// fileSystem is the minimal filesystem used by fileLogWriter, the default is
+// backed by os, the tests inject memFS to rotate without touching the disk
+type fileSystem interface {
+	Open(name string) (logFile, error)
+	OpenFile(name string, flag int, perm os.FileMode) (logFile, error)
+	Lstat(name string) (os.FileInfo, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Chmod(name string, mode os.FileMode) error
+	Walk(root string, fn filepath.WalkFunc) error
+}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (logFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	f, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (osFS) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (osFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (osFS) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
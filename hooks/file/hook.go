@@ -6,6 +6,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gogap/config"
@@ -14,45 +17,155 @@ import (
 
 type fileHookConfig struct {
 	Filename    string `json:"filename"`
+	Fd          int    `json:"fd"`
 	MaxLines    int64  `json:"maxLines"`
 	MaxSize     int64  `json:"maxsize"`
 	StripColors bool   `json:"stripColors"`
+	StripMaxLen int    `json:"strip_max_len"`
 	Daily       bool   `json:"daily"`
 	Hourly      bool   `json:"hourly"`
 	MaxDays     int64  `json:"maxDays"`
+	MaxFiles    int    `json:"maxfiles"`
 	Rotate      bool   `json:"rotate"`
 	Perm        string `json:"perm"`
 	RotatePerm  string `json:"rotateperm"`
 	Level       int32  `json:"level"`
+
+	StderrFallback bool `json:"stderr_fallback"`
+
+	MinFreeBytes   int64         `json:"min_free_bytes"`
+	MinFreePercent float64       `json:"min_free_percent"`
+	CheckInterval  time.Duration `json:"check_interval"`
+
+	RotateCron string `json:"rotate_cron"`
+
+	Truncate bool `json:"truncate"`
+
+	OpenRetries int           `json:"open_retries"`
+	OpenBackoff time.Duration `json:"open_backoff"`
+
+	MaxOpenAge time.Duration `json:"max_open_age"`
+
+	WriteTimeout time.Duration `json:"write_timeout"`
+
+	CountBlocksAsOne bool `json:"count_blocks_as_one"`
+
+	MarkerInterval time.Duration `json:"marker_interval"`
+
+	LineTerminator string `json:"line_terminator"`
+
+	Index      bool `json:"index"`
+	IndexEvery int  `json:"index_every"`
+
+	AuditChain bool   `json:"audit_chain"`
+	AuditHash  string `json:"audit_hash"`
+
+	AlwaysNumber bool `json:"always_number"`
+
+	RotateMode string `json:"rotate_mode"`
+	Slots      int    `json:"slots"`
+
+	EncryptKey string `json:"encrypt_key"`
+
+	ReadOnlyBuffer int           `json:"readonly_buffer"`
+	ReadOnlyProbe  time.Duration `json:"readonly_probe"`
+
+	CoalesceBytes    int           `json:"coalesce_bytes"`
+	CoalesceInterval time.Duration `json:"coalesce_interval"`
 }
 
 func init() {
 	logrus_mate.RegisterHook("file", NewFileHook)
 }
 
+// getpid is replaced by the tests to simulate the processes
+var getpid = os.Getpid
+
+// pidFilename inserts the pid before the extension, logs/app.log is logs/app.<pid>.log
+func pidFilename(filename string, pid int) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + strconv.Itoa(pid) + ext
+}
+
 func NewFileHook(config config.Configuration) (hook logrus.Hook, err error) {
 
 	filename := config.GetString("filename", "logs/logrus.log")
 
-	dir := filepath.Dir(filename)
+	// every process writes and rotates its own file, e.g. the pre-forked workers
+	if config.GetBoolean("pid-suffix", false) {
+		filename = pidFilename(filename, getpid())
+	}
 
-	err = os.MkdirAll(dir, 0755)
-	if err != nil {
-		return
+	fd := int(config.GetInt32("fd", 0))
+	if fd > 0 {
+		// the filename identifies the writer only
+		filename = fmt.Sprintf("fd%d", fd)
+	} else {
+		dir := filepath.Dir(filename)
+
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return
+		}
 	}
 
 	hookConf := fileHookConfig{
 		Filename:    filename,
+		Fd:          fd,
 		StripColors: config.GetBoolean("strip-colors", true),
+		StripMaxLen: int(config.GetInt32("strip-max-len", defaultStripMaxLen)),
 		Daily:       config.GetBoolean("daily", true),
 		Hourly:      config.GetBoolean("hourly", true),
 		MaxDays:     config.GetInt64("max-days", 7),
+		MaxFiles:    int(config.GetInt32("max-files", 0)),
 		Rotate:      config.GetBoolean("rotate", true),
 		MaxLines:    config.GetInt64("max-lines", 10000),
 		MaxSize:     config.GetInt64("max-size", 1024),
 		RotatePerm:  config.GetString("rotate-perm", "0440"),
 		Perm:        config.GetString("perm", "0660"),
 		Level:       config.GetInt32("level"),
+
+		StderrFallback: config.GetBoolean("stderr-fallback", false),
+
+		MinFreeBytes:   config.GetInt64("min-free-bytes"),
+		MinFreePercent: config.GetFloat64("min-free-percent"),
+		CheckInterval:  config.GetTimeDuration("check-interval", 10*time.Second),
+
+		RotateCron: config.GetString("rotate-cron"),
+
+		Truncate: config.GetBoolean("truncate", false),
+
+		OpenRetries: int(config.GetInt32("open-retries", 0)),
+		OpenBackoff: config.GetTimeDuration("open-backoff", defaultOpenBackoff),
+
+		MaxOpenAge: config.GetTimeDuration("max-open-age", 0),
+
+		WriteTimeout: config.GetTimeDuration("write-timeout", 0),
+
+		CountBlocksAsOne: config.GetBoolean("count-blocks-as-one", false),
+
+		MarkerInterval: config.GetTimeDuration("marker-interval", 0),
+
+		LineTerminator: config.GetString("line-terminator", "\n"),
+
+		Index:      config.GetBoolean("index", false),
+		IndexEvery: int(config.GetInt32("index-every", 1000)),
+
+		AuditChain: config.GetBoolean("audit-chain", false),
+		AuditHash:  config.GetString("audit-hash", "sha256"),
+
+		AlwaysNumber: config.GetBoolean("always-number", false),
+
+		RotateMode: config.GetString("rotate-mode"),
+		Slots:      int(config.GetInt32("slots", 0)),
+
+		EncryptKey: config.GetString("encrypt-key"),
+
+		ReadOnlyBuffer: int(config.GetInt32("readonly-buffer", 0)),
+		ReadOnlyProbe:  config.GetTimeDuration("readonly-probe", defaultReadOnlyProbe),
+
+		CoalesceBytes:    int(config.GetInt32("coalesce-bytes", 0)),
+		CoalesceInterval: config.GetTimeDuration("coalesce-interval", defaultCoalesceInterval),
 	}
 
 	confData, err := json.Marshal(hookConf)
@@ -60,8 +173,8 @@ func NewFileHook(config config.Configuration) (hook logrus.Hook, err error) {
 		return
 	}
 
-	w := newFileWriter(string(confData))
-	if w == nil {
+	w, err := newFileWriter(string(confData))
+	if err != nil {
 		return
 	}
 
@@ -72,22 +185,77 @@ func NewFileHook(config config.Configuration) (hook logrus.Hook, err error) {
 
 type FileHook struct {
 	W *fileLogWriter
+
+	closeOnce sync.Once
 }
 
 func (p *FileHook) Fire(entry *logrus.Entry) (err error) {
 	if p.W.Level < int(entry.Level) {
 		return nil
 	}
-	message, err := entry.String()
+	message, err := entry.Bytes()
 
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Unable to read entry, %v", err)
 		return err
 	}
 
-	now := time.Now()
+	return p.W.writeBytes(p.W.now(), message, 1)
+}
+
+// WriteBlock writes the header and the tab indented lines into the file at once,
+// e.g. a debug dump, the lines of other entries never interleave it
+func (p *FileHook) WriteBlock(header string, lines ...string) error {
+	return p.W.WriteBlock(p.W.now(), header, lines)
+}
+
+// SetClock replaces the clock of the file, e.g. a frozen time for tests, it is used for
+// the time headers, rotate, max-days, cron, markers and diagnostics. The writer of the
+// same file is shared by the hooks, so they all get the clock. Set it before the logging.
+func (p *FileHook) SetClock(now func() time.Time) {
+	p.W.Lock()
+	defer p.W.Unlock()
+
+	p.W.now = now
+	if p.W.maxSizeCurSize == 0 {
+		p.W.setOpenTime(now())
+	}
+}
+
+// Flush syncs the file to disk
+func (p *FileHook) Flush() error {
+	p.W.Lock()
+	defer p.W.Unlock()
+
+	p.W.flushPending("flush")
+	return p.W.fileWriter.Sync()
+}
 
-	return p.W.WriteMsg(now, message)
+// FlushAndRotate syncs the file then rotates it at once, the entries written
+// before are all in the rotated file, e.g. for the archival jobs
+func (p *FileHook) FlushAndRotate() error {
+	return p.W.flushAndRotate()
+}
+
+// Degraded reports whether the write timed out by write-timeout is still in flight
+func (p *FileHook) Degraded() bool {
+	return p.W.degraded()
+}
+
+// Dropped returns the count of messages dropped by low disk space
+func (p *FileHook) Dropped() uint64 {
+	return p.W.guard.Dropped()
+}
+
+// Close flushes the file, then the last hook of the writer stops its goroutines, e.g.
+// the markers, cron rotation, disk guard and read-only probe, and closes the file.
+// CloseWithTimeout of the mate closes it.
+func (p *FileHook) Close() (err error) {
+	p.closeOnce.Do(func() {
+		err = p.Flush()
+		p.W.release()
+	})
+	return
 }
 
 func (p *FileHook) Levels() []logrus.Level {
@@ -0,0 +1,73 @@
+package logrus_file
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// indexSuffix is appended to the log filename for its sidecar index, the
+// rotated file keeps its index by the renamed name
+const indexSuffix = ".idx"
+
+// openIndex opens the sidecar index of the current file, the lines are
+// "<line> <offset>\n", the line numbered from 0 starts at the byte offset.
+// The count of existing lines is read when the log file is reopened.
+func (w *fileLogWriter) openIndex(size int64) error {
+	if w.indexWriter != nil {
+		_ = w.indexWriter.Close()
+		w.indexWriter = nil
+	}
+
+	perm, err := parsePerm("perm", w.Perm)
+	if err != nil {
+		return err
+	}
+
+	// the index of the empty log file is stale, e.g. truncated
+	flag := os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	if size == 0 {
+		flag |= os.O_TRUNC
+	}
+
+	fd, err := w.fs.OpenFile(w.activeFilename()+indexSuffix, flag, perm&0600)
+	if err != nil {
+		return fmt.Errorf("open index err: %s", err)
+	}
+	_ = fd.Chmod(perm)
+
+	w.indexWriter = fd
+	w.indexLines = 0
+	w.indexNext = 0
+
+	if size > 0 {
+		count, err := w.lines()
+		if err != nil {
+			return err
+		}
+		w.indexLines = count
+		w.indexNext = count
+	}
+
+	return nil
+}
+
+// indexAdvance records the write at offset into the index when the next
+// IndexEvery lines is reached, then counts the lines of msg. The message of many
+// lines is recorded by its first line. It must be called with w locked.
+func (w *fileLogWriter) indexAdvance(offset int, msg []byte) {
+	if w.indexWriter == nil {
+		return
+	}
+
+	if w.indexLines >= w.indexNext {
+		record := strconv.Itoa(w.indexLines) + " " + strconv.Itoa(offset) + "\n"
+		if _, err := w.indexWriter.Write([]byte(record)); err != nil {
+			w.diag.printf("index:"+err.Error(), "%d %v index FileLogWriter(%q): %s", GoId(), w.now(), w.Filename, err)
+		}
+		w.indexNext = w.indexLines + w.IndexEvery
+	}
+
+	w.indexLines += bytes.Count(msg, w.terminator)
+}
@@ -0,0 +1,101 @@
+package logrus_file
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// assertIndex checks every record of the index of name locates its line
+func assertIndex(t *testing.T, fs *memFS, name string, expectedRecords int) {
+	t.Helper()
+
+	data := readMem(t, fs, name)
+	lines := strings.SplitAfter(data, "\n")
+
+	records := strings.Split(strings.TrimSpace(readMem(t, fs, name+indexSuffix)), "\n")
+	if len(records) != expectedRecords {
+		t.Fatalf("index of %s has records %q, expected %d", name, records, expectedRecords)
+	}
+
+	for _, record := range records {
+		parts := strings.Fields(record)
+		if len(parts) != 2 {
+			t.Fatalf("invalid record %q", record)
+		}
+		line, _ := strconv.Atoi(parts[0])
+		offset, _ := strconv.Atoi(parts[1])
+
+		if offset >= len(data) || line >= len(lines) || !strings.HasPrefix(data[offset:], lines[line]) {
+			t.Fatalf("record %q of %s does not locate line %q", record, name, lines[line])
+		}
+	}
+}
+
+func TestIndexLocatesLines(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"index":true,"index_every":3}`)
+
+	for i := 0; i < 10; i++ {
+		writeLines(t, w, fmt.Sprintf("line %d %s", i, strings.Repeat("x", i)))
+	}
+
+	// the lines 0 3 6 9 are recorded
+	if s := readMem(t, fs, "logs/app.log.idx"); s != "0 0\n3 27\n6 63\n9 108\n" {
+		t.Fatalf("index %q", s)
+	}
+	assertIndex(t, fs, "logs/app.log", 4)
+}
+
+func TestIndexMultiLineMessage(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"index":true,"index_every":2}`)
+
+	writeLines(t, w, "a\nb\nc", "d", "e")
+
+	// the message of 3 lines is counted as 3 lines, the next record is line 3 of d
+	if s := readMem(t, fs, "logs/app.log.idx"); s != "0 0\n3 6\n" {
+		t.Fatalf("index %q", s)
+	}
+	assertIndex(t, fs, "logs/app.log", 2)
+}
+
+func TestIndexFollowsRotation(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":4,"maxsize":0,"index":true,"index_every":2}`)
+
+	writeLines(t, w, "1", "22", "333", "4444", "55555", "666666")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.2024-01-01.log.idx", "logs/app.log", "logs/app.log.idx")
+
+	// one index per log file, the new file starts its index from line 0
+	if s := readMem(t, fs, "logs/app.2024-01-01.log.idx"); s != "0 0\n2 5\n" {
+		t.Fatalf("rotated index %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log.idx"); s != "0 0\n" {
+		t.Fatalf("active index %q", s)
+	}
+	assertIndex(t, fs, "logs/app.2024-01-01.log", 2)
+	assertIndex(t, fs, "logs/app.log", 1)
+}
+
+func TestIndexReopenContinues(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"index":true,"index_every":2}`)
+	writeLines(t, w, "a", "b", "c")
+	w.Destroy()
+
+	// the reopened writer counts the existing lines and appends to the index
+	w = newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"index":true,"index_every":2}`)
+	writeLines(t, w, "d", "e")
+
+	if s := readMem(t, fs, "logs/app.log.idx"); s != "0 0\n2 4\n3 6\n" {
+		t.Fatalf("index %q", s)
+	}
+	assertIndex(t, fs, "logs/app.log", 3)
+}
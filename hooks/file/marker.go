@@ -0,0 +1,41 @@
+package logrus_file
+
+import (
+	"fmt"
+	"time"
+)
+
+// markerKey is the key of the sentinel record, the tailers filter the records having it
+const markerKey = "_marker"
+
+func newTimeTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// writeMarkers writes the sentinel record {"_marker":"flush","ts":"..."} at every
+// MarkerInterval, so the tailers of NDJSON could checkpoint at the batch boundary
+func (w *fileLogWriter) writeMarkers() {
+	c, stop := w.newTicker(w.MarkerInterval)
+	defer stop()
+
+	for {
+		select {
+		case <-c:
+		case <-w.markerStop:
+			return
+		}
+
+		marker := fmt.Sprintf("{%q:\"flush\",\"ts\":%q}%s", markerKey, w.now().Format(time.RFC3339Nano), w.LineTerminator)
+
+		w.Lock()
+		if err := w.write([]byte(marker)); err != nil {
+			w.diag.printf("marker:"+err.Error(), "%d %v marker FileLogWriter(%q): %s", GoId(), w.now(), w.Filename, err)
+		} else {
+			w.indexAdvance(w.maxSizeCurSize, []byte(marker))
+			w.maxLinesCurLines++
+			w.maxSizeCurSize += len(marker)
+		}
+		w.Unlock()
+	}
+}
@@ -0,0 +1,93 @@
+package logrus_file
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkerCadence(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	timers := make(fakeTimers, 1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"marker_interval":30000000000}`,
+		func(w *fileLogWriter) { w.newTicker = timers.newTimer })
+
+	ticker := timers.next(t)
+	if ticker.d != 30*time.Second {
+		t.Fatalf("marker interval %s", ticker.d)
+	}
+
+	var expected []string
+	for i := 1; i <= 3; i++ {
+		writeLines(t, w, fmt.Sprintf("entry %d", i))
+		expected = append(expected, fmt.Sprintf("entry %d", i))
+
+		clock.Add(30 * time.Second)
+		ticker.c <- clock.Now()
+
+		marker := fmt.Sprintf(`{"_marker":"flush","ts":%q}`, clock.Now().Format(time.RFC3339Nano))
+		expected = append(expected, marker)
+
+		waitContent(t, fs, "logs/app.log", strings.Join(expected, "\n")+"\n")
+	}
+
+	// the markers are counted as lines, the same as the entries
+	w.Lock()
+	lines := w.maxLinesCurLines
+	w.Unlock()
+	if lines != 6 {
+		t.Fatalf("lines %d, expected 6", lines)
+	}
+}
+
+func TestMarkerStopOnDestroy(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	timers := make(fakeTimers, 1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"marker_interval":1000000000}`,
+		func(w *fileLogWriter) { w.newTicker = timers.newTimer })
+
+	ticker := timers.next(t)
+	w.Destroy()
+
+	// the tick after destroy writes nothing
+	ticker.c <- clock.Now()
+	time.Sleep(20 * time.Millisecond)
+
+	if s := readMem(t, fs, "logs/app.log"); s != "" {
+		t.Fatalf("file %q", s)
+	}
+}
+
+func TestMarkerOff(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	timers := make(fakeTimers, 1)
+	newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false}`,
+		func(w *fileLogWriter) { w.newTicker = timers.newTimer })
+
+	select {
+	case <-timers:
+		t.Fatal("the marker ticker is started without marker_interval")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// waitContent waits the file written by the background goroutine to be expected
+func waitContent(t *testing.T, fs *memFS, name, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := readMem(t, fs, name)
+		if s == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is %q, expected %q", name, s, expected)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,322 @@
+package logrus_file
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errTransient = errors.New("transient error")
+
+// memFS is the in-memory fileSystem for tests, the files live in a flat map
+// keyed by the cleaned path, the dirs are implied by the file paths
+type memFS struct {
+	mu    sync.Mutex
+	files map[string]*memFileData
+	now   func() time.Time
+
+	// the error of every write while set, e.g. the disk full
+	writeErr error
+	// the writes wait until it is closed while set, e.g. the hung NFS
+	writeBlock chan struct{}
+	// the count of Lstat calls
+	lstats int
+	// the count of Write calls succeeded
+	writes int
+	// the count of the next opens failing, e.g. the mount not ready yet
+	openFails int
+}
+
+type memFileData struct {
+	name    string
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: make(map[string]*memFileData), now: time.Now}
+}
+
+func (m *memFS) Open(name string) (logFile, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *memFS) OpenFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if m.openFails > 0 {
+		m.openFails--
+		return nil, &os.PathError{Op: "open", Path: name, Err: errTransient}
+	}
+
+	d, exist := m.files[name]
+	if !exist {
+		if flag&os.O_CREATE == 0 {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		d = &memFileData{name: name, mode: perm, modTime: m.now()}
+		m.files[name] = d
+	}
+
+	if flag&os.O_TRUNC != 0 {
+		d.data = nil
+		d.modTime = m.now()
+	}
+
+	return &memFile{fs: m, data: d, flag: flag}, nil
+}
+
+func (m *memFS) Lstat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lstats++
+	d, exist := m.files[filepath.Clean(name)]
+	if !exist {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+	}
+	return d.info(), nil
+}
+
+func (m *memFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	d, exist := m.files[oldpath]
+	if !exist {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+
+	delete(m.files, oldpath)
+	d.name = newpath
+	m.files[newpath] = d
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if _, exist := m.files[name]; !exist {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memFS) Chmod(name string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, exist := m.files[filepath.Clean(name)]
+	if !exist {
+		return &os.PathError{Op: "chmod", Path: name, Err: os.ErrNotExist}
+	}
+	d.mode = mode
+	return nil
+}
+
+// Walk visits root and the files directly in it in lexical order
+func (m *memFS) Walk(root string, fn filepath.WalkFunc) error {
+	root = filepath.Clean(root)
+
+	m.mu.Lock()
+	var infos []os.FileInfo
+	var names []string
+	for name := range m.files {
+		if filepath.Dir(name) == root {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		infos = append(infos, m.files[name].info())
+	}
+	m.mu.Unlock()
+
+	if err := fn(root, memDirInfo(filepath.Base(root)), nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	for i, name := range names {
+		if err := fn(name, infos[i], nil); err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// FailWrites makes every write fail by err, nil recovers
+func (m *memFS) FailWrites(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writeErr = err
+}
+
+// FailOpens makes the next n opens fail
+func (m *memFS) FailOpens(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.openFails = n
+}
+
+// BlockWrites makes every write hang until release is called
+func (m *memFS) BlockWrites() (release func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	block := make(chan struct{})
+	m.writeBlock = block
+	return func() {
+		m.mu.Lock()
+		m.writeBlock = nil
+		m.mu.Unlock()
+		close(block)
+	}
+}
+
+// Names returns the paths of all files, for the assertions
+func (m *memFS) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chtime sets the modification time, e.g. to age the rotated files
+func (m *memFS) Chtime(name string, t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d, exist := m.files[filepath.Clean(name)]; exist {
+		d.modTime = t
+	}
+}
+
+func (d *memFileData) info() os.FileInfo {
+	return &memFileInfo{
+		name:    filepath.Base(d.name),
+		size:    int64(len(d.data)),
+		mode:    d.mode,
+		modTime: d.modTime,
+	}
+}
+
+type memFile struct {
+	fs     *memFS
+	data   *memFileData
+	flag   int
+	offset int
+	closed bool
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.offset >= len(f.data.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data.data[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	block := f.fs.writeBlock
+	f.fs.mu.Unlock()
+
+	if block != nil {
+		<-block
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, &os.PathError{Op: "write", Path: f.data.name, Err: os.ErrPermission}
+	}
+	if f.fs.writeErr != nil {
+		return 0, &os.PathError{Op: "write", Path: f.data.name, Err: f.fs.writeErr}
+	}
+
+	// the renamed file keeps receiving the writes, the same as an opened fd
+	f.data.data = append(f.data.data, p...)
+	f.fs.writes++
+	f.data.modTime = f.fs.now()
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	return f.data.info(), nil
+}
+
+func (f *memFile) Chmod(mode os.FileMode) error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.data.mode = mode
+	return nil
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i *memFileInfo) Name() string       { return i.name }
+func (i *memFileInfo) Size() int64        { return i.size }
+func (i *memFileInfo) Mode() os.FileMode  { return i.mode }
+func (i *memFileInfo) ModTime() time.Time { return i.modTime }
+func (i *memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memFileInfo) Sys() interface{}   { return nil }
+
+func memDirInfo(name string) os.FileInfo {
+	return &memFileInfo{name: name, mode: os.ModeDir | 0755}
+}
+
+var _ fileSystem = (*memFS)(nil)
@@ -0,0 +1,85 @@
+package logrus_file
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newRetryWriter is newMemWriter recording the sleeps, the Init error is returned
+func newRetryWriter(fs *memFS, clock *fakeClock, jsonConfig string) (*fileLogWriter, *[]time.Duration, error) {
+	fs.now = clock.Now
+
+	var sleeps []time.Duration
+
+	w := newDefaultWriter()
+	w.fs = fs
+	w.now = clock.Now
+	w.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	return w, &sleeps, w.Init(jsonConfig)
+}
+
+func TestOpenRetrySucceeds(t *testing.T) {
+	fs := newMemFS()
+	fs.FailOpens(2)
+
+	w, sleeps, err := newRetryWriter(fs, newFakeClock(day1), `{"filename":"logs/app.log","open_retries":3,"open_backoff":10000000}`)
+	if err != nil {
+		t.Fatalf("init: %s", err)
+	}
+	defer w.Destroy()
+
+	if expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; !reflect.DeepEqual(*sleeps, expected) {
+		t.Fatalf("sleeps %v, expected %v", *sleeps, expected)
+	}
+
+	writeLines(t, w, "a")
+	if s := readMem(t, fs, "logs/app.log"); s != "a\n" {
+		t.Fatalf("file %q", s)
+	}
+}
+
+func TestOpenRetryDefaultBackoff(t *testing.T) {
+	fs := newMemFS()
+	fs.FailOpens(1)
+
+	w, sleeps, err := newRetryWriter(fs, newFakeClock(day1), `{"filename":"logs/app.log","open_retries":1}`)
+	if err != nil {
+		t.Fatalf("init: %s", err)
+	}
+	defer w.Destroy()
+
+	if expected := []time.Duration{defaultOpenBackoff}; !reflect.DeepEqual(*sleeps, expected) {
+		t.Fatalf("sleeps %v, expected %v", *sleeps, expected)
+	}
+}
+
+func TestOpenRetryExhausted(t *testing.T) {
+	fs := newMemFS()
+	fs.FailOpens(3)
+
+	_, sleeps, err := newRetryWriter(fs, newFakeClock(day1), `{"filename":"logs/app.log","open_retries":2,"open_backoff":10000000}`)
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("init error %v, expected %v", err, errTransient)
+	}
+
+	if len(*sleeps) != 2 {
+		t.Fatalf("sleeps %v, expected 2", *sleeps)
+	}
+	assertNames(t, fs)
+}
+
+func TestOpenNoRetry(t *testing.T) {
+	fs := newMemFS()
+	fs.FailOpens(1)
+
+	_, sleeps, err := newRetryWriter(fs, newFakeClock(day1), `{"filename":"logs/app.log"}`)
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("init error %v, expected %v", err, errTransient)
+	}
+	if len(*sleeps) != 0 {
+		t.Fatalf("sleeps %v, expected none", *sleeps)
+	}
+}
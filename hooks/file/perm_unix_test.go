@@ -0,0 +1,51 @@
+//go:build linux || darwin || freebsd || dragonfly
+// +build linux darwin freebsd dragonfly
+
+package logrus_file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestPermIgnoresUmask(t *testing.T) {
+	for _, umask := range []int{0, 0077, 0027} {
+		old := syscall.Umask(umask)
+
+		filename := filepath.Join(t.TempDir(), "app.log")
+		w := newDefaultWriter()
+		err := w.Init(fmt.Sprintf(`{"filename":%q,"perm":"0644","rotateperm":"0444","daily":false,"hourly":false}`, filename))
+
+		syscall.Umask(old)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = w.WriteMsg(w.now(), "a\n"); err != nil {
+			t.Fatal(err)
+		}
+		if err = w.doRotate(w.now(), true); err != nil {
+			t.Fatal(err)
+		}
+		w.Destroy()
+
+		rotated, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "app.*.log"))
+		if len(rotated) != 1 {
+			t.Fatalf("rotated %v", rotated)
+		}
+
+		for name, expected := range map[string]os.FileMode{filename: 0644, rotated[0]: 0444} {
+			info, err := os.Stat(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != expected {
+				t.Fatalf("umask %04o: %s mode %v, expected %v", umask, name, info.Mode().Perm(), expected)
+			}
+		}
+	}
+}
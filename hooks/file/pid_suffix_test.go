@@ -0,0 +1,77 @@
+package logrus_file
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPidFilename(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"logs/app.log":  "logs/app.42.log",
+		"logs/app":      "logs/app.42",
+		"app.json.log":  "app.json.42.log",
+		"/var/log/x.gz": "/var/log/x.42.gz",
+	} {
+		if name := pidFilename(filename, 42); name != expected {
+			t.Fatalf("%s is %s, expected %s", filename, name, expected)
+		}
+	}
+}
+
+func TestPidSuffixProcesses(t *testing.T) {
+	dir := t.TempDir()
+	defer func(f func() int) { getpid = f }(getpid)
+
+	// two processes sharing the dir
+	for _, pid := range []int{100, 200} {
+		getpid = func() int { return pid }
+
+		hook, err := NewFileHook(config.NewConfig(config.ConfigString(`filename = "` + filepath.Join(dir, "app.log") + `", level = 6, hourly = false, pid-suffix = true`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logger := logrus.New()
+		logger.Out = ioutil.Discard
+		logger.Formatter = &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}
+		logger.AddHook(hook)
+		logger.Infof("pid %d", pid)
+
+		hook.(*FileHook).W.Destroy()
+	}
+
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	sort.Strings(names)
+	if len(names) != 2 || filepath.Base(names[0]) != "app.100.log" || filepath.Base(names[1]) != "app.200.log" {
+		t.Fatalf("files %v", names)
+	}
+
+	for i, pid := range []string{"100", "200"} {
+		data, err := ioutil.ReadFile(names[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(data); s != "level=info msg=\"pid "+pid+"\"\n" {
+			t.Fatalf("%s is %q", names[i], s)
+		}
+	}
+}
+
+func TestPidSuffixRotated(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+
+	for _, pid := range []int{100, 200} {
+		w := newMemWriter(t, fs, clock, `{"filename":"`+pidFilename("logs/app.log", pid)+`","daily":false,"hourly":false,"maxlines":1,"maxsize":0,"always_number":true}`)
+		writeLines(t, w, "a", "b")
+	}
+
+	assertNames(t, fs,
+		"logs/app.100.2024-01-01.001.log", "logs/app.100.log",
+		"logs/app.200.2024-01-01.001.log", "logs/app.200.log")
+}
@@ -0,0 +1,108 @@
+package logrus_file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// the interval of probing the read-only filesystem when readonly_probe is not set
+const defaultReadOnlyProbe = 10 * time.Second
+
+// readOnlyState holds the messages while the filesystem is read-only, e.g.
+// remounted, until the probe writes them
+type readOnlyState struct {
+	msgs  [][]byte
+	lines []int
+	size  int
+
+	overflow int
+	stop     chan struct{}
+}
+
+func isReadOnly(err error) bool {
+	return errors.Is(err, syscall.EROFS)
+}
+
+// enterReadOnly starts holding the messages from msg failed by the read-only
+// filesystem, and probes it at ReadOnlyProbe. It must be called with w locked.
+func (w *fileLogWriter) enterReadOnly(msg []byte, lines int) {
+	_, _ = fmt.Fprintf(os.Stderr, "%d %v FileLogWriter(%q): filesystem is read-only, hold the messages up to %d bytes\n", GoId(), w.now(), w.Filename, w.ReadOnlyBuffer)
+
+	w.readOnly = &readOnlyState{stop: make(chan struct{})}
+	w.readOnly.hold(w, msg, lines)
+
+	go w.probeReadOnly(w.readOnly)
+}
+
+// hold keeps msg, the messages beyond ReadOnlyBuffer go to stderr
+func (s *readOnlyState) hold(w *fileLogWriter, msg []byte, lines int) {
+	if s.size+len(msg) > w.ReadOnlyBuffer {
+		s.overflow++
+		w.fallbackToStderr(string(msg))
+		return
+	}
+
+	s.msgs = append(s.msgs, msg)
+	s.lines = append(s.lines, lines)
+	s.size += len(msg)
+}
+
+// probeReadOnly writes the held messages at every ReadOnlyProbe until all
+// written, then the writer leaves the read-only mode
+func (w *fileLogWriter) probeReadOnly(s *readOnlyState) {
+	c, stop := w.newTicker(w.ReadOnlyProbe)
+	defer stop()
+
+	for {
+		select {
+		case <-c:
+		case <-s.stop:
+			return
+		}
+
+		w.Lock()
+		recovered := w.flushReadOnly(s)
+		w.Unlock()
+
+		if recovered {
+			return
+		}
+	}
+}
+
+// flushReadOnly writes the held messages in order, the file is reopened once when
+// the write failed by other than read-only, e.g. the remount invalidated it.
+// It must be called with w locked.
+func (w *fileLogWriter) flushReadOnly(s *readOnlyState) bool {
+	if w.readOnly != s {
+		// destroyed
+		return true
+	}
+
+	reopened := false
+
+	for len(s.msgs) > 0 {
+		err := w.writeLocked(s.msgs[0], s.lines[0])
+		if err != nil && !isReadOnly(err) && !reopened {
+			reopened = true
+			if err = w.startLogger(); err == nil {
+				err = w.writeLocked(s.msgs[0], s.lines[0])
+			}
+		}
+		if err != nil {
+			return false
+		}
+
+		s.size -= len(s.msgs[0])
+		s.msgs, s.lines = s.msgs[1:], s.lines[1:]
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "%d %v FileLogWriter(%q): filesystem is writable again, %d messages went to stderr meanwhile\n", GoId(), w.now(), w.Filename, s.overflow)
+
+	w.readOnly = nil
+
+	return true
+}
@@ -0,0 +1,135 @@
+package logrus_file
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadOnlyHoldAndRecover(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	timers := make(fakeTimers, 1)
+	stderr := &bytes.Buffer{}
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"readonly_buffer":8,"readonly_probe":1000000000}`,
+		func(w *fileLogWriter) {
+			w.newTicker = timers.newTimer
+			w.stderr = stderr
+		})
+
+	writeLines(t, w, "a")
+
+	// the remount flips the filesystem to read-only, the messages are held
+	fs.FailWrites(syscall.EROFS)
+	writeLines(t, w, "b", "c")
+
+	probe := timers.next(t)
+	if probe.d != time.Second {
+		t.Fatalf("probe interval %s", probe.d)
+	}
+
+	// beyond the buffer goes to stderr
+	writeLines(t, w, "overflow")
+	if s := stderr.String(); s != "overflow\n" {
+		t.Fatalf("stderr %q", s)
+	}
+
+	// the third tick is taken after the first probe is done, the probes still
+	// read-only keep holding
+	for i := 0; i < 3; i++ {
+		probe.c <- clock.Now()
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "a\n" {
+		t.Fatalf("file while read-only %q", s)
+	}
+
+	// writable again, the held messages are written in order by the next probe,
+	// which may be the tick still pending
+	fs.FailWrites(nil)
+	select {
+	case probe.c <- clock.Now():
+	default:
+	}
+	waitContent(t, fs, "logs/app.log", "a\nb\nc\n")
+
+	w.Lock()
+	recovered := w.readOnly == nil
+	w.Unlock()
+	if !recovered {
+		t.Fatal("still read-only after flushed")
+	}
+
+	writeLines(t, w, "d")
+	if s := readMem(t, fs, "logs/app.log"); s != "a\nb\nc\nd\n" {
+		t.Fatalf("file after recovered %q", s)
+	}
+
+	// the held messages are counted when written
+	w.Lock()
+	lines := w.maxLinesCurLines
+	w.Unlock()
+	if lines != 4 {
+		t.Fatalf("lines %d, expected 4", lines)
+	}
+}
+
+func TestReadOnlyWithoutBuffer(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false}`)
+
+	fs.FailWrites(syscall.EROFS)
+	if err := w.WriteMsg(clock.Now(), "a\n"); !errors.Is(err, syscall.EROFS) {
+		t.Fatalf("write error %v, expected EROFS", err)
+	}
+}
+
+func TestReadOnlyOtherErrorNotHeld(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"readonly_buffer":1024}`)
+
+	fs.FailWrites(syscall.ENOSPC)
+	err := w.WriteMsg(clock.Now(), "a\n")
+	if err == nil || !strings.Contains(err.Error(), syscall.ENOSPC.Error()) {
+		t.Fatalf("write error %v, expected ENOSPC", err)
+	}
+
+	w.Lock()
+	held := w.readOnly != nil
+	w.Unlock()
+	if held {
+		t.Fatal("held by the error other than read-only")
+	}
+}
+
+func TestReadOnlyProbeStopsOnClose(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	timers := make(fakeTimers, 1)
+	probeStopped := make(chan struct{})
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"readonly_buffer":8,"readonly_probe":1000000000}`,
+		func(w *fileLogWriter) {
+			w.newTicker = func(d time.Duration) (<-chan time.Time, func()) {
+				c, _ := timers.newTimer(d)
+				return c, func() { close(probeStopped) }
+			}
+			w.stderr = &bytes.Buffer{}
+		})
+
+	fs.FailWrites(syscall.EROFS)
+	writeLines(t, w, "a")
+	timers.next(t)
+
+	// the filesystem never recovers, Close of the hook stops the probe
+	_ = (&FileHook{W: w}).Close()
+
+	select {
+	case <-probeStopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the read-only probe is not stopped by Close")
+	}
+}
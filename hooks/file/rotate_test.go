@@ -0,0 +1,111 @@
+package logrus_file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateMaxLinesCounters(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	w, err := newFileWriter(`{"filename":"` + filepath.ToSlash(filename) + `","daily":false,"hourly":false,"maxlines":2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	when := time.Now()
+	for _, line := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		if err := w.WriteMsg(when, line+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the counters restart at every fresh file
+	names := rotatedFiles(t, dir)
+	if len(names) != 3 {
+		t.Fatalf("rotated %v", names)
+	}
+	for i, expected := range []string{"1\n2\n", "3\n4\n", "5\n6\n"} {
+		if s := readFile(t, names[i]); s != expected {
+			t.Fatalf("%s %q, expected %q", names[i], s, expected)
+		}
+	}
+	if s := readFile(t, filename); s != "7\n" {
+		t.Fatalf("active %q", s)
+	}
+
+	w.Lock()
+	lines, size := w.maxLinesCurLines, w.maxSizeCurSize
+	w.Unlock()
+	if lines != 1 || size != 2 {
+		t.Fatalf("%d lines and %d bytes of the active file", lines, size)
+	}
+
+	// the reopen counts the lines already in the file
+	w.Lock()
+	err = w.startLogger()
+	lines = w.maxLinesCurLines
+	w.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines != 1 {
+		t.Fatalf("%d lines after reopen", lines)
+	}
+}
+
+func TestDeleteOldLogSkipsActiveAndSubDirs(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	w, err := newFileWriter(`{"filename":"` + filepath.ToSlash(filename) + `","daily":false,"hourly":false,"maxlines":1,"maxdays":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	when := time.Now()
+	for _, line := range []string{"1", "2", "3", "4"} {
+		if err := w.WriteMsg(when, line+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := rotatedFiles(t, dir)
+	if len(names) != 3 {
+		t.Fatalf("rotated %v", names)
+	}
+
+	sub := filepath.Join(dir, "app.sub.log")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(sub, "app.old.log")
+	if err := os.WriteFile(nested, []byte("nested\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{filename, names[0], nested} {
+		if err := os.Chtimes(name, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.deleteOldLog()
+
+	if _, err := os.Stat(names[0]); !os.IsNotExist(err) {
+		t.Fatalf("the old rotated file %s is kept: %v", names[0], err)
+	}
+	for _, name := range []string{filename, names[1], nested} {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatalf("%s is deleted: %s", name, err)
+		}
+	}
+	if !strings.HasSuffix(readFile(t, filename), "4\n") {
+		t.Fatal("the active file is lost")
+	}
+}
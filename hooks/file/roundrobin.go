@@ -0,0 +1,63 @@
+package logrus_file
+
+import (
+	"fmt"
+	"os"
+)
+
+// rotateRoundRobin is the rotate_mode writing Slots files in cycle, like
+// xx.0.log ... xx.3.log, see rotateSlot
+const rotateRoundRobin = "round-robin"
+
+// slotFilename is the file of slot, logs/app.log is logs/app.<slot>.log
+func (w *fileLogWriter) slotFilename(slot int) string {
+	return fmt.Sprintf("%s.%d%s", w.fileNameOnly, slot, w.suffix)
+}
+
+// activeFilename is the file written, the slot file in round-robin
+func (w *fileLogWriter) activeFilename() string {
+	if w.Slots > 0 {
+		return w.slotFilename(w.slot)
+	}
+	return w.Filename
+}
+
+// lastSlot is the slot modified last, so the restart continues it instead of
+// overwriting slot 0, it is 0 when no slot exists
+func (w *fileLogWriter) lastSlot() int {
+	last := 0
+	var lastModTime int64
+
+	for slot := 0; slot < w.Slots; slot++ {
+		info, err := w.fs.Lstat(w.slotFilename(slot))
+		if err != nil {
+			continue
+		}
+
+		if modTime := info.ModTime().UnixNano(); modTime > lastModTime {
+			last, lastModTime = slot, modTime
+		}
+	}
+
+	return last
+}
+
+// rotateSlot is doRotate in round-robin, the next slot is truncated and written,
+// so the oldest is overwritten and the files never exceed Slots. The slots are
+// never renamed, compressed or deleted.
+func (w *fileLogWriter) rotateSlot() error {
+	w.flushPending("rotate")
+	w.fileWriter.Close()
+
+	w.slot = (w.slot + 1) % w.Slots
+	w.truncateSlot = true
+
+	_, _ = fmt.Fprintf(os.Stderr, "%d %v rotate: round-robin to slot %s, %v\n", GoId(), w.now(), w.activeFilename(), w)
+
+	err := w.startLogger()
+	if err != nil {
+		return fmt.Errorf("rotate: restartLogger startLoggerErr: %v", err)
+	}
+
+	return nil
+}
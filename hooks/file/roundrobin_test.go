@@ -0,0 +1,76 @@
+package logrus_file
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const roundRobinConfig = `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0,"rotate_mode":"round-robin","slots":3}`
+
+// writeSlotLines writes the lines a second apart, so the slots are ordered by their modification time
+func writeSlotLines(t *testing.T, w *fileLogWriter, clock *fakeClock, from, to int) {
+	t.Helper()
+
+	for i := from; i <= to; i++ {
+		clock.Add(time.Second)
+		writeLines(t, w, fmt.Sprint(i))
+	}
+}
+
+func assertSlots(t *testing.T, fs *memFS, expected ...string) {
+	t.Helper()
+
+	assertNames(t, fs, "logs/app.0.log", "logs/app.1.log", "logs/app.2.log")
+	for slot, s := range expected {
+		name := fmt.Sprintf("logs/app.%d.log", slot)
+		if data := readMem(t, fs, name); data != s {
+			t.Fatalf("%s is %q, expected %q", name, data, s)
+		}
+	}
+}
+
+func TestRoundRobinCycle(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, roundRobinConfig)
+
+	writeSlotLines(t, w, clock, 1, 6)
+	assertSlots(t, fs, "1\n2\n", "3\n4\n", "5\n6\n")
+
+	// the oldest slot is truncated and overwritten, never more files than slots
+	writeSlotLines(t, w, clock, 7, 7)
+	assertSlots(t, fs, "7\n", "3\n4\n", "5\n6\n")
+
+	writeSlotLines(t, w, clock, 8, 11)
+	assertSlots(t, fs, "7\n8\n", "9\n10\n", "11\n")
+}
+
+func TestRoundRobinRestart(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, roundRobinConfig)
+
+	writeSlotLines(t, w, clock, 1, 3)
+	w.Destroy()
+
+	// the restart continues the slot modified last instead of overwriting slot 0
+	w = newMemWriter(t, fs, clock, roundRobinConfig)
+	writeSlotLines(t, w, clock, 4, 5)
+
+	assertSlots(t, fs, "1\n2\n", "3\n4\n", "5\n")
+}
+
+func TestRoundRobinInvalid(t *testing.T) {
+	for _, config := range []string{
+		`{"filename":"logs/app.log","rotate_mode":"round-robin","slots":1}`,
+		`{"filename":"logs/app.log","rotate_mode":"ring","slots":3}`,
+		`{"filename":"logs/app.log","rotate_mode":"round-robin","slots":3,"encrypt_key":"MDEyMzQ1Njc4OWFiY2RlZg=="}`,
+	} {
+		w := newDefaultWriter()
+		w.fs = newMemFS()
+		if err := w.Init(config); err == nil {
+			t.Fatalf("%s is accepted", config)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logrus_file
+
+import (
+	"bytes"
+)
+
+// the messages longer are stripped by stripScan instead of the regexp by default
+const defaultStripMaxLen = 64 * 1024
+
+// strip removes the ansi colors of msg, the messages longer than StripMaxLen are
+// scanned once instead of the regexp, so a huge message costs linear time and
+// at most one copy
+func (w *fileLogWriter) strip(msg []byte) []byte {
+	if w.StripMaxLen > 0 && len(msg) > w.StripMaxLen {
+		return stripScan(msg)
+	}
+
+	return re.ReplaceAll(msg, nil)
+}
+
+// stripScan removes the sequences matched by ansi byte by byte, msg is returned
+// as it is when it has no escape
+func stripScan(msg []byte) []byte {
+	if bytes.IndexByte(msg, 0x1b) < 0 && !bytes.Contains(msg, []byte("\u009b")) {
+		return msg
+	}
+
+	out := make([]byte, 0, len(msg))
+
+	for i := 0; i < len(msg); {
+		start := i
+		switch {
+		case msg[i] == 0x1b:
+			i++
+		case msg[i] == 0xc2 && i+1 < len(msg) && msg[i+1] == 0x9b:
+			i += 2
+		default:
+			out = append(out, msg[i])
+			i++
+			continue
+		}
+
+		if n := ansiLen(msg[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		// not a sequence, keep the escape
+		out = append(out, msg[start:i]...)
+	}
+
+	return out
+}
+
+// ansiLen returns the length of the sequence after the escape, 0 if none
+func ansiLen(b []byte) int {
+	i := 0
+	for i < len(b) && bytes.IndexByte([]byte("[]()#;?"), b[i]) >= 0 {
+		i++
+	}
+
+	// the sequence terminated by BEL, like the title of terminal
+	j := i
+	for j < len(b) && (isAlnum(b[j]) || b[j] == ';') {
+		j++
+	}
+	if j < len(b) && b[j] == 0x07 {
+		return j + 1
+	}
+
+	// the params of at most 4 digits separated by ';' then the final byte, the
+	// digits are final bytes too, so the longest params followed by one wins
+	j = i
+	if n := digitsLen(b[i:]); n > 0 {
+		j += n
+		for j < len(b) && b[j] == ';' {
+			j++
+			j += digitsLen(b[j:])
+		}
+	}
+	for ; j >= i; j-- {
+		if j < len(b) && isFinal(b[j]) {
+			return j + 1
+		}
+	}
+
+	return 0
+}
+
+// digitsLen returns the length of the leading digits of b, at most 4
+func digitsLen(b []byte) int {
+	n := 0
+	for n < len(b) && n < 4 && isDigit(b[n]) {
+		n++
+	}
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isFinal reports whether c is in [\dA-PRZcf-ntqry=><~]
+func isFinal(c byte) bool {
+	switch {
+	case isDigit(c), c >= 'A' && c <= 'P', c >= 'f' && c <= 'n':
+		return true
+	}
+
+	return bytes.IndexByte([]byte("RZcqtry=><~"), c) >= 0
+}
@@ -0,0 +1,88 @@
+package logrus_file
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+var stripCases = []string{
+	"plain text",
+	"\x1b[31mred\x1b[0m",
+	"\x1b[1;32;40mbold green\x1b[m and \x1b[0;39m reset",
+	"\x1b[38;5;196m256 colors\x1b[0m",
+	"\x1b]0;title\x07after title",
+	"\x1b(B charset",
+	"\u009b31mc1 csi\u009b0m",
+	"lone escape \x1b at the end \x1b",
+	"\x1b[12345m too many digits",
+	"\x1b[?25l hide cursor \x1b[?25h",
+	"中文 \x1b[33m黄\x1b[0m",
+	"",
+}
+
+func TestStripScanEquivalent(t *testing.T) {
+	for _, s := range stripCases {
+		expected := re.ReplaceAll([]byte(s), nil)
+		if got := stripScan([]byte(s)); !bytes.Equal(got, expected) {
+			t.Fatalf("%q is stripped as %q, the regexp %q", s, got, expected)
+		}
+	}
+}
+
+func TestStripScanEquivalentRandom(t *testing.T) {
+	alphabet := []byte("\x1b\x07[]()#;?0123456789mHJKABfhlqrtyRZc=><~xyz \xc2\x9b\n")
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20000; i++ {
+		b := make([]byte, rnd.Intn(24))
+		for j := range b {
+			b[j] = alphabet[rnd.Intn(len(alphabet))]
+		}
+
+		expected := re.ReplaceAll(b, nil)
+		if got := stripScan(b); !bytes.Equal(got, expected) {
+			t.Fatalf("%q is stripped as %q, the regexp %q", b, got, expected)
+		}
+	}
+}
+
+func TestStripMaxLen(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"stripcolors":true,"strip_max_len":16}`)
+
+	// the short one by the regexp, the long one by the scan, the same output
+	writeLines(t, w, "\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m and a long tail of text")
+
+	if s := readMem(t, fs, "logs/app.log"); s != "red\nred and a long tail of text\n" {
+		t.Fatalf("file %q", s)
+	}
+}
+
+// largeColored returns about size bytes of colored lines
+func largeColored(size int) []byte {
+	line := []byte("\x1b[36mINFO\x1b[0m[0000] request handled \x1b[36mstatus\x1b[0m=200 \x1b[36mpath\x1b[0m=/users\n")
+	return bytes.Repeat(line, size/len(line)+1)
+}
+
+func benchmarkStrip(b *testing.B, size int, strip func([]byte) []byte) {
+	msg := largeColored(size)
+
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		strip(msg)
+	}
+}
+
+func stripRegexp(msg []byte) []byte {
+	return re.ReplaceAll(msg, nil)
+}
+
+func BenchmarkStripRegexp64K(b *testing.B) { benchmarkStrip(b, 64<<10, stripRegexp) }
+func BenchmarkStripScan64K(b *testing.B)   { benchmarkStrip(b, 64<<10, stripScan) }
+func BenchmarkStripRegexp4M(b *testing.B)  { benchmarkStrip(b, 4<<20, stripRegexp) }
+func BenchmarkStripScan4M(b *testing.B)    { benchmarkStrip(b, 4<<20, stripScan) }
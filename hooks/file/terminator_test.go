@@ -0,0 +1,87 @@
+package logrus_file
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLineTerminatorCRLF(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":2,"maxsize":0,"line_terminator":"\r\n"}`)
+
+	writeLines(t, w, "a", "b", "c")
+	if err := w.WriteMsg(w.now(), "d\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteBlock(w.now(), "dump", []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the trailing "\n" of formatters is replaced, the terminated message is kept
+	assertNames(t, fs, "logs/app.2024-01-01.001.log", "logs/app.2024-01-01.002.log", "logs/app.log")
+	for name, expected := range map[string]string{
+		"logs/app.2024-01-01.001.log": "a\r\nb\r\n",
+		"logs/app.2024-01-01.002.log": "c\r\nd\r\n",
+		"logs/app.log":                "dump\r\n\tx\r\n",
+	} {
+		if s := readMem(t, fs, name); s != expected {
+			t.Fatalf("%s is %q, expected %q", name, s, expected)
+		}
+	}
+}
+
+func TestLineTerminatorNUL(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":3,"maxsize":0,"line_terminator":"\u0000"}`)
+
+	// the "\n" inside the message is not a line
+	writeLines(t, w, "one\nline", "b", "c", "d")
+
+	assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	if s := readMem(t, fs, "logs/app.2024-01-01.log"); s != "one\nline\x00b\x00c\x00" {
+		t.Fatalf("rotated %q", s)
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "d\x00" {
+		t.Fatalf("active %q", s)
+	}
+}
+
+func TestLineTerminatorCountsExistingLines(t *testing.T) {
+	for _, c := range []struct {
+		terminator string
+		json       string
+	}{
+		{"\n", `\n`},
+		{"\r\n", `\r\n`},
+		{"\x00", `\u0000`},
+	} {
+		fs := newMemFS()
+		clock := newFakeClock(day1)
+		fs.now = clock.Now
+
+		// the lines of 11 bytes with "\r\n" split the terminator across the 32k reads
+		const lines = 5000
+		var b strings.Builder
+		for i := 0; i < lines; i++ {
+			b.WriteString(fmt.Sprintf("line%05d", i))
+			b.WriteString(c.terminator)
+		}
+		f, _ := fs.OpenFile("logs/app.log", os.O_WRONLY|os.O_CREATE, 0660)
+		_, _ = f.Write([]byte(b.String()))
+		_ = f.Close()
+
+		w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","daily":false,"hourly":false,"maxlines":5002,"maxsize":0,"line_terminator":"`+c.json+`"}`)
+		if w.maxLinesCurLines != lines {
+			t.Fatalf("terminator %q: %d lines counted, expected %d", c.terminator, w.maxLinesCurLines, lines)
+		}
+
+		writeLines(t, w, "a", "b")
+		assertNames(t, fs, "logs/app.log")
+		writeLines(t, w, "c")
+		assertNames(t, fs, "logs/app.2024-01-01.log", "logs/app.log")
+	}
+}
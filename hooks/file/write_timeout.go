@@ -0,0 +1,73 @@
+package logrus_file
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errWriteTimeout  = errors.New("write timeout")
+	errWriteDegraded = errors.New("write degraded, the timed out write is still in flight")
+)
+
+// writeFile writes msg into the file, it must be called with w locked.
+//
+// With WriteTimeout the write runs in a goroutine and is abandoned after the
+// timeout, e.g. a hung NFS. The abandoned write is not cancelled, it may still
+// complete later, so the message could appear in the file after the stderr
+// fallback. Until it returns the writer is degraded: no new write is issued,
+// the messages go to the stderr fallback if enabled or are dropped, so the
+// logging goroutines never pile up on the hung file.
+func (w *fileLogWriter) writeFile(msg []byte) error {
+	if w.WriteTimeout <= 0 {
+		_, err := w.fileWriter.Write(msg)
+		return err
+	}
+
+	if w.inflight != nil {
+		select {
+		case <-w.inflight:
+			w.inflight = nil
+			w.diag.printf("write recovered", "%d %v FileLogWriter(%q): timed out write returned, write recovered", GoId(), w.now(), w.Filename)
+		default:
+			return errWriteDegraded
+		}
+	}
+
+	done := make(chan error, 1)
+	fd := w.fileWriter
+	go func() {
+		_, err := fd.Write(msg)
+		done <- err
+	}()
+
+	timer := time.NewTimer(w.WriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		w.inflight = done
+		w.diag.printf("write timeout", "%d %v FileLogWriter(%q): write timeout after %s, writer degraded", GoId(), w.now(), w.Filename, w.WriteTimeout)
+		return errWriteTimeout
+	}
+}
+
+// degraded reports whether a timed out write is still in flight
+func (w *fileLogWriter) degraded() bool {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.inflight == nil {
+		return false
+	}
+
+	select {
+	case <-w.inflight:
+		w.inflight = nil
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,81 @@
+package logrus_file
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	stderr := &bytes.Buffer{}
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"stderr_fallback":true,"write_timeout":20000000}`,
+		func(w *fileLogWriter) {
+			w.stderr = stderr
+			w.diag.out = ioutil.Discard
+		})
+
+	writeLines(t, w, "before")
+
+	release := fs.BlockWrites()
+
+	// the hung write is abandoned after the timeout and falls back to stderr
+	start := time.Now()
+	if err := w.WriteMsg(w.now(), "hung\n"); err != errWriteTimeout {
+		t.Fatalf("expected write timeout: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("the write returned after %s", elapsed)
+	}
+	if !w.degraded() {
+		t.Fatal("the writer should be degraded while the write is in flight")
+	}
+
+	// no new write is issued while degraded, the messages go to stderr at once
+	start = time.Now()
+	writeLines(t, w, "degraded")
+	if elapsed := time.Since(start); elapsed >= 20*time.Millisecond {
+		t.Fatalf("the degraded write waited %s", elapsed)
+	}
+
+	if s := stderr.String(); s != "hung\ndegraded\n" {
+		t.Fatalf("stderr %q", s)
+	}
+
+	// the abandoned write completes later, the writer recovers
+	release()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.degraded() {
+		if time.Now().After(deadline) {
+			t.Fatal("the writer is not recovered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	writeLines(t, w, "after")
+
+	if s := readMem(t, fs, "logs/app.log"); s != "before\nhung\nafter\n" {
+		t.Fatalf("file %q", s)
+	}
+	if strings.Contains(stderr.String(), "after") {
+		t.Fatalf("stderr %q", stderr)
+	}
+}
+
+func TestWriteTimeoutFast(t *testing.T) {
+	fs := newMemFS()
+	clock := newFakeClock(day1)
+	w := newMemWriter(t, fs, clock, `{"filename":"logs/app.log","rotate":false,"write_timeout":1000000000}`)
+
+	writeLines(t, w, "a", "b")
+
+	if w.degraded() {
+		t.Fatal("the fast writes should not degrade the writer")
+	}
+	if s := readMem(t, fs, "logs/app.log"); s != "a\nb\n" {
+		t.Fatalf("file %q", s)
+	}
+}
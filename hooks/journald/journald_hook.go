@@ -0,0 +1,197 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+var allLevels = []logrus.Level{
+	logrus.DebugLevel,
+	logrus.InfoLevel,
+	logrus.WarnLevel,
+	logrus.ErrorLevel,
+	logrus.FatalLevel,
+	logrus.PanicLevel,
+}
+
+// syslog priorities of the levels
+var priorities = map[logrus.Level]int{
+	logrus.PanicLevel: 0,
+	logrus.FatalLevel: 2,
+	logrus.ErrorLevel: 3,
+	logrus.WarnLevel:  4,
+	logrus.InfoLevel:  6,
+	logrus.DebugLevel: 7,
+	logrus.TraceLevel: 7,
+}
+
+type JournaldHookConfig struct {
+	SocketPath string
+	Identifier string
+	Levels     []string
+}
+
+func init() {
+	logrus_mate.RegisterHook("journald", NewJournaldHook)
+}
+
+func NewJournaldHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := JournaldHookConfig{
+		SocketPath: "/run/systemd/journal/socket",
+	}
+
+	if config != nil {
+		conf.SocketPath = config.GetString("socket-path", conf.SocketPath)
+		conf.Identifier = config.GetString("identifier")
+		conf.Levels = config.GetStringList("levels")
+	}
+
+	levels := []logrus.Level{}
+
+	for _, level := range conf.Levels {
+		var lv logrus.Level
+		if lv, err = logrus_mate.ParseLevel(level); err != nil {
+			return
+		}
+		levels = append(levels, lv)
+	}
+
+	hook = &JournaldHook{
+		AcceptedLevels: levels,
+		Config:         conf,
+	}
+
+	return
+}
+
+// JournaldHook sends the entries to journald by its native socket protocol,
+// the fields become journal fields with the uppercased keys, and PRIORITY is
+// mapped from level. It is a no-op on the platforms other than linux.
+type JournaldHook struct {
+	AcceptedLevels []logrus.Level
+	Config         JournaldHookConfig
+
+	locker sync.Mutex
+	conn   journalConn
+}
+
+func (p *JournaldHook) Levels() []logrus.Level {
+	if len(p.AcceptedLevels) == 0 {
+		return allLevels
+	}
+	return p.AcceptedLevels
+}
+
+func (p *JournaldHook) Fire(entry *logrus.Entry) (err error) {
+	msg := p.encode(entry)
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.conn == nil {
+		if p.conn, err = dialJournal(p.Config.SocketPath); err != nil {
+			err = fmt.Errorf("logurs mate: journald(%q): %s", p.Config.SocketPath, err)
+			return
+		}
+	}
+
+	if err = p.conn.send(msg); err != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+		err = fmt.Errorf("logurs mate: journald(%q): %s", p.Config.SocketPath, err)
+	}
+
+	return
+}
+
+// Close closes the socket
+func (p *JournaldHook) Close() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+
+	return err
+}
+
+func (p *JournaldHook) encode(entry *logrus.Entry) []byte {
+	b := &bytes.Buffer{}
+
+	writeField(b, "MESSAGE", entry.Message)
+	writeField(b, "PRIORITY", fmt.Sprint(priorities[entry.Level]))
+
+	if len(p.Config.Identifier) > 0 {
+		writeField(b, "SYSLOG_IDENTIFIER", p.Config.Identifier)
+	}
+
+	for k, v := range entry.Data {
+		if e, ok := v.(error); ok {
+			v = e.Error()
+		}
+		writeField(b, fieldName(k), fmt.Sprint(v))
+	}
+
+	return b.Bytes()
+}
+
+// writeField writes KEY=value, or the binary safe form for the multiline value:
+// KEY\n, the little endian uint64 length, value and \n
+func writeField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+
+	if !strings.ContainsRune(value, '\n') {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// fieldName converts key to the journal field name, which has only uppercase
+// letters, digits and underscore, does not start with underscore or digit
+// (reserved for trusted fields), and is at most 64 chars
+func fieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if len(name) == 0 || name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
+
+type journalConn interface {
+	send(msg []byte) error
+	Close() error
+}
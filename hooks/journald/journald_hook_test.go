@@ -0,0 +1,50 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestFieldName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"user":        "USER",
+		"request-id":  "REQUEST_ID",
+		"http.status": "HTTP_STATUS",
+		"_private":    "PRIVATE",
+		"2fa":         "F_2FA",
+		"__":          "F_",
+		"Mixed_Case9": "MIXED_CASE9",
+		"名字":          "F_",
+		"user name":   "USER_NAME",
+	} {
+		if name := fieldName(key); name != expected {
+			t.Errorf("%q: %q, expected %q", key, name, expected)
+		}
+	}
+
+	long := string(bytes.Repeat([]byte("a"), 100))
+	if name := fieldName(long); len(name) != 64 {
+		t.Errorf("the name of %d chars is not cut to 64: %d", len(long), len(name))
+	}
+}
+
+func TestWriteField(t *testing.T) {
+	b := &bytes.Buffer{}
+	writeField(b, "MESSAGE", "one line")
+	if b.String() != "MESSAGE=one line\n" {
+		t.Fatalf("%q", b)
+	}
+
+	b.Reset()
+	writeField(b, "MESSAGE", "two\nlines")
+
+	expected := &bytes.Buffer{}
+	expected.WriteString("MESSAGE\n")
+	_ = binary.Write(expected, binary.LittleEndian, uint64(len("two\nlines")))
+	expected.WriteString("two\nlines\n")
+
+	if !bytes.Equal(b.Bytes(), expected.Bytes()) {
+		t.Fatalf("%q, expected %q", b, expected)
+	}
+}
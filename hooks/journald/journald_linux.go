@@ -0,0 +1,73 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"syscall"
+)
+
+type unixJournalConn struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+func dialJournal(socketPath string) (journalConn, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: "", Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &unixJournalConn{
+		conn: conn,
+		addr: &net.UnixAddr{Name: socketPath, Net: "unixgram"},
+	}, nil
+}
+
+// send sends msg as a datagram, the message too large for a datagram is
+// written into an unlinked temp file and its fd is passed instead, as journald
+// accepts
+func (p *unixJournalConn) send(msg []byte) error {
+	_, _, err := p.conn.WriteMsgUnix(msg, nil, p.addr)
+	if err == nil {
+		return nil
+	}
+
+	if !isMsgSize(err) {
+		return err
+	}
+
+	f, err := ioutil.TempFile("/dev/shm", "logrus-journald-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = os.Remove(f.Name()); err != nil {
+		return err
+	}
+
+	if _, err = f.Write(msg); err != nil {
+		return err
+	}
+
+	_, _, err = p.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), p.addr)
+
+	return err
+}
+
+func (p *unixJournalConn) Close() error {
+	return p.conn.Close()
+}
+
+func isMsgSize(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+			return sysErr.Err == syscall.EMSGSIZE || sysErr.Err == syscall.ENOBUFS
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// mockJournal is the journald socket receiving the datagrams of hook
+type mockJournal struct {
+	path string
+	conn *net.UnixConn
+}
+
+func newMockJournal(t *testing.T) *mockJournal {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &mockJournal{path: path, conn: conn}
+}
+
+// receive reads a datagram, or the memfd passed for the large message, and
+// parses its fields
+func (p *mockJournal) receive(t *testing.T) map[string]string {
+	t.Helper()
+
+	_ = p.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 1<<20)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := p.conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "journal-fd")
+		defer f.Close()
+		if _, err = f.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if data, err = ioutil.ReadAll(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return parseFields(t, data)
+}
+
+// parseFields parses KEY=value\n and the binary safe KEY\n<len>value\n
+func parseFields(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("malformed %q", data)
+		}
+
+		key := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[key] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[key] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func newTestHook(t *testing.T, conf string) *JournaldHook {
+	t.Helper()
+
+	hook, err := NewJournaldHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = hook.(*JournaldHook).Close() })
+	return hook.(*JournaldHook)
+}
+
+func newEntry(level logrus.Level, msg string, data logrus.Fields) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New()).WithFields(data)
+	entry.Level = level
+	entry.Message = msg
+	return entry
+}
+
+func TestJournaldFields(t *testing.T) {
+	journal := newMockJournal(t)
+	hook := newTestHook(t, `socket-path = "`+journal.path+`", identifier = "myapp"`)
+
+	err := hook.Fire(newEntry(logrus.WarnLevel, "disk almost full", logrus.Fields{
+		"request-id": "r1",
+		"used":       0.93,
+		"error":      os.ErrPermission,
+		"stack":      "line1\nline2",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := journal.receive(t)
+	for k, v := range map[string]string{
+		"MESSAGE":           "disk almost full",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"REQUEST_ID":        "r1",
+		"USED":              "0.93",
+		"ERROR":             os.ErrPermission.Error(),
+		"STACK":             "line1\nline2",
+	} {
+		if fields[k] != v {
+			t.Errorf("%s: %q, expected %q", k, fields[k], v)
+		}
+	}
+}
+
+func TestJournaldPriorities(t *testing.T) {
+	journal := newMockJournal(t)
+	hook := newTestHook(t, `socket-path = "`+journal.path+`"`)
+
+	for level, priority := range map[logrus.Level]string{
+		logrus.PanicLevel: "0",
+		logrus.FatalLevel: "2",
+		logrus.ErrorLevel: "3",
+		logrus.WarnLevel:  "4",
+		logrus.InfoLevel:  "6",
+		logrus.DebugLevel: "7",
+	} {
+		if err := hook.Fire(newEntry(level, "msg", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if fields := journal.receive(t); fields["PRIORITY"] != priority {
+			t.Errorf("%s: priority %q, expected %s", level, fields["PRIORITY"], priority)
+		}
+	}
+}
+
+func TestJournaldLargeMessage(t *testing.T) {
+	if _, err := os.Stat("/dev/shm"); err != nil {
+		t.Skip("no /dev/shm")
+	}
+
+	journal := newMockJournal(t)
+	hook := newTestHook(t, `socket-path = "`+journal.path+`"`)
+
+	// larger than the datagram, passed by fd
+	msg := strings.Repeat("x", 512<<10)
+	if err := hook.Fire(newEntry(logrus.InfoLevel, msg, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields := journal.receive(t); fields["MESSAGE"] != msg {
+		t.Fatalf("message of %d bytes, expected %d", len(fields["MESSAGE"]), len(msg))
+	}
+}
+
+func TestJournaldReconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.socket")
+	hook := newTestHook(t, `socket-path = "`+path+`"`)
+
+	if err := hook.Fire(newEntry(logrus.InfoLevel, "lost", nil)); err == nil {
+		t.Fatal("expected error without journald")
+	}
+
+	// journald started later
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal := &mockJournal{path: path, conn: conn}
+	defer conn.Close()
+
+	if err = hook.Fire(newEntry(logrus.InfoLevel, "delivered", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if fields := journal.receive(t); fields["MESSAGE"] != "delivered" {
+		t.Fatalf("fields %v", fields)
+	}
+}
+
+func TestJournaldLevels(t *testing.T) {
+	hook := newTestHook(t, `levels = ["error", "warn"]`)
+	if levels := hook.Levels(); len(levels) != 2 || levels[0] != logrus.ErrorLevel || levels[1] != logrus.WarnLevel {
+		t.Fatalf("levels %v", levels)
+	}
+
+	if _, err := NewJournaldHook(config.NewConfig(config.ConfigString(`levels = ["loud"]`))); err == nil {
+		t.Fatal("expected error of unknown level")
+	}
+}
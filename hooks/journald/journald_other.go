@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package journald
+
+// journald is linux only, the entries are discarded on other platforms
+type nopJournalConn struct{}
+
+func dialJournal(socketPath string) (journalConn, error) {
+	return nopJournalConn{}, nil
+}
+
+func (nopJournalConn) send(msg []byte) error {
+	return nil
+}
+
+func (nopJournalConn) Close() error {
+	return nil
+}
@@ -21,7 +21,7 @@ func NewLFShook(conf config.Configuration) (hook logrus.Hook, err error) {
 
 	for _, key := range pathMapConf.Keys() {
 		var lvl logrus.Level
-		lvl, err = logrus.ParseLevel(key)
+		lvl, err = logrus_mate.ParseLevel(key)
 		if err != nil {
 			return
 		}
@@ -0,0 +1,153 @@
+package mask
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type FieldMask struct {
+	// last4, lastN, firstN, email or full
+	Strategy string
+	// revealed chars of lastN and firstN, or of the email local part
+	N int
+}
+
+type MaskHookConfig struct {
+	Fields    map[string]FieldMask
+	MaskChar  string
+	Stringify bool
+}
+
+func init() {
+	logrus_mate.RegisterHook("mask", NewMaskHook)
+}
+
+var allLevels = []logrus.Level{
+	logrus.PanicLevel,
+	logrus.FatalLevel,
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+	logrus.InfoLevel,
+	logrus.DebugLevel,
+	logrus.TraceLevel,
+}
+
+// NewMaskHook creates the hook from config like:
+// fields { card = "last4", phone { strategy = "lastN", n = 2 }, email = "email" }
+func NewMaskHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := MaskHookConfig{
+		Fields:   make(map[string]FieldMask),
+		MaskChar: "*",
+	}
+
+	if config != nil {
+		conf.MaskChar = config.GetString("mask-char", conf.MaskChar)
+		conf.Stringify = config.GetBoolean("stringify", false)
+
+		if fieldsConf := config.GetConfig("fields"); fieldsConf != nil {
+			for _, field := range fieldsConf.Keys() {
+				mask := FieldMask{N: 3}
+				if fieldsConf.IsObject(field) {
+					maskConf := fieldsConf.GetConfig(field)
+					mask.Strategy = maskConf.GetString("strategy")
+					mask.N = int(maskConf.GetInt32("n", 3))
+				} else {
+					mask.Strategy = fieldsConf.GetString(field)
+				}
+
+				switch mask.Strategy {
+				case "last4", "lastN", "firstN", "email", "full":
+				default:
+					err = fmt.Errorf("logurs mate: unknown mask strategy %q of field %s", mask.Strategy, field)
+					return
+				}
+
+				conf.Fields[field] = mask
+			}
+		}
+	}
+
+	if len([]rune(conf.MaskChar)) != 1 {
+		err = fmt.Errorf("logurs mate: mask-char should be one char: %q", conf.MaskChar)
+		return
+	}
+
+	hook = &MaskHook{Config: conf, maskChar: []rune(conf.MaskChar)[0]}
+
+	return
+}
+
+// MaskHook masks the configured fields while revealing a safe portion, the values
+// which are not string are skipped unless Stringify
+type MaskHook struct {
+	Config   MaskHookConfig
+	maskChar rune
+}
+
+// PreHook makes the hook fire before the other hooks of logger whatever the
+// order of config, so no hook or output sees the unmasked values
+func (p *MaskHook) PreHook() {}
+
+func (p *MaskHook) Levels() []logrus.Level {
+	return allLevels
+}
+
+func (p *MaskHook) Fire(entry *logrus.Entry) (err error) {
+	for field, mask := range p.Config.Fields {
+		v, exist := entry.Data[field]
+		if !exist {
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			if !p.Config.Stringify || v == nil {
+				continue
+			}
+			s = fmt.Sprint(v)
+		}
+
+		entry.Data[field] = p.mask(s, mask)
+	}
+
+	return
+}
+
+func (p *MaskHook) mask(s string, mask FieldMask) string {
+	switch mask.Strategy {
+	case "last4":
+		return p.reveal(s, 0, 4)
+	case "lastN":
+		return p.reveal(s, 0, mask.N)
+	case "firstN":
+		return p.reveal(s, mask.N, 0)
+	case "email":
+		at := strings.LastIndex(s, "@")
+		if at <= 0 {
+			return p.reveal(s, 0, 0)
+		}
+		return p.reveal(s[:at], mask.N, 0) + s[at:]
+	}
+
+	return p.reveal(s, 0, 0)
+}
+
+// reveal keeps the first and last chars and masks the others, it masks all
+// when the reveal would leave nothing hidden
+func (p *MaskHook) reveal(s string, first, last int) string {
+	r := []rune(s)
+	if first+last >= len(r) {
+		first, last = 0, 0
+	}
+
+	for i := first; i < len(r)-last; i++ {
+		r[i] = p.maskChar
+	}
+
+	return string(r)
+}
@@ -0,0 +1,116 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestHook(t *testing.T, conf string) *MaskHook {
+	t.Helper()
+
+	hook, err := NewMaskHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook.(*MaskHook)
+}
+
+func fire(t *testing.T, hook *MaskHook, data logrus.Fields) logrus.Fields {
+	t.Helper()
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(data)
+	if err := hook.Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry.Data
+}
+
+func TestMaskStrategies(t *testing.T) {
+	hook := newTestHook(t, `
+		fields {
+			card = "last4"
+			phone { strategy = "lastN", n = 2 }
+			name { strategy = "firstN", n = 1 }
+			email = "email"
+			token = "full"
+		}`)
+
+	data := fire(t, hook, logrus.Fields{
+		"card":  "4111111111111111",
+		"phone": "13800138000",
+		"name":  "alice",
+		"email": "john.doe@example.com",
+		"token": "secret",
+		"other": "kept",
+	})
+
+	expected := logrus.Fields{
+		"card":  "************1111",
+		"phone": "*********00",
+		"name":  "a****",
+		"email": "joh*****@example.com",
+		"token": "******",
+		"other": "kept",
+	}
+
+	for k, v := range expected {
+		if data[k] != v {
+			t.Errorf("%s: %v, expected %v", k, data[k], v)
+		}
+	}
+}
+
+func TestMaskShortValues(t *testing.T) {
+	hook := newTestHook(t, `fields { card = "last4", email = "email" }`)
+
+	// the reveal leaving nothing hidden masks all
+	data := fire(t, hook, logrus.Fields{"card": "1234", "email": "invalid"})
+
+	if data["card"] != "****" || data["email"] != "*******" {
+		t.Fatalf("data %v", data)
+	}
+
+	data = fire(t, hook, logrus.Fields{"email": "ab@example.com"})
+	if data["email"] != "**@example.com" {
+		t.Fatalf("email %v", data["email"])
+	}
+}
+
+func TestMaskMultibyte(t *testing.T) {
+	hook := newTestHook(t, `mask-char = "#", fields { name { strategy = "firstN", n = 1 } }`)
+
+	if data := fire(t, hook, logrus.Fields{"name": "张三丰"}); data["name"] != "张##" {
+		t.Fatalf("name %v", data["name"])
+	}
+}
+
+func TestMaskNonString(t *testing.T) {
+	conf := `fields { card = "last4", id = "last4", missing = "full" }`
+
+	data := fire(t, newTestHook(t, conf), logrus.Fields{"card": 4111111111111111, "id": nil})
+	if data["card"] != 4111111111111111 || data["id"] != nil {
+		t.Fatalf("the values not string should be skipped: %v", data)
+	}
+	if _, exist := data["missing"]; exist {
+		t.Fatal("the missing field should not be added")
+	}
+
+	data = fire(t, newTestHook(t, "stringify = true, "+conf), logrus.Fields{"card": 4111111111111111, "id": nil})
+	if data["card"] != "************1111" || data["id"] != nil {
+		t.Fatalf("the values should be stringified: %v", data)
+	}
+}
+
+func TestMaskInvalidConfig(t *testing.T) {
+	for _, conf := range []string{
+		`fields { card = "last3" }`,
+		`fields { card { strategy = "middle" } }`,
+		`mask-char = "**", fields { card = "last4" }`,
+	} {
+		if _, err := NewMaskHook(config.NewConfig(config.ConfigString(conf))); err == nil {
+			t.Errorf("%s: expected error", conf)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gogap/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+var allLevels = []logrus.Level{
+	logrus.DebugLevel,
+	logrus.InfoLevel,
+	logrus.WarnLevel,
+	logrus.ErrorLevel,
+	logrus.FatalLevel,
+	logrus.PanicLevel,
+}
+
+type MetricConfig struct {
+	Name    string
+	Type    string
+	Help    string
+	Field   string
+	Labels  []string
+	Buckets []float64
+}
+
+type MetricsHookConfig struct {
+	Namespace string
+	Levels    []string
+	Metrics   []MetricConfig
+}
+
+func init() {
+	logrus_mate.RegisterHook("metrics", NewMetricsHook)
+}
+
+// NewMetricsHook create the hook with metrics registered into prometheus.DefaultRegisterer
+func NewMetricsHook(config config.Configuration) (hook logrus.Hook, err error) {
+	return NewMetricsHookWithRegisterer(config, prometheus.DefaultRegisterer)
+}
+
+func NewMetricsHookWithRegisterer(config config.Configuration, registerer prometheus.Registerer) (hook logrus.Hook, err error) {
+	conf := MetricsHookConfig{}
+
+	if config != nil {
+		conf.Namespace = config.GetString("namespace")
+		conf.Levels = config.GetStringList("levels")
+
+		if metricsConf := config.GetConfig("metrics"); metricsConf != nil {
+			for _, name := range metricsConf.Keys() {
+				metricConf := metricsConf.GetConfig(name)
+				conf.Metrics = append(conf.Metrics, MetricConfig{
+					Name:    name,
+					Type:    metricConf.GetString("type", "counter"),
+					Help:    metricConf.GetString("help", name),
+					Field:   metricConf.GetString("field"),
+					Labels:  metricConf.GetStringList("labels"),
+					Buckets: metricConf.GetFloat64List("buckets"),
+				})
+			}
+		}
+	}
+
+	levels := []logrus.Level{}
+
+	for _, level := range conf.Levels {
+		if lv, e := logrus_mate.ParseLevel(level); e != nil {
+			err = e
+			return
+		} else {
+			levels = append(levels, lv)
+		}
+	}
+
+	metricsHook := &MetricsHook{AcceptedLevels: levels}
+
+	for _, metricConf := range conf.Metrics {
+		var m *metric
+		if m, err = newMetric(conf.Namespace, metricConf, registerer); err != nil {
+			return
+		}
+		metricsHook.metrics = append(metricsHook.metrics, m)
+	}
+
+	hook = metricsHook
+
+	return
+}
+
+// MetricsHook observes counters and histograms labeled by entry fields,
+// an entry missing any label field or the value field is skipped by that metric.
+type MetricsHook struct {
+	AcceptedLevels []logrus.Level
+
+	metrics []*metric
+}
+
+func (p *MetricsHook) Levels() []logrus.Level {
+	if len(p.AcceptedLevels) == 0 {
+		return allLevels
+	}
+	return p.AcceptedLevels
+}
+
+func (p *MetricsHook) Fire(entry *logrus.Entry) (err error) {
+	for _, m := range p.metrics {
+		m.observe(entry)
+	}
+	return
+}
+
+type metric struct {
+	conf      MetricConfig
+	counter   *prometheus.CounterVec
+	histogram *prometheus.HistogramVec
+}
+
+func newMetric(namespace string, conf MetricConfig, registerer prometheus.Registerer) (m *metric, err error) {
+	m = &metric{conf: conf}
+
+	var collector prometheus.Collector
+
+	switch conf.Type {
+	case "counter":
+		m.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      conf.Name,
+			Help:      conf.Help,
+		}, conf.Labels)
+		collector = m.counter
+	case "histogram":
+		if len(conf.Field) == 0 {
+			err = fmt.Errorf("logurs mate: metric %s of histogram did not assign field", conf.Name)
+			return
+		}
+
+		buckets := conf.Buckets
+		if len(buckets) == 0 {
+			buckets = prometheus.DefBuckets
+		}
+
+		m.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      conf.Name,
+			Help:      conf.Help,
+			Buckets:   buckets,
+		}, conf.Labels)
+		collector = m.histogram
+	default:
+		err = fmt.Errorf("logurs mate: unknown metric type %s of %s", conf.Type, conf.Name)
+		return
+	}
+
+	if err = registerer.Register(collector); err != nil {
+		// the same metric was registered by another logger, share it
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return
+		}
+
+		err = nil
+
+		if m.counter != nil {
+			m.counter, ok = are.ExistingCollector.(*prometheus.CounterVec)
+		} else {
+			m.histogram, ok = are.ExistingCollector.(*prometheus.HistogramVec)
+		}
+
+		if !ok {
+			err = fmt.Errorf("logurs mate: metric %s was registered with another type", conf.Name)
+		}
+	}
+
+	return
+}
+
+func (p *metric) observe(entry *logrus.Entry) {
+	labels := make(prometheus.Labels, len(p.conf.Labels))
+	for _, label := range p.conf.Labels {
+		v, exist := entry.Data[label]
+		if !exist {
+			return
+		}
+		labels[label] = fmt.Sprint(v)
+	}
+
+	if p.counter != nil {
+		if len(p.conf.Field) > 0 {
+			if _, exist := entry.Data[p.conf.Field]; !exist {
+				return
+			}
+		}
+		p.counter.With(labels).Inc()
+		return
+	}
+
+	v, ok := toFloat64(entry.Data[p.conf.Field])
+	if !ok {
+		return
+	}
+
+	p.histogram.With(labels).Observe(v)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMetricsHookLabels(t *testing.T) {
+	conf := config.NewConfig(config.ConfigString(`
+namespace = "app"
+metrics {
+    requests {
+        type   = "counter"
+        labels = ["route"]
+    }
+    latency {
+        type    = "histogram"
+        field   = "latency"
+        labels  = ["route"]
+        buckets = [0.1, 1]
+    }
+}`))
+
+	registry := prometheus.NewRegistry()
+
+	hook, err := NewMetricsHookWithRegisterer(conf, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	for _, fields := range []logrus.Fields{
+		{"route": "/a", "latency": 0.5},
+		{"route": "/a", "latency": "0.05"},
+		{"route": "/b"},
+		// no label field, skipped by both
+		{"latency": 0.5},
+	} {
+		entry := logrus.NewEntry(logger).WithFields(fields)
+		entry.Level = logrus.InfoLevel
+		if err = hook.Fire(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			route := ""
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "route" {
+					route = label.GetValue()
+				}
+			}
+
+			switch family.GetName() {
+			case "app_requests":
+				counts["requests "+route] = m.GetCounter().GetValue()
+			case "app_latency":
+				counts["latency "+route] = float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		"requests /a": 2,
+		"requests /b": 1,
+		"latency /a":  2,
+	}
+
+	if len(counts) != len(expected) {
+		t.Fatalf("got %v, expected %v", counts, expected)
+	}
+	for k, v := range expected {
+		if counts[k] != v {
+			t.Fatalf("got %v, expected %v", counts, expected)
+		}
+	}
+}
+
+func TestMetricsHookSharedAcrossLoggers(t *testing.T) {
+	conf := config.NewConfig(config.ConfigString(`metrics.requests.labels = ["route"]`))
+
+	registry := prometheus.NewRegistry()
+
+	// the second logger of the same metric shares the registered collector
+	for i := 0; i < 2; i++ {
+		if _, err := NewMetricsHookWithRegisterer(conf, registry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
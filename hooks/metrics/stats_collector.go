@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gogap/logrus_mate/hooks/utils/dispatcher"
+)
+
+// StatsCollector exports the backpressure stats of an async hook or dispatcher,
+// the stats are read at every scrape, e.g.
+// prometheus.MustRegister(metrics.NewStatsCollector("app", "unixsocket", hook.Stats))
+type StatsCollector struct {
+	stats func() dispatcher.Stats
+
+	queueDepth       *prometheus.Desc
+	capacity         *prometheus.Desc
+	dropped          *prometheus.Desc
+	flushes          *prometheus.Desc
+	lastFlushLatency *prometheus.Desc
+	maxFlushLatency  *prometheus.Desc
+}
+
+func NewStatsCollector(namespace, component string, stats func() dispatcher.Stats) *StatsCollector {
+	labels := prometheus.Labels{"component": component}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "log_async", name), help, nil, labels)
+	}
+
+	return &StatsCollector{
+		stats:            stats,
+		queueDepth:       desc("queue_depth", "entries buffered"),
+		capacity:         desc("queue_capacity", "capacity of the buffer"),
+		dropped:          desc("dropped_total", "entries dropped by full buffer"),
+		flushes:          desc("flushes_total", "flushes to the backend"),
+		lastFlushLatency: desc("last_flush_seconds", "latency of the last flush"),
+		maxFlushLatency:  desc("max_flush_seconds", "max latency of the flushes"),
+	}
+}
+
+func (p *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.queueDepth
+	ch <- p.capacity
+	ch <- p.dropped
+	ch <- p.flushes
+	ch <- p.lastFlushLatency
+	ch <- p.maxFlushLatency
+}
+
+func (p *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := p.stats()
+
+	ch <- prometheus.MustNewConstMetric(p.queueDepth, prometheus.GaugeValue, float64(stats.QueueDepth))
+	ch <- prometheus.MustNewConstMetric(p.capacity, prometheus.GaugeValue, float64(stats.Capacity))
+	ch <- prometheus.MustNewConstMetric(p.dropped, prometheus.CounterValue, float64(stats.Dropped))
+	ch <- prometheus.MustNewConstMetric(p.flushes, prometheus.CounterValue, float64(stats.Flushes))
+	ch <- prometheus.MustNewConstMetric(p.lastFlushLatency, prometheus.GaugeValue, stats.LastFlushLatency.Seconds())
+	ch <- prometheus.MustNewConstMetric(p.maxFlushLatency, prometheus.GaugeValue, stats.MaxFlushLatency.Seconds())
+}
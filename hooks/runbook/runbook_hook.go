@@ -0,0 +1,157 @@
+package runbook
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type RunbookPattern struct {
+	Name  string
+	Match *regexp.Regexp
+	URL   string
+}
+
+type RunbookHookConfig struct {
+	CodeField string
+	Field     string
+	Codes     map[string]string
+	Patterns  []RunbookPattern
+}
+
+func init() {
+	logrus_mate.RegisterHook("runbook", NewRunbookHook)
+}
+
+// NewRunbookHook creates the hook from config like:
+// codes { E1001 = "https://wiki/runbooks/e1001" }
+// patterns { db-timeout { match = "timeout.*mysql", url = "https://wiki/runbooks/db" } }
+// the codes and patterns could also be loaded from an external file by `file`
+func NewRunbookHook(conf config.Configuration) (hook logrus.Hook, err error) {
+	hookConf := RunbookHookConfig{
+		CodeField: "error_code",
+		Field:     "runbook",
+		Codes:     make(map[string]string),
+	}
+
+	if conf != nil {
+		hookConf.CodeField = conf.GetString("code-field", hookConf.CodeField)
+		hookConf.Field = conf.GetString("field", hookConf.Field)
+
+		if file := conf.GetString("file"); len(file) > 0 {
+			if _, err = os.Stat(file); err != nil {
+				err = fmt.Errorf("logurs mate: runbook file: %s", err)
+				return
+			}
+
+			fileConf := config.NewConfig(config.ConfigFile(file))
+			if err = loadRunbooks(&hookConf, fileConf); err != nil {
+				return
+			}
+		}
+
+		// the runbooks in mate config override the ones in file
+		if err = loadRunbooks(&hookConf, conf); err != nil {
+			return
+		}
+	}
+
+	hook = &RunbookHook{Config: hookConf}
+
+	return
+}
+
+func loadRunbooks(hookConf *RunbookHookConfig, conf config.Configuration) (err error) {
+	if conf == nil {
+		return
+	}
+
+	if codesConf := conf.GetConfig("codes"); codesConf != nil {
+		for _, code := range codesConf.Keys() {
+			hookConf.Codes[code] = codesConf.GetString(code)
+		}
+	}
+
+	patternsConf := conf.GetConfig("patterns")
+	if patternsConf == nil {
+		return
+	}
+
+	names := patternsConf.Keys()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !patternsConf.IsObject(name) {
+			err = fmt.Errorf("logurs mate: runbook pattern %s should be { match, url }", name)
+			return
+		}
+		patternConf := patternsConf.GetConfig(name)
+
+		pattern := RunbookPattern{Name: name, URL: patternConf.GetString("url")}
+		if pattern.Match, err = regexp.Compile(patternConf.GetString("match")); err != nil {
+			err = fmt.Errorf("logurs mate: runbook pattern %s: %s", name, err)
+			return
+		}
+
+		replaced := false
+		for i := range hookConf.Patterns {
+			if hookConf.Patterns[i].Name == name {
+				hookConf.Patterns[i], replaced = pattern, true
+			}
+		}
+
+		if !replaced {
+			hookConf.Patterns = append(hookConf.Patterns, pattern)
+		}
+	}
+
+	return
+}
+
+// RunbookHook attaches the runbook url to the error entries, matched by the error
+// code field first, then by the patterns against the message and the error,
+// the unmatched entries are left untouched
+type RunbookHook struct {
+	Config RunbookHookConfig
+}
+
+func (p *RunbookHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.PanicLevel,
+		logrus.FatalLevel,
+		logrus.ErrorLevel,
+	}
+}
+
+func (p *RunbookHook) Fire(entry *logrus.Entry) (err error) {
+	if v, exist := entry.Data[p.Config.CodeField]; exist {
+		if url, exist := p.Config.Codes[fmt.Sprint(v)]; exist {
+			entry.Data[p.Config.Field] = url
+			return
+		}
+	}
+
+	if len(p.Config.Patterns) == 0 {
+		return
+	}
+
+	var errText string
+	if v, exist := entry.Data[logrus.ErrorKey]; exist {
+		errText = fmt.Sprint(v)
+	}
+
+	for _, pattern := range p.Config.Patterns {
+		if pattern.Match.MatchString(entry.Message) || (len(errText) > 0 && pattern.Match.MatchString(errText)) {
+			entry.Data[p.Config.Field] = pattern.URL
+			return
+		}
+	}
+
+	return
+}
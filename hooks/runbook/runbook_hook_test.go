@@ -0,0 +1,130 @@
+package runbook
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestHook(t *testing.T, conf string) *RunbookHook {
+	t.Helper()
+
+	hook, err := NewRunbookHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook.(*RunbookHook)
+}
+
+func fire(t *testing.T, hook *RunbookHook, msg string, data logrus.Fields) logrus.Fields {
+	t.Helper()
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(data)
+	entry.Level = logrus.ErrorLevel
+	entry.Message = msg
+	if err := hook.Fire(entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry.Data
+}
+
+const testRunbooks = `
+	codes { E1001 = "https://wiki/runbooks/e1001", "404" = "https://wiki/runbooks/404" }
+	patterns {
+		db { match = "timeout.*mysql", url = "https://wiki/runbooks/db" }
+		disk { match = "(?i)no space left", url = "https://wiki/runbooks/disk" }
+	}`
+
+func TestRunbookByCode(t *testing.T) {
+	hook := newTestHook(t, testRunbooks)
+
+	if data := fire(t, hook, "failed", logrus.Fields{"error_code": "E1001"}); data["runbook"] != "https://wiki/runbooks/e1001" {
+		t.Fatalf("data %v", data)
+	}
+
+	// the code not string matches by its text
+	if data := fire(t, hook, "failed", logrus.Fields{"error_code": 404}); data["runbook"] != "https://wiki/runbooks/404" {
+		t.Fatalf("data %v", data)
+	}
+
+	// the code wins over the patterns
+	if data := fire(t, hook, "timeout of mysql", logrus.Fields{"error_code": "E1001"}); data["runbook"] != "https://wiki/runbooks/e1001" {
+		t.Fatalf("data %v", data)
+	}
+}
+
+func TestRunbookByPattern(t *testing.T) {
+	hook := newTestHook(t, testRunbooks)
+
+	if data := fire(t, hook, "query timeout on mysql master", nil); data["runbook"] != "https://wiki/runbooks/db" {
+		t.Fatalf("message: %v", data)
+	}
+
+	data := fire(t, hook, "write failed", logrus.Fields{logrus.ErrorKey: errors.New("write /data: No space left on device")})
+	if data["runbook"] != "https://wiki/runbooks/disk" {
+		t.Fatalf("error: %v", data)
+	}
+
+	// the unknown code falls through to the patterns
+	if data := fire(t, hook, "timeout from mysql", logrus.Fields{"error_code": "E9999"}); data["runbook"] != "https://wiki/runbooks/db" {
+		t.Fatalf("unknown code: %v", data)
+	}
+}
+
+func TestRunbookUnmatched(t *testing.T) {
+	hook := newTestHook(t, testRunbooks)
+
+	data := fire(t, hook, "something else", logrus.Fields{"error_code": "E2002", "user": "bob"})
+	if _, exist := data["runbook"]; exist || len(data) != 2 {
+		t.Fatalf("the unmatched entry is changed: %v", data)
+	}
+}
+
+func TestRunbookFields(t *testing.T) {
+	hook := newTestHook(t, `code-field = "code", field = "help", codes { E1 = "https://wiki/e1" }`)
+
+	if data := fire(t, hook, "failed", logrus.Fields{"code": "E1"}); data["help"] != "https://wiki/e1" {
+		t.Fatalf("data %v", data)
+	}
+}
+
+func TestRunbookFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "runbooks.conf")
+	if err := ioutil.WriteFile(file, []byte(`
+		codes { E1 = "https://wiki/file/e1", E2 = "https://wiki/file/e2" }
+		patterns { db { match = "mysql", url = "https://wiki/file/db" } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mate config overrides the file
+	hook := newTestHook(t, `file = "`+filepath.ToSlash(file)+`", codes { E2 = "https://wiki/conf/e2" }, patterns { db { match = "mysql", url = "https://wiki/conf/db" } }`)
+
+	if data := fire(t, hook, "failed", logrus.Fields{"error_code": "E1"}); data["runbook"] != "https://wiki/file/e1" {
+		t.Fatalf("file code: %v", data)
+	}
+	if data := fire(t, hook, "failed", logrus.Fields{"error_code": "E2"}); data["runbook"] != "https://wiki/conf/e2" {
+		t.Fatalf("overridden code: %v", data)
+	}
+	if data := fire(t, hook, "mysql down", nil); data["runbook"] != "https://wiki/conf/db" {
+		t.Fatalf("overridden pattern: %v", data)
+	}
+	if len(hook.Config.Patterns) != 1 {
+		t.Fatalf("patterns %v", hook.Config.Patterns)
+	}
+}
+
+func TestRunbookInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`patterns { db { match = "(", url = "https://wiki/db" } }`,
+		`patterns { db = "https://wiki/db" }`,
+		`file = "/not/exist/runbooks.conf"`,
+	} {
+		if _, err := NewRunbookHook(config.NewConfig(config.ConfigString(conf))); err == nil {
+			t.Errorf("%s: expected error", conf)
+		}
+	}
+}
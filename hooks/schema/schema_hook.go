@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+const ViolationKey = "_schema_violation"
+
+type SchemaHookConfig struct {
+	Mode     string
+	Required map[logrus.Level][]string
+}
+
+func init() {
+	logrus_mate.RegisterHook("schema", NewSchemaHook)
+}
+
+func NewSchemaHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := SchemaHookConfig{
+		Mode:     "mark",
+		Required: make(map[logrus.Level][]string),
+	}
+
+	if config != nil {
+		conf.Mode = config.GetString("mode", conf.Mode)
+
+		if requiredConf := config.GetConfig("required"); requiredConf != nil {
+			for _, key := range requiredConf.Keys() {
+				var lvl logrus.Level
+				if lvl, err = logrus_mate.ParseLevel(key); err != nil {
+					return
+				}
+				conf.Required[lvl] = requiredConf.GetStringList(key)
+			}
+		}
+	}
+
+	if conf.Mode != "mark" && conf.Mode != "warn" {
+		err = fmt.Errorf("logurs mate: schema mode should be mark or warn: %s", conf.Mode)
+		return
+	}
+
+	hook = &SchemaHook{Config: conf, stderr: os.Stderr}
+
+	return
+}
+
+// SchemaHook checks the required fields of entry level, the missing fields
+// are marked into ViolationKey field, or warned to stderr once per message
+type SchemaHook struct {
+	Config SchemaHookConfig
+
+	warned sync.Map
+	stderr io.Writer
+}
+
+func (p *SchemaHook) Levels() []logrus.Level {
+	levels := make([]logrus.Level, 0, len(p.Config.Required))
+	for lvl := range p.Config.Required {
+		levels = append(levels, lvl)
+	}
+	return levels
+}
+
+func (p *SchemaHook) Fire(entry *logrus.Entry) (err error) {
+	var missing []string
+	for _, field := range p.Config.Required[entry.Level] {
+		if _, exist := entry.Data[field]; !exist {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	strMissing := strings.Join(missing, ",")
+
+	if p.Config.Mode == "mark" {
+		entry.Data[ViolationKey] = "missing: " + strMissing
+		return
+	}
+
+	key := entry.Level.String() + "|" + entry.Message + "|" + strMissing
+	if _, warned := p.warned.LoadOrStore(key, true); !warned {
+		_, _ = fmt.Fprintf(p.stderr, "logurs mate: schema violation, %s entry %q missing fields: %s\n", entry.Level, entry.Message, strMissing)
+	}
+
+	return
+}
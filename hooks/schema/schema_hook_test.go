@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestHook(t *testing.T, conf string) *SchemaHook {
+	t.Helper()
+
+	hook, err := NewSchemaHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook.(*SchemaHook)
+}
+
+func newEntry(level logrus.Level, message string, fields logrus.Fields) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	entry.Level = level
+	entry.Message = message
+	return entry
+}
+
+func TestSchemaMark(t *testing.T) {
+	hook := newTestHook(t, `required.error = ["service", "error_code"]`)
+
+	if levels := hook.Levels(); len(levels) != 1 || levels[0] != logrus.ErrorLevel {
+		t.Fatalf("levels %v", levels)
+	}
+
+	compliant := newEntry(logrus.ErrorLevel, "failed", logrus.Fields{"service": "api", "error_code": 42})
+	_ = hook.Fire(compliant)
+	if _, exist := compliant.Data[ViolationKey]; exist {
+		t.Fatalf("the compliant entry is marked: %v", compliant.Data)
+	}
+
+	violating := newEntry(logrus.ErrorLevel, "failed", logrus.Fields{"service": "api"})
+	_ = hook.Fire(violating)
+	if v := violating.Data[ViolationKey]; v != "missing: error_code" {
+		t.Fatalf("the violation %v", v)
+	}
+}
+
+func TestSchemaWarnOnce(t *testing.T) {
+	hook := newTestHook(t, `
+mode = "warn"
+required.error = ["service"]`)
+
+	stderr := &bytes.Buffer{}
+	hook.stderr = stderr
+
+	for i := 0; i < 3; i++ {
+		entry := newEntry(logrus.ErrorLevel, "failed", nil)
+		_ = hook.Fire(entry)
+		if _, exist := entry.Data[ViolationKey]; exist {
+			t.Fatal("the warn mode marks the entry")
+		}
+	}
+	_ = hook.Fire(newEntry(logrus.ErrorLevel, "failed", logrus.Fields{"service": "api"}))
+
+	if n := strings.Count(stderr.String(), "missing fields: service"); n != 1 {
+		t.Fatalf("warned %d times: %q", n, stderr)
+	}
+}
+
+func TestSchemaBadConfig(t *testing.T) {
+	for _, conf := range []string{`mode = "drop"`, `required.verbose = ["service"]`} {
+		if _, err := NewSchemaHook(config.NewConfig(config.ConfigString(conf))); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package stack
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+)
+
+type StackHookConfig struct {
+	Field       string
+	RepeatField string
+	Levels      []logrus.Level
+	// the repeats of the error message within the window get the repeat count instead of the stack
+	OnceWindow time.Duration
+	// max distinct messages of the window, the oldest is forgotten beyond
+	MaxKeys int
+}
+
+func init() {
+	logrus_mate.RegisterHook("stack", NewStackHook)
+}
+
+// NewStackHook creates the hook from config like:
+// field = "stack", levels = ["error", "fatal", "panic"], stack-once-window = 1m
+func NewStackHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := StackHookConfig{
+		Field:       "stack",
+		RepeatField: "stack_repeats",
+		Levels:      []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel},
+		MaxKeys:     1000,
+	}
+
+	if config != nil {
+		conf.Field = config.GetString("field", conf.Field)
+		conf.RepeatField = config.GetString("repeat-field", conf.RepeatField)
+		conf.OnceWindow = config.GetTimeDuration("stack-once-window", 0)
+		conf.MaxKeys = int(config.GetInt32("max-keys", int32(conf.MaxKeys)))
+
+		if levels := config.GetStringList("levels"); len(levels) > 0 {
+			conf.Levels = nil
+			for _, level := range levels {
+				var lv logrus.Level
+				if lv, err = logrus_mate.ParseLevel(level); err != nil {
+					return
+				}
+				conf.Levels = append(conf.Levels, lv)
+			}
+		}
+	}
+
+	if conf.OnceWindow < 0 || conf.MaxKeys <= 0 {
+		err = fmt.Errorf("logurs mate: stack-once-window should not be negative and max-keys should be positive: %s, %d", conf.OnceWindow, conf.MaxKeys)
+		return
+	}
+
+	hook = &StackHook{Config: conf, seen: make(map[string]*occurrence), now: time.Now}
+
+	return
+}
+
+type occurrence struct {
+	first   time.Time
+	repeats int
+}
+
+// StackHook attaches the stack of the logging call. With OnceWindow the stack is
+// attached only to the first entry of the same message within the window, the
+// repeats get the repeat count in RepeatField instead, so the identical stacks
+// do not balloon the log.
+type StackHook struct {
+	Config StackHookConfig
+
+	locker sync.Mutex
+	seen   map[string]*occurrence
+	now    func() time.Time
+}
+
+func (p *StackHook) Levels() []logrus.Level {
+	return p.Config.Levels
+}
+
+func (p *StackHook) Fire(entry *logrus.Entry) (err error) {
+	if p.Config.OnceWindow > 0 {
+		if repeats := p.repeat(entry.Message); repeats > 0 {
+			entry.Data[p.Config.RepeatField] = repeats
+			return
+		}
+	}
+
+	entry.Data[p.Config.Field] = callStack()
+
+	return
+}
+
+// repeat returns the repeat count of message within the window, 0 for the first
+func (p *StackHook) repeat(message string) int {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	now := p.now()
+
+	if o, exist := p.seen[message]; exist && now.Sub(o.first) < p.Config.OnceWindow {
+		o.repeats++
+		return o.repeats
+	}
+
+	if len(p.seen) >= p.Config.MaxKeys {
+		p.evict(now)
+	}
+
+	p.seen[message] = &occurrence{first: now}
+
+	return 0
+}
+
+// evict forgets the expired messages, or the oldest one when none expired
+func (p *StackHook) evict(now time.Time) {
+	oldest := ""
+	for message, o := range p.seen {
+		if now.Sub(o.first) >= p.Config.OnceWindow {
+			delete(p.seen, message)
+			continue
+		}
+		if oldest == "" || o.first.Before(p.seen[oldest].first) {
+			oldest = message
+		}
+	}
+
+	if len(p.seen) >= p.Config.MaxKeys && oldest != "" {
+		delete(p.seen, oldest)
+	}
+}
+
+// callStack formats the stack from the logging call, the frames of logrus and mate are skipped
+func callStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	b := strings.Builder{}
+	skipping := true
+
+	for {
+		frame, more := frames.Next()
+
+		if skipping && !isLoggingFrame(frame.Function) {
+			skipping = false
+		}
+
+		if !skipping {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+func isLoggingFrame(function string) bool {
+	return strings.HasPrefix(function, "github.com/sirupsen/logrus.") ||
+		strings.HasPrefix(function, "github.com/gogap/logrus_mate.") ||
+		strings.HasPrefix(function, "github.com/gogap/logrus_mate/hooks/stack.")
+}
@@ -0,0 +1,132 @@
+package stack
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestLogger returns the logger with the stack hook of conf, the data of
+// the entries fired are sent to the returned channel
+func newTestLogger(t *testing.T, conf string) (*logrus.Logger, *StackHook, chan logrus.Fields) {
+	t.Helper()
+
+	hook, err := NewStackHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(chan logrus.Fields, 100)
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.AddHook(hook)
+	logger.AddHook(dataHook(fields))
+
+	return logger, hook.(*StackHook), fields
+}
+
+type dataHook chan logrus.Fields
+
+func (dataHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p dataHook) Fire(entry *logrus.Entry) error {
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	p <- data
+	return nil
+}
+
+func TestStackOnceWithinWindow(t *testing.T) {
+	logger, hook, fields := newTestLogger(t, `stack-once-window = 1m`)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	logger.Error("db down")
+	first := <-fields
+	stack, _ := first["stack"].(string)
+	if len(stack) == 0 || strings.HasPrefix(stack, "github.com/sirupsen/logrus.") {
+		t.Fatalf("the first error has the stack %q", stack)
+	}
+	if _, exist := first["stack_repeats"]; exist {
+		t.Fatalf("the first error has the repeats %v", first)
+	}
+
+	// the immediate repeats get the count instead of the stack
+	for i := 1; i <= 3; i++ {
+		logger.Error("db down")
+		data := <-fields
+		if _, exist := data["stack"]; exist || data["stack_repeats"] != i {
+			t.Fatalf("repeat %d has %v", i, data)
+		}
+	}
+
+	// another message has its own stack
+	logger.Error("disk full")
+	if data := <-fields; data["stack"] == nil {
+		t.Fatalf("another message %v", data)
+	}
+
+	// after the window the stack is attached again
+	now = now.Add(time.Minute)
+	logger.Error("db down")
+	if data := <-fields; data["stack"] == nil || data["stack_repeats"] != nil {
+		t.Fatalf("after the window %v", data)
+	}
+}
+
+func TestStackEveryTimeWithoutWindow(t *testing.T) {
+	logger, _, fields := newTestLogger(t, `field = "trace", levels = ["warn"]`)
+
+	logger.Warn("slow")
+	logger.Warn("slow")
+	logger.Error("not in levels")
+
+	for i := 0; i < 2; i++ {
+		if data := <-fields; data["trace"] == nil {
+			t.Fatalf("warn %d has %v", i, data)
+		}
+	}
+	if data := <-fields; data["trace"] != nil {
+		t.Fatalf("the error has the stack %v", data)
+	}
+}
+
+func TestStackMaxKeys(t *testing.T) {
+	logger, hook, fields := newTestLogger(t, `stack-once-window = 1h, max-keys = 2`)
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	for _, message := range []string{"a", "b", "c"} {
+		logger.Error(message)
+		<-fields
+		now = now.Add(time.Second)
+	}
+
+	// a is the oldest and forgotten for c, so it gets the stack again
+	logger.Error("a")
+	if data := <-fields; data["stack"] == nil {
+		t.Fatalf("the forgotten message %v", data)
+	}
+	if len(hook.seen) > 2 {
+		t.Fatalf("%d messages kept", len(hook.seen))
+	}
+}
+
+func TestStackInvalid(t *testing.T) {
+	for _, conf := range []string{`stack-once-window = -1s`, `max-keys = 0`, `levels = ["loud"]`} {
+		if _, err := NewStackHook(config.NewConfig(config.ConfigString(conf))); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package unixsocket
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+	"github.com/gogap/logrus_mate/hooks/utils/dispatcher"
+)
+
+var allLevels = []logrus.Level{
+	logrus.TraceLevel,
+	logrus.DebugLevel,
+	logrus.InfoLevel,
+	logrus.WarnLevel,
+	logrus.ErrorLevel,
+	logrus.FatalLevel,
+	logrus.PanicLevel,
+}
+
+type UnixSocketHookConfig struct {
+	SocketPath        string
+	SocketType        string
+	Levels            []string
+	BufferSize        int
+	ReconnectInterval time.Duration
+	WriteTimeout      time.Duration
+}
+
+func init() {
+	logrus_mate.RegisterHook("unixsocket", NewUnixSocketHook)
+}
+
+func NewUnixSocketHook(config config.Configuration) (hook logrus.Hook, err error) {
+	conf := UnixSocketHookConfig{}
+
+	if config != nil {
+		conf.SocketPath = config.GetString("socket-path")
+		conf.SocketType = config.GetString("socket-type", "unixgram")
+		conf.Levels = config.GetStringList("levels")
+		conf.BufferSize = int(config.GetInt32("buffer-size", 1000))
+		conf.ReconnectInterval = config.GetTimeDuration("reconnect-interval", time.Second)
+		conf.WriteTimeout = config.GetTimeDuration("write-timeout", time.Second)
+	}
+
+	if len(conf.SocketPath) == 0 {
+		err = errors.New("logurs mate: unixsocket's socket-path is empty")
+		return
+	}
+
+	switch conf.SocketType {
+	case "unixgram", "dgram":
+		conf.SocketType = "unixgram"
+	case "unix", "stream":
+		conf.SocketType = "unix"
+	default:
+		err = fmt.Errorf("logurs mate: unixsocket's socket-type should be unixgram or unix: %s", conf.SocketType)
+		return
+	}
+
+	levels := []logrus.Level{}
+
+	for _, level := range conf.Levels {
+		if lv, e := logrus_mate.ParseLevel(level); e != nil {
+			err = e
+			return
+		} else {
+			levels = append(levels, lv)
+		}
+	}
+
+	hook = &UnixSocketHook{
+		AcceptedLevels: levels,
+		Config:         conf,
+	}
+
+	return
+}
+
+// UnixSocketHook writes the formatted entries to an unix domain socket,
+// while the socket is unavailable the entries are kept in a bounded buffer,
+// the oldest is dropped when it is full, and reconnect at the next Fire.
+// A write blocked by the peer longer than write-timeout is abandoned like a
+// failed write, so Fire never blocks the logger on a stuck reader.
+type UnixSocketHook struct {
+	AcceptedLevels []logrus.Level
+	Config         UnixSocketHookConfig
+
+	locker   sync.Mutex
+	conn     net.Conn
+	pending  [][]byte
+	dropped  int
+	lastDial time.Time
+
+	totalDropped uint64
+	flushStats   dispatcher.FlushStats
+}
+
+func (p *UnixSocketHook) Levels() []logrus.Level {
+	if len(p.AcceptedLevels) == 0 {
+		return allLevels
+	}
+	return p.AcceptedLevels
+}
+
+func (p *UnixSocketHook) Fire(entry *logrus.Entry) (err error) {
+	message, err := entry.String()
+	if err != nil {
+		return
+	}
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.Config.BufferSize > 0 && len(p.pending) >= p.Config.BufferSize {
+		p.pending = p.pending[1:]
+		p.dropped++
+		p.totalDropped++
+	}
+	p.pending = append(p.pending, []byte(message))
+
+	p.flush()
+
+	return
+}
+
+// flush must be called with locker held
+func (p *UnixSocketHook) flush() {
+	if p.conn == nil {
+		if time.Since(p.lastDial) < p.Config.ReconnectInterval {
+			return
+		}
+
+		p.lastDial = time.Now()
+
+		conn, err := net.Dial(p.Config.SocketType, p.Config.SocketPath)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v unixsocket(%q): dial failed, %d entries buffered: %s\n", time.Now(), p.Config.SocketPath, len(p.pending), err)
+			return
+		}
+
+		p.conn = conn
+
+		if p.dropped > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "%v unixsocket(%q): reconnected, %d entries dropped\n", time.Now(), p.Config.SocketPath, p.dropped)
+			p.dropped = 0
+		}
+	}
+
+	start := time.Now()
+	defer func() {
+		p.flushStats.Observe(time.Since(start))
+	}()
+
+	for len(p.pending) > 0 {
+		if p.Config.WriteTimeout > 0 {
+			_ = p.conn.SetWriteDeadline(time.Now().Add(p.Config.WriteTimeout))
+		}
+
+		if _, err := p.conn.Write(p.pending[0]); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v unixsocket(%q): write failed, reconnect later: %s\n", time.Now(), p.Config.SocketPath, err)
+			_ = p.conn.Close()
+			p.conn = nil
+			return
+		}
+		p.pending = p.pending[1:]
+	}
+}
+
+// Stats returns the buffered entries, drops and the latency of writing to the socket
+func (p *UnixSocketHook) Stats() (stats dispatcher.Stats) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	stats = dispatcher.Stats{
+		QueueDepth: len(p.pending),
+		Capacity:   p.Config.BufferSize,
+		Dropped:    p.totalDropped,
+	}
+	p.flushStats.Fill(&stats)
+	return
+}
+
+// Close flush the buffered entries and close the socket
+func (p *UnixSocketHook) Close() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	// dial immediately if disconnected
+	p.lastDial = time.Time{}
+	p.flush()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+
+	return err
+}
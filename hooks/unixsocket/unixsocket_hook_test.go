@@ -0,0 +1,134 @@
+package unixsocket
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestHook(t *testing.T, conf string) *UnixSocketHook {
+	t.Helper()
+
+	hook, err := NewUnixSocketHook(config.NewConfig(config.ConfigString(conf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = hook.(*UnixSocketHook).Close() })
+
+	return hook.(*UnixSocketHook)
+}
+
+func newEntry(level logrus.Level, message string) *logrus.Entry {
+	logger := logrus.New()
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+
+	entry := logrus.NewEntry(logger)
+	entry.Level = level
+	entry.Message = message
+	return entry
+}
+
+func listen(t *testing.T) (string, net.Listener) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "log.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	return path, l
+}
+
+func TestUnixSocketStream(t *testing.T) {
+	path, l := listen(t)
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	hook := newTestHook(t, fmt.Sprintf(`socket-path = %q, socket-type = "unix"`, path))
+
+	for _, level := range []logrus.Level{logrus.TraceLevel, logrus.InfoLevel} {
+		if err := hook.Fire(newEntry(level, "msg "+level.String())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, expected := range []string{"msg trace", "msg info"} {
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, expected) {
+				t.Fatalf("line %q, expected %q", line, expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%q is not received", expected)
+		}
+	}
+
+	if stats := hook.Stats(); stats.QueueDepth != 0 || stats.Dropped != 0 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
+
+func TestUnixSocketLevels(t *testing.T) {
+	hook := newTestHook(t, `socket-path = "/nonexistent.sock"`)
+
+	levels := hook.Levels()
+	if len(levels) != len(logrus.AllLevels) {
+		t.Fatalf("levels %v, expected all levels", levels)
+	}
+}
+
+func TestUnixSocketWriteTimeout(t *testing.T) {
+	path, l := listen(t)
+
+	// the peer accepts and never reads
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		<-time.After(10 * time.Second)
+		_ = conn.Close()
+	}()
+
+	hook := newTestHook(t, fmt.Sprintf(`socket-path = %q, socket-type = "unix", write-timeout = 50ms, reconnect-interval = 1h`, path))
+
+	message := strings.Repeat("x", 1<<20)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 8; i++ {
+			_ = hook.Fire(newEntry(logrus.InfoLevel, message))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fire is blocked by the peer not reading")
+	}
+
+	if stats := hook.Stats(); stats.QueueDepth == 0 {
+		t.Fatalf("the entries not written are not kept: %+v", stats)
+	}
+}
@@ -0,0 +1,174 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogap/config"
+)
+
+// State of the breaker
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// ErrOpen is returned by Do without calling while the breaker is open
+var ErrOpen = errors.New("breaker: open")
+
+type Options struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+// OptionsFromConfig reads failure-threshold (default 5) and open-duration (default 30s)
+func OptionsFromConfig(conf config.Configuration) (opts Options) {
+	opts = Options{
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}
+
+	if conf != nil {
+		opts.FailureThreshold = int(conf.GetInt32("failure-threshold", int32(opts.FailureThreshold)))
+		opts.OpenDuration = conf.GetTimeDuration("open-duration", opts.OpenDuration)
+	}
+
+	return
+}
+
+// Breaker stops calling the failing backend: it opens after FailureThreshold
+// consecutive failures and fails fast for OpenDuration, then half-opens to let
+// one call probe, which closes it by success or opens it again by failure.
+type Breaker struct {
+	opts Options
+
+	locker   sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	rejected uint64
+
+	now func() time.Time
+}
+
+func New(opts Options) *Breaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = 30 * time.Second
+	}
+
+	return &Breaker{opts: opts, now: time.Now}
+}
+
+// Do calls fn unless the breaker is open, the error or panic of fn is counted as failure
+func (p *Breaker) Do(fn func() error) (err error) {
+	allowed, probe := p.allow()
+	if !allowed {
+		atomic.AddUint64(&p.rejected, 1)
+		return ErrOpen
+	}
+
+	ok := false
+	defer func() { p.done(probe, ok) }()
+
+	err = fn()
+	ok = err == nil
+
+	return
+}
+
+// State returns the state, the open breaker past OpenDuration is half-open
+func (p *Breaker) State() State {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.state == Open && p.now().Sub(p.openedAt) >= p.opts.OpenDuration {
+		return HalfOpen
+	}
+	return p.state
+}
+
+// Rejected returns the count of calls failed fast while open
+func (p *Breaker) Rejected() uint64 {
+	return atomic.LoadUint64(&p.rejected)
+}
+
+// allow reports whether the call is allowed, and whether it is the probe of half-open
+func (p *Breaker) allow() (allowed, probe bool) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	switch p.state {
+	case Closed:
+		return true, false
+	case Open:
+		if p.now().Sub(p.openedAt) < p.opts.OpenDuration {
+			return false, false
+		}
+		p.state = HalfOpen
+	}
+
+	// only one probe at a time while half-open
+	if p.probing {
+		return false, false
+	}
+	p.probing = true
+
+	return true, true
+}
+
+// done records the result of call, the calls allowed before the breaker opened
+// count nothing after it
+func (p *Breaker) done(probe, ok bool) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if probe {
+		p.probing = false
+		if ok {
+			p.state = Closed
+			p.failures = 0
+		} else {
+			p.state = Open
+			p.openedAt = p.now()
+		}
+		return
+	}
+
+	if p.state != Closed {
+		return
+	}
+
+	if ok {
+		p.failures = 0
+		return
+	}
+
+	p.failures++
+	if p.failures >= p.opts.FailureThreshold {
+		p.state = Open
+		p.openedAt = p.now()
+	}
+}
@@ -0,0 +1,149 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errBackend = errors.New("backend down")
+
+// flaky is the mock backend failing while down, it counts the calls reaching it
+type flaky struct {
+	down  bool
+	calls int
+}
+
+func (p *flaky) call() error {
+	p.calls++
+	if p.down {
+		return errBackend
+	}
+	return nil
+}
+
+func newTestBreaker(threshold int, open time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b := New(Options{FailureThreshold: threshold, OpenDuration: open})
+	b.now = func() time.Time { return now }
+
+	return b, &now
+}
+
+func assertState(t *testing.T, b *Breaker, expected State) {
+	t.Helper()
+
+	if state := b.State(); state != expected {
+		t.Fatalf("state %s, expected %s", state, expected)
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	b, now := newTestBreaker(3, time.Minute)
+	backend := &flaky{down: true}
+
+	// closed until the consecutive failures reach the threshold
+	for i := 0; i < 2; i++ {
+		if err := b.Do(backend.call); err != errBackend {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		assertState(t, b, Closed)
+	}
+	_ = b.Do(backend.call)
+	assertState(t, b, Open)
+
+	// open fails fast without calling the backend
+	if err := b.Do(backend.call); err != ErrOpen {
+		t.Fatalf("open: %v", err)
+	}
+	if backend.calls != 3 || b.Rejected() != 1 {
+		t.Fatalf("calls %d, rejected %d", backend.calls, b.Rejected())
+	}
+
+	// half-open after the duration, the failed probe opens it again
+	*now = now.Add(time.Minute)
+	assertState(t, b, HalfOpen)
+	if err := b.Do(backend.call); err != errBackend {
+		t.Fatalf("probe: %v", err)
+	}
+	assertState(t, b, Open)
+	if err := b.Do(backend.call); err != ErrOpen {
+		t.Fatalf("reopened: %v", err)
+	}
+
+	// the succeeded probe closes it
+	*now = now.Add(time.Minute)
+	backend.down = false
+	if err := b.Do(backend.call); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	assertState(t, b, Closed)
+	if backend.calls != 5 {
+		t.Fatalf("calls %d, expected 5", backend.calls)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+	backend := &flaky{}
+
+	for i := 0; i < 5; i++ {
+		backend.down = i%2 == 0
+		_ = b.Do(backend.call)
+		assertState(t, b, Closed)
+	}
+}
+
+func TestBreakerOneProbe(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	_ = b.Do(func() error { return errBackend })
+	*now = now.Add(time.Minute)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = b.Do(func() error {
+			close(probing)
+			<-release
+			return nil
+		})
+	}()
+
+	// the other calls fail fast while the probe is in flight
+	<-probing
+	if err := b.Do(func() error { return nil }); err != ErrOpen {
+		t.Fatalf("call while probing: %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	assertState(t, b, Closed)
+}
+
+func TestBreakerPanicCounted(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	func() {
+		defer func() { _ = recover() }()
+		_ = b.Do(func() error { panic("backend panic") })
+	}()
+
+	assertState(t, b, Open)
+}
+
+func TestBreakerDefaults(t *testing.T) {
+	opts := OptionsFromConfig(nil)
+	if opts.FailureThreshold != 5 || opts.OpenDuration != 30*time.Second {
+		t.Fatalf("options %+v", opts)
+	}
+
+	b := New(Options{})
+	if b.opts.FailureThreshold != 1 || b.opts.OpenDuration != 30*time.Second {
+		t.Fatalf("options %+v", b.opts)
+	}
+}
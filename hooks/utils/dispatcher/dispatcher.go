@@ -0,0 +1,309 @@
+package dispatcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/gogap/logrus_mate/hooks/utils/breaker"
+)
+
+const (
+	OverflowBlock      = "block"
+	OverflowDropOldest = "drop_oldest"
+	OverflowDropNew    = "drop_new"
+)
+
+var ErrClosed = errors.New("dispatcher: closed")
+
+// Handler sends a batch of items to the backend, the slice is reused
+// after it returns, the handler must not keep it
+type Handler func(items []interface{}) error
+
+type Options struct {
+	BufferSize    int
+	BatchSize     int
+	Overflow      string
+	FlushInterval time.Duration
+
+	// Stop sending the batches for a while after the consecutive failures, nil never stops
+	Breaker *breaker.Options
+}
+
+// OptionsFromConfig reads the uniform buffering config of hooks:
+// buffer-size, batch-size, overflow (block, drop_oldest or drop_new), flush-interval
+// and breaker { failure-threshold, open-duration }
+func OptionsFromConfig(conf config.Configuration) (opts Options) {
+	opts = Options{
+		BufferSize:    1000,
+		BatchSize:     100,
+		Overflow:      OverflowDropNew,
+		FlushInterval: time.Second,
+	}
+
+	if conf != nil {
+		opts.BufferSize = int(conf.GetInt32("buffer-size", int32(opts.BufferSize)))
+		opts.BatchSize = int(conf.GetInt32("batch-size", int32(opts.BatchSize)))
+		opts.Overflow = conf.GetString("overflow", opts.Overflow)
+		opts.FlushInterval = conf.GetTimeDuration("flush-interval", opts.FlushInterval)
+
+		if conf.HasPath("breaker") {
+			breakerOpts := breaker.OptionsFromConfig(conf.GetConfig("breaker"))
+			opts.Breaker = &breakerOpts
+		}
+	}
+
+	return
+}
+
+// Dispatcher buffers the items in a bounded queue, and sends them in batches
+// by the handler in background, when the batch is full, every flush interval,
+// or Flush/Close is called.
+type Dispatcher struct {
+	opts    Options
+	handler Handler
+	breaker *breaker.Breaker
+
+	locker sync.Mutex
+	space  *sync.Cond
+	queue  []interface{}
+	closed bool
+
+	// the drained queue, reused as the next queue, only run touches it
+	spare []interface{}
+
+	dropped    uint64
+	depth      int64
+	flushStats FlushStats
+
+	notify   chan struct{}
+	flushReq chan chan error
+	closing  chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func New(opts Options, handler Handler) (d *Dispatcher, err error) {
+	if handler == nil {
+		err = errors.New("dispatcher: handler is nil")
+		return
+	}
+
+	if opts.BufferSize <= 0 {
+		err = fmt.Errorf("dispatcher: buffer-size should be positive: %d", opts.BufferSize)
+		return
+	}
+
+	switch opts.Overflow {
+	case OverflowBlock, OverflowDropOldest, OverflowDropNew:
+	default:
+		err = fmt.Errorf("dispatcher: overflow should be block, drop_oldest or drop_new: %s", opts.Overflow)
+		return
+	}
+
+	if opts.BatchSize <= 0 || opts.BatchSize > opts.BufferSize {
+		opts.BatchSize = opts.BufferSize
+	}
+
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	d = &Dispatcher{
+		opts:     opts,
+		handler:  handler,
+		queue:    make([]interface{}, 0, opts.BufferSize),
+		spare:    make([]interface{}, 0, opts.BufferSize),
+		notify:   make(chan struct{}, 1),
+		flushReq: make(chan chan error),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	d.space = sync.NewCond(&d.locker)
+
+	if opts.Breaker != nil {
+		d.breaker = breaker.New(*opts.Breaker)
+	}
+
+	go d.run()
+
+	return
+}
+
+// Dispatch queues the item, when the queue is full it blocks, drops the oldest
+// or drops the item by the overflow policy
+func (p *Dispatcher) Dispatch(item interface{}) error {
+	p.locker.Lock()
+
+	for !p.closed && len(p.queue) >= p.opts.BufferSize {
+		switch p.opts.Overflow {
+		case OverflowBlock:
+			p.wakeup()
+			p.space.Wait()
+			continue
+		case OverflowDropOldest:
+			copy(p.queue, p.queue[1:])
+			p.queue = p.queue[:len(p.queue)-1]
+		default:
+			p.locker.Unlock()
+			atomic.AddUint64(&p.dropped, 1)
+			return nil
+		}
+		atomic.AddUint64(&p.dropped, 1)
+	}
+
+	if p.closed {
+		p.locker.Unlock()
+		return ErrClosed
+	}
+
+	p.queue = append(p.queue, item)
+	atomic.StoreInt64(&p.depth, int64(len(p.queue)))
+	full := len(p.queue) >= p.opts.BatchSize
+	p.locker.Unlock()
+
+	if full {
+		p.wakeup()
+	}
+
+	return nil
+}
+
+// Dropped returns the count of items dropped by overflow, or by the breaker while open
+func (p *Dispatcher) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Stats returns the queue depth, drops and the latency of sending batches
+func (p *Dispatcher) Stats() (stats Stats) {
+	stats = Stats{
+		QueueDepth: int(atomic.LoadInt64(&p.depth)),
+		Capacity:   p.opts.BufferSize,
+		Dropped:    p.Dropped(),
+	}
+	p.flushStats.Fill(&stats)
+	return
+}
+
+// Flush sends all the queued items and returns the last handler error
+func (p *Dispatcher) Flush() error {
+	req := make(chan error, 1)
+	select {
+	case p.flushReq <- req:
+		return <-req
+	case <-p.done:
+		return ErrClosed
+	}
+}
+
+// Close stops accepting items, sends the queued ones and stops the background goroutine
+func (p *Dispatcher) Close() error {
+	p.once.Do(func() {
+		p.locker.Lock()
+		p.closed = true
+		p.space.Broadcast()
+		p.locker.Unlock()
+
+		close(p.closing)
+	})
+
+	<-p.done
+
+	return nil
+}
+
+func (p *Dispatcher) wakeup() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Dispatcher) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.notify:
+			p.drain()
+		case <-ticker.C:
+			p.drain()
+		case req := <-p.flushReq:
+			req <- p.drain()
+		case <-p.closing:
+			p.drain()
+			return
+		}
+	}
+}
+
+// send calls the handler through the breaker if any
+func (p *Dispatcher) send(items []interface{}) error {
+	handle := func() error {
+		start := time.Now()
+		defer func() { p.flushStats.Observe(time.Since(start)) }()
+
+		return p.handler(items)
+	}
+
+	if p.breaker == nil {
+		return handle()
+	}
+
+	return p.breaker.Do(handle)
+}
+
+// BreakerState returns the state of the breaker, Closed without breaker
+func (p *Dispatcher) BreakerState() breaker.State {
+	if p.breaker == nil {
+		return breaker.Closed
+	}
+	return p.breaker.State()
+}
+
+func (p *Dispatcher) drain() (err error) {
+	p.locker.Lock()
+	drained := p.queue
+	p.queue = p.spare
+	atomic.StoreInt64(&p.depth, 0)
+	p.space.Broadcast()
+	p.locker.Unlock()
+
+	defer func() {
+		// release the items and keep the slice for the next drain
+		for i := range drained {
+			drained[i] = nil
+		}
+		p.spare = drained[:0]
+	}()
+
+	items := drained
+	for len(items) > 0 {
+		n := p.opts.BatchSize
+		if n > len(items) {
+			n = len(items)
+		}
+
+		e := p.send(items[:n])
+
+		if e == breaker.ErrOpen {
+			// the backend is failing, the batch is dropped without trying it
+			err = e
+			atomic.AddUint64(&p.dropped, uint64(n))
+		} else if e != nil {
+			err = e
+			_, _ = fmt.Fprintf(os.Stderr, "%v dispatcher: handler failed, %d items lost: %s\n", time.Now(), n, e)
+		}
+
+		items = items[n:]
+	}
+
+	return
+}
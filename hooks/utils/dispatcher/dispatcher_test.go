@@ -0,0 +1,191 @@
+package dispatcher
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingHandler records the batches, the first batch blocks until release
+type blockingHandler struct {
+	locker   sync.Mutex
+	batches  [][]interface{}
+	handling chan struct{}
+	release  chan struct{}
+	once     sync.Once
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{handling: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (p *blockingHandler) handle(items []interface{}) error {
+	p.locker.Lock()
+	p.batches = append(p.batches, append([]interface{}(nil), items...))
+	p.locker.Unlock()
+
+	p.once.Do(func() {
+		close(p.handling)
+		<-p.release
+	})
+	return nil
+}
+
+func (p *blockingHandler) Batches() [][]interface{} {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	return p.batches
+}
+
+// newBlockedDispatcher returns the dispatcher whose background goroutine is
+// blocked in handling the batch 1 2 3, the queue is full of 4 5 6
+func newBlockedDispatcher(t *testing.T, overflow string) (*Dispatcher, *blockingHandler) {
+	t.Helper()
+
+	h := newBlockingHandler()
+	d, err := New(Options{BufferSize: 3, BatchSize: 3, Overflow: overflow, FlushInterval: time.Hour}, h.handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		_ = d.Dispatch(i)
+	}
+	<-h.handling
+
+	for i := 4; i <= 6; i++ {
+		_ = d.Dispatch(i)
+	}
+
+	return d, h
+}
+
+func TestOverflowDropNew(t *testing.T) {
+	d, h := newBlockedDispatcher(t, OverflowDropNew)
+
+	_ = d.Dispatch(7)
+	_ = d.Dispatch(8)
+
+	if dropped := d.Dropped(); dropped != 2 {
+		t.Fatalf("%d dropped", dropped)
+	}
+
+	close(h.release)
+	_ = d.Close()
+
+	expected := [][]interface{}{{1, 2, 3}, {4, 5, 6}}
+	if batches := h.Batches(); !reflect.DeepEqual(batches, expected) {
+		t.Fatalf("batches %v, expected %v", batches, expected)
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	d, h := newBlockedDispatcher(t, OverflowDropOldest)
+
+	_ = d.Dispatch(7)
+	_ = d.Dispatch(8)
+
+	if dropped := d.Dropped(); dropped != 2 {
+		t.Fatalf("%d dropped", dropped)
+	}
+
+	close(h.release)
+	_ = d.Close()
+
+	expected := [][]interface{}{{1, 2, 3}, {6, 7, 8}}
+	if batches := h.Batches(); !reflect.DeepEqual(batches, expected) {
+		t.Fatalf("batches %v, expected %v", batches, expected)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	d, h := newBlockedDispatcher(t, OverflowBlock)
+
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		_ = d.Dispatch(7)
+	}()
+
+	select {
+	case <-dispatched:
+		t.Fatal("dispatch into the full queue is not blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(h.release)
+
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatch is blocked after the queue is drained")
+	}
+
+	_ = d.Close()
+
+	var items []interface{}
+	for _, batch := range h.Batches() {
+		items = append(items, batch...)
+	}
+
+	if expected := []interface{}{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(items, expected) || d.Dropped() != 0 {
+		t.Fatalf("items %v dropped %d", items, d.Dropped())
+	}
+}
+
+func TestDispatchAfterClose(t *testing.T) {
+	d, err := New(Options{BufferSize: 1, Overflow: OverflowDropNew}, func([]interface{}) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = d.Close()
+
+	if err = d.Dispatch(1); err != ErrClosed {
+		t.Fatalf("dispatch after close: %v", err)
+	}
+	if err = d.Flush(); err != ErrClosed {
+		t.Fatalf("flush after close: %v", err)
+	}
+}
+
+func TestFlushReusesBatch(t *testing.T) {
+	var batches [][]interface{}
+	d, err := New(Options{BufferSize: 4, BatchSize: 4, Overflow: OverflowDropNew, FlushInterval: time.Hour}, func(items []interface{}) error {
+		batches = append(batches, append([]interface{}(nil), items...))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	for round := 0; round < 3; round++ {
+		_ = d.Dispatch(round*2 + 1)
+		_ = d.Dispatch(round*2 + 2)
+		if err = d.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := [][]interface{}{{1, 2}, {3, 4}, {5, 6}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Fatalf("batches %v, expected %v", batches, expected)
+	}
+}
+
+func BenchmarkDispatchFlush(b *testing.B) {
+	d, err := New(Options{BufferSize: 1000, BatchSize: 100, Overflow: OverflowBlock, FlushInterval: time.Hour}, func([]interface{}) error { return nil })
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer d.Close()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			_ = d.Dispatch(j)
+		}
+		_ = d.Flush()
+	}
+}
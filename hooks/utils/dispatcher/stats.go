@@ -0,0 +1,43 @@
+package dispatcher
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is the backpressure of an async component, to tune the buffer size
+// and the overflow policy
+type Stats struct {
+	QueueDepth       int
+	Capacity         int
+	Dropped          uint64
+	Flushes          uint64
+	LastFlushLatency time.Duration
+	MaxFlushLatency  time.Duration
+}
+
+// FlushStats records the flush latency, the counters are atomic
+type FlushStats struct {
+	flushes uint64
+	last    int64
+	max     int64
+}
+
+func (p *FlushStats) Observe(latency time.Duration) {
+	atomic.AddUint64(&p.flushes, 1)
+	atomic.StoreInt64(&p.last, int64(latency))
+
+	for {
+		max := atomic.LoadInt64(&p.max)
+		if int64(latency) <= max || atomic.CompareAndSwapInt64(&p.max, max, int64(latency)) {
+			return
+		}
+	}
+}
+
+// Fill sets the flush fields of stats
+func (p *FlushStats) Fill(stats *Stats) {
+	stats.Flushes = atomic.LoadUint64(&p.flushes)
+	stats.LastFlushLatency = time.Duration(atomic.LoadInt64(&p.last))
+	stats.MaxFlushLatency = time.Duration(atomic.LoadInt64(&p.max))
+}
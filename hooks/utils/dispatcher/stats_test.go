@@ -0,0 +1,71 @@
+package dispatcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatsDroppedUnderFullBuffer(t *testing.T) {
+	for _, overflow := range []string{OverflowDropNew, OverflowDropOldest} {
+		d, h := newBlockedDispatcher(t, overflow)
+
+		stats := d.Stats()
+		if stats.QueueDepth != 3 || stats.Capacity != 3 || stats.Dropped != 0 {
+			t.Fatalf("%s: stats of the full queue %+v", overflow, stats)
+		}
+
+		for i := 7; i <= 11; i++ {
+			_ = d.Dispatch(i)
+		}
+
+		if stats = d.Stats(); stats.Dropped != 5 || stats.QueueDepth != 3 {
+			t.Fatalf("%s: stats after 5 overflows %+v", overflow, stats)
+		}
+
+		close(h.release)
+		_ = d.Close()
+
+		if stats = d.Stats(); stats.QueueDepth != 0 || stats.Dropped != 5 || stats.Flushes != 2 {
+			t.Fatalf("%s: stats after close %+v", overflow, stats)
+		}
+	}
+}
+
+func TestStatsDroppedConcurrent(t *testing.T) {
+	d, h := newBlockedDispatcher(t, OverflowDropNew)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = d.Dispatch(i)
+				_ = d.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if dropped := d.Stats().Dropped; dropped != 800 {
+		t.Fatalf("%d dropped, expected 800", dropped)
+	}
+
+	close(h.release)
+	_ = d.Close()
+}
+
+func TestFlushStatsLatency(t *testing.T) {
+	var fs FlushStats
+	fs.Observe(3 * time.Millisecond)
+	fs.Observe(7 * time.Millisecond)
+	fs.Observe(2 * time.Millisecond)
+
+	var stats Stats
+	fs.Fill(&stats)
+
+	if stats.Flushes != 3 || stats.LastFlushLatency != 2*time.Millisecond || stats.MaxFlushLatency != 7*time.Millisecond {
+		t.Fatalf("flush stats %+v", stats)
+	}
+}
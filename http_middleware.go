@@ -0,0 +1,99 @@
+package logrus_mate
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader is read for the request id, or set by HTTPMiddleware when absent
+const RequestIDHeader = "X-Request-Id"
+
+// HTTPMiddleware logs each request through the named logger when it is done, with
+// the fields method, path, status, latency_ms, bytes and request_id. The request id
+// is taken from X-Request-Id or generated, it is echoed in the response header and
+// attached by ContextWithFields to the request context, so the entries logged by
+// the handler with WithContext(r.Context()) carry it. The status 5xx is logged as
+// error, 4xx as warning and others as info.
+func (p *LogrusMate) HTTPMiddleware(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if len(requestID) == 0 {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := ContextWithFields(r.Context(), logrus.Fields{"request_id": requestID})
+			rw := &responseRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			logger := p.Logger(name)
+			if logger == nil {
+				return
+			}
+
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := logrus.InfoLevel
+			if status >= 500 {
+				level = logrus.ErrorLevel
+			} else if status >= 400 {
+				level = logrus.WarnLevel
+			}
+
+			logger.WithContext(ctx).WithFields(logrus.Fields{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     status,
+				"latency_ms": float64(time.Since(start)) / float64(time.Millisecond),
+				"bytes":      rw.bytes,
+			}).Log(level, "http request")
+		})
+	}
+}
+
+// responseRecorder records the status and the bytes written
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (p *responseRecorder) WriteHeader(status int) {
+	if p.status == 0 {
+		p.status = status
+	}
+	p.ResponseWriter.WriteHeader(status)
+}
+
+func (p *responseRecorder) Write(b []byte) (int, error) {
+	if p.status == 0 {
+		p.status = http.StatusOK
+	}
+	n, err := p.ResponseWriter.Write(b)
+	p.bytes += n
+	return n, err
+}
+
+// Flush keeps the streaming of the wrapped writer
+func (p *responseRecorder) Flush() {
+	if flusher, ok := p.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
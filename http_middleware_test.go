@@ -0,0 +1,144 @@
+package logrus_mate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestHTTPMiddlewareFields(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { level = "info", out.name = "nil", hooks.test-record.id = "http" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := mate.HTTPMiddleware("mike")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mate.Logger("mike").WithContext(r.Context()).Info("loading user")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+		_, _ = w.Write([]byte(" world"))
+	}))
+
+	req := httptest.NewRequest("POST", "/users?name=bob", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || rec.Header().Get(RequestIDHeader) != "req-1" {
+		t.Fatalf("response %d %v", rec.Code, rec.Header())
+	}
+
+	entries := recordedBy(t, "http").Entries()
+	if len(entries) != 2 {
+		t.Fatalf("entries %v", entries)
+	}
+
+	// the entry of handler carries the request id by the context
+	if entries[0].Message != "loading user" || entries[0].Data["request_id"] != "req-1" {
+		t.Fatalf("handler entry %v", entries[0])
+	}
+
+	e := entries[1]
+	if e.Message != "http request" || e.Level != logrus.InfoLevel {
+		t.Fatalf("request entry %v", e)
+	}
+	for key, expected := range map[string]interface{}{
+		"method":     "POST",
+		"path":       "/users",
+		"status":     http.StatusCreated,
+		"bytes":      11,
+		"request_id": "req-1",
+	} {
+		if e.Data[key] != expected {
+			t.Fatalf("field %s is %v, expected %v", key, e.Data[key], expected)
+		}
+	}
+	if latency, ok := e.Data["latency_ms"].(float64); !ok || latency < 0 {
+		t.Fatalf("latency_ms %v", e.Data["latency_ms"])
+	}
+}
+
+func TestHTTPMiddlewareStatusLevels(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { level = "info", out.name = "nil", hooks.test-record.id = "http-status" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		status int
+		level  logrus.Level
+	}{
+		{0, logrus.InfoLevel},
+		{http.StatusNotFound, logrus.WarnLevel},
+		{http.StatusBadGateway, logrus.ErrorLevel},
+	} {
+		handler := mate.HTTPMiddleware("mike")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.status > 0 {
+				w.WriteHeader(c.status)
+			}
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		entries := recordedBy(t, "http-status").Entries()
+		e := entries[len(entries)-1]
+
+		expectedStatus := c.status
+		if expectedStatus == 0 {
+			expectedStatus = http.StatusOK
+		}
+		if e.Level != c.level || e.Data["status"] != expectedStatus || e.Data["bytes"] != 0 {
+			t.Fatalf("status %d logged as %v", c.status, e)
+		}
+
+		// the generated request id is echoed
+		if id, _ := e.Data["request_id"].(string); len(id) != 16 || rec.Header().Get(RequestIDHeader) != id {
+			t.Fatalf("request id %q, header %q", id, rec.Header().Get(RequestIDHeader))
+		}
+	}
+}
+
+func TestHTTPMiddlewareFlush(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := mate.HTTPMiddleware("mike")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("the wrapped writer is not a flusher")
+		}
+		_, _ = w.Write([]byte("chunk"))
+		flusher.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+
+	if !rec.Flushed || !strings.Contains(rec.Body.String(), "chunk") {
+		t.Fatalf("flushed %v body %q", rec.Flushed, rec.Body.String())
+	}
+}
+
+func TestHTTPMiddlewareUnknownLogger(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := mate.HTTPMiddleware("missing")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Fatalf("response %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,144 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	levelAliasesLocker = sync.RWMutex{}
+	levelAliases       = make(map[string]logrus.Level)
+
+	// the aliases of the config being prepared, accepted by ParseLevel until
+	// they are registered by the commit or dropped
+	pendingAliasesLocker = sync.Mutex{}
+	pendingAliases       map[string]logrus.Level
+)
+
+// RegisterLevelAlias maps the domain severity name like NOTICE or CRITICAL to the
+// nearest logrus level, the alias is case insensitive. The alias could not shadow
+// a logrus level name, and the same alias could not map to another level.
+func RegisterLevelAlias(alias string, level logrus.Level) error {
+	levelAliasesLocker.Lock()
+	defer levelAliasesLocker.Unlock()
+
+	name, err := checkLevelAlias(alias, level)
+	if err != nil {
+		return err
+	}
+
+	levelAliases[name] = level
+
+	return nil
+}
+
+// checkLevelAlias returns the name of alias, it must be called with levelAliasesLocker locked
+func checkLevelAlias(alias string, level logrus.Level) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(alias))
+	if len(name) == 0 {
+		return "", fmt.Errorf("logurs mate: level alias is empty")
+	}
+
+	if _, err := logrus.ParseLevel(name); err == nil {
+		return "", fmt.Errorf("logurs mate: level alias %s is a logrus level", alias)
+	}
+
+	if exist, ok := levelAliases[name]; ok && exist != level {
+		return "", fmt.Errorf("logurs mate: level alias %s is mapped to %s already", alias, exist)
+	}
+
+	return name, nil
+}
+
+// LevelAliases returns the registered aliases, e.g. notice=info
+func LevelAliases() map[string]logrus.Level {
+	levelAliasesLocker.RLock()
+	defer levelAliasesLocker.RUnlock()
+
+	aliases := make(map[string]logrus.Level, len(levelAliases))
+	for name, level := range levelAliases {
+		aliases[name] = level
+	}
+	return aliases
+}
+
+// ParseLevel is logrus.ParseLevel accepting the registered aliases, the configs
+// of mate and the hooks parse the levels by it
+func ParseLevel(name string) (logrus.Level, error) {
+	alias := strings.ToLower(strings.TrimSpace(name))
+
+	levelAliasesLocker.RLock()
+	level, exist := levelAliases[alias]
+	if !exist {
+		level, exist = pendingAliases[alias]
+	}
+	levelAliasesLocker.RUnlock()
+
+	if exist {
+		return level, nil
+	}
+
+	return logrus.ParseLevel(name)
+}
+
+// levelAliasesOf checks the aliases of logger config like:
+// level-aliases { notice = "info", critical = "error" }
+// they are registered by registerLevelAliases when the config is committed
+func levelAliasesOf(conf config.Configuration) (aliases map[string]logrus.Level, err error) {
+	names := conf.Keys()
+	sort.Strings(names)
+
+	levelAliasesLocker.RLock()
+	defer levelAliasesLocker.RUnlock()
+
+	aliases = make(map[string]logrus.Level, len(names))
+	for _, name := range names {
+		var level logrus.Level
+		if level, err = logrus.ParseLevel(conf.GetString(name)); err != nil {
+			return nil, fmt.Errorf("logurs mate: level alias %s: %s", name, err)
+		}
+
+		var alias string
+		if alias, err = checkLevelAlias(name, level); err != nil {
+			return nil, err
+		}
+		aliases[alias] = level
+	}
+
+	return
+}
+
+// pendLevelAliases makes ParseLevel accept the aliases until released, so the
+// config defining them is prepared by them before it is committed. The prepares
+// pending the aliases are serialized.
+func pendLevelAliases(aliases map[string]logrus.Level) (release func()) {
+	pendingAliasesLocker.Lock()
+
+	levelAliasesLocker.Lock()
+	pendingAliases = aliases
+	levelAliasesLocker.Unlock()
+
+	return func() {
+		levelAliasesLocker.Lock()
+		pendingAliases = nil
+		levelAliasesLocker.Unlock()
+
+		pendingAliasesLocker.Unlock()
+	}
+}
+
+// registerLevelAliases registers the aliases checked by levelAliasesOf, the alias
+// mapped to another level in between is reported to stderr
+func registerLevelAliases(aliases map[string]logrus.Level) {
+	for alias, level := range aliases {
+		if err := RegisterLevelAlias(alias, level); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelAliasesByConfig(t *testing.T) {
+	logger, buf := hijackString(t, `
+level-aliases { notice = "info", critical = "error" }
+level = "NOTICE"
+relevel.rules.disk { match = "disk full", level = "Critical" }`)
+
+	if logger.Level != logrus.InfoLevel {
+		t.Fatalf("level %s, expected info", logger.Level)
+	}
+
+	logger.Debug("dropped")
+	logger.Info("started")
+	logger.Warn("disk full")
+
+	// the formatter writes the resolved logrus levels
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "level=info") || !strings.Contains(lines[1], "level=error") {
+		t.Fatalf("lines %q", lines)
+	}
+
+	for alias, expected := range map[string]logrus.Level{"notice": logrus.InfoLevel, "CRITICAL": logrus.ErrorLevel, "warn": logrus.WarnLevel} {
+		if level, err := ParseLevel(alias); err != nil || level != expected {
+			t.Fatalf("%s is parsed as %s, %v", alias, level, err)
+		}
+	}
+}
+
+func TestRegisterLevelAlias(t *testing.T) {
+	if err := RegisterLevelAlias("Audit", logrus.WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+	// registering the same mapping again is fine
+	if err := RegisterLevelAlias("audit", logrus.WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	if level := LevelAliases()["audit"]; level != logrus.WarnLevel {
+		t.Fatalf("audit is %s", level)
+	}
+
+	for _, c := range []struct {
+		alias string
+		level logrus.Level
+	}{
+		{"audit", logrus.ErrorLevel},
+		{"error", logrus.InfoLevel},
+		{"  ", logrus.InfoLevel},
+	} {
+		if err := RegisterLevelAlias(c.alias, c.level); err == nil {
+			t.Fatalf("alias %q to %s is accepted", c.alias, c.level)
+		}
+	}
+}
+
+func TestLevelAliasesInvalidConfig(t *testing.T) {
+	for _, conf := range []string{
+		`level-aliases { severe = "nope" }`,
+		`level-aliases { info = "debug" }`,
+		`level = "unknown-alias"`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
+
+func TestLevelAliasesRegisteredByCommit(t *testing.T) {
+	// the levels of the config use the alias, then the config fails
+	err := Hijack(logrus.New(), ConfigString(`
+level-aliases { uncommitted = "warn" }
+level = "uncommitted"
+hooks.no-such-hook {}`))
+	if err == nil {
+		t.Fatal("the unknown hook is accepted")
+	}
+
+	if _, err = ParseLevel("uncommitted"); err == nil {
+		t.Fatal("the alias of the failed hijack is registered")
+	}
+
+	// the snapshot apply rolled back registers nothing either
+	mate, err := NewLogrusMate(ConfigString(`mike.level = "info"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("mike")
+
+	s := mate.Snapshot().WithConfig("mike", configOf(`level-aliases { rolledback = "warn" }, level = "rolledback", hooks.no-such-hook {}`))
+	if err = mate.Apply(s); err == nil {
+		t.Fatal("the unknown hook is applied")
+	}
+	if _, err = ParseLevel("rolledback"); err == nil {
+		t.Fatal("the alias of the apply rolled back is registered")
+	}
+
+	if err = mate.Apply(mate.Snapshot().WithConfig("mike", configOf(`level-aliases { applied = "warn" }, level = "applied"`))); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := ParseLevel("applied"); err != nil || level != logrus.WarnLevel {
+		t.Fatalf("applied is parsed as %s, %v", level, err)
+	}
+}
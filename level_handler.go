@@ -0,0 +1,68 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type levelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+}
+
+// LevelHandler returns a handler to change the level of loggers at runtime,
+// GET responses the levels of the created loggers, e.g. {"mike":"info"},
+// PUT sets the level of a logger by ?logger=mike&level=debug or the body {"logger":"mike","level":"debug"}
+func (p *LogrusMate) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			p.writeLevels(w)
+		case http.MethodPut, http.MethodPost:
+			req := levelRequest{
+				Logger: r.URL.Query().Get("logger"),
+				Level:  r.URL.Query().Get("level"),
+			}
+
+			if len(req.Level) == 0 && r.Body != nil {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, "bad request body: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+
+			lvl, err := ParseLevel(req.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			logger := p.Logger(req.Logger)
+			if logger == nil {
+				http.Error(w, ErrLoggerNotExist.Error()+": "+req.Logger, http.StatusNotFound)
+				return
+			}
+
+			logger.SetLevel(lvl)
+
+			p.writeLevels(w)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (p *LogrusMate) writeLevels(w http.ResponseWriter) {
+	levels := map[string]string{}
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		levels[k.(string)] = v.(*logrus.Logger).GetLevel().String()
+		return true
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levels)
+}
@@ -0,0 +1,64 @@
+package logrus_mate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelHandler(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+mike { level = "info" }
+jack { level = "error" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mate.Logger("jack")
+
+	handler := mate.LevelHandler()
+	serve := func(method, target, body string) (int, map[string]string) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+		levels := map[string]string{}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &levels); err != nil {
+				t.Fatalf("%s %s: %s", method, target, err)
+			}
+		}
+		return rec.Code, levels
+	}
+
+	if code, levels := serve(http.MethodGet, "/", ""); code != http.StatusOK || len(levels) != 1 || levels["jack"] != "error" {
+		t.Fatalf("GET %d %v", code, levels)
+	}
+
+	if code, levels := serve(http.MethodPut, "/?logger=mike&level=debug", ""); code != http.StatusOK || levels["mike"] != "debug" {
+		t.Fatalf("PUT by query %d %v", code, levels)
+	}
+	if lvl := mate.Logger("mike").GetLevel(); lvl != logrus.DebugLevel {
+		t.Fatalf("the live level %s", lvl)
+	}
+
+	if code, levels := serve(http.MethodPost, "/", `{"logger":"jack","level":"warn"}`); code != http.StatusOK || levels["jack"] != "warning" {
+		t.Fatalf("POST by body %d %v", code, levels)
+	}
+
+	for _, c := range []struct {
+		method, target, body string
+		code                 int
+	}{
+		{http.MethodPut, "/?logger=mike&level=loud", "", http.StatusBadRequest},
+		{http.MethodPut, "/", "{", http.StatusBadRequest},
+		{http.MethodPut, "/?logger=missing&level=info", "", http.StatusNotFound},
+		{http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+	} {
+		if code, _ := serve(c.method, c.target, c.body); code != c.code {
+			t.Fatalf("%s %s %q: %d, expected %d", c.method, c.target, c.body, code, c.code)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// levelOverrideHook lets the entries carrying the override field through above
+// the configured level, e.g. the debug lines of one request. The logger is lowered
+// to the override level so logrus passes them to the hooks, the hook drops the
+// entries above the configured level without the override.
+type levelOverrideHook struct {
+	field    string
+	level    logrus.Level
+	override logrus.Level
+}
+
+// newLevelOverrideHook parses level-override { field = "debug_override", level = "debug" },
+// level is the config level of logger
+func newLevelOverrideHook(level logrus.Level, conf config.Configuration) (hook *levelOverrideHook, err error) {
+	hook = &levelOverrideHook{
+		field: conf.GetString("field", "debug_override"),
+		level: level,
+	}
+
+	if len(hook.field) == 0 {
+		err = fmt.Errorf("logurs mate: level-override field is empty")
+		return
+	}
+
+	if hook.override, err = ParseLevel(conf.GetString("level", "debug")); err != nil {
+		err = fmt.Errorf("logurs mate: level-override: %s", err)
+		return
+	}
+
+	return
+}
+
+// loggerLevel is the level of logger letting both the configured and the override entries through
+func (p *levelOverrideHook) loggerLevel() logrus.Level {
+	if p.override > p.level {
+		return p.override
+	}
+	return p.level
+}
+
+func (p *levelOverrideHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *levelOverrideHook) Fire(entry *logrus.Entry) error {
+	if entry.Level <= p.level {
+		return nil
+	}
+
+	if entry.Level > p.override || !overridden(entry.Data[p.field]) {
+		markDropped(entry)
+	}
+
+	return nil
+}
+
+// overridden reports whether the value of override field is set, e.g. true, "1" or "yes"
+func overridden(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	case string:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		return value == "yes" || value == "on"
+	}
+	return true
+}
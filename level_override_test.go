@@ -0,0 +1,63 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelOverride(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+level-override { field = "debug_override", level = "debug" }
+formatter.name = "text"
+formatter.options.disable-timestamp = true`)
+
+	logger.Debug("debug without override")
+	logger.WithField("debug_override", true).Debug("debug with override")
+	logger.WithField("debug_override", false).Debug("debug override false")
+	logger.WithField("debug_override", true).Trace("trace above the override")
+	logger.Info("info")
+
+	s := buf.String()
+	for _, written := range []string{"debug with override", "msg=info"} {
+		if !strings.Contains(s, written) {
+			t.Fatalf("%q not written: %q", written, s)
+		}
+	}
+	for _, dropped := range []string{"debug without override", "debug override false", "trace above the override"} {
+		if strings.Contains(s, dropped) {
+			t.Fatalf("%q written: %q", dropped, s)
+		}
+	}
+}
+
+func TestLevelOverrideValues(t *testing.T) {
+	for v, expected := range map[interface{}]bool{
+		nil:     false,
+		true:    true,
+		false:   false,
+		"1":     true,
+		"false": false,
+		"yes":   true,
+		"on":    true,
+		"no":    false,
+		1:       true,
+	} {
+		if overridden(v) != expected {
+			t.Fatalf("overridden(%#v) is %v", v, !expected)
+		}
+	}
+}
+
+func TestLevelOverrideInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`level-override { field = "", level = "debug" }`,
+		`level-override { level = "verbose" }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
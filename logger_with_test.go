@@ -0,0 +1,56 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoggerWith(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+mike {
+    level = "warn"
+    out.name = "buffer"
+    hooks.test-record.id = "logger-with"
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := mate.LoggerWith("mike", logrus.Fields{"subsystem": "billing"})
+	if child == nil {
+		t.Fatal("no child of mike")
+	}
+
+	child.Info("below level")
+	child.Warn("charged")
+	child.WithField("id", 7).Error("refund failed")
+
+	entries := recordedBy(t, "logger-with").Entries()
+	if len(entries) != 2 {
+		t.Fatalf("the child entries recorded %v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Data["subsystem"] != "billing" {
+			t.Fatalf("the entry misses the static field: %v", entry)
+		}
+	}
+	if entries[1].Data["id"] != 7 {
+		t.Fatalf("the per call field is lost: %v", entries[1])
+	}
+
+	// the later level change of the logger applies to the child
+	mate.Logger("mike").SetLevel(logrus.InfoLevel)
+	child.Info("now visible")
+
+	buf, _ := mate.Buffer("mike")
+	if out := buf.String(); strings.Contains(out, "below level") || !strings.Contains(out, "now visible") ||
+		strings.Count(out, "subsystem=billing") != 3 {
+		t.Fatalf("output %q", out)
+	}
+
+	if mate.LoggerWith("missing", nil) != nil {
+		t.Fatal("the child of missing logger")
+	}
+}
@@ -1,12 +1,16 @@
 package logrus_mate
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"sync"
 
 	"github.com/gogap/config"
+	"github.com/gogap/logrus_mate/hooks/utils/breaker"
 
 	"github.com/sirupsen/logrus"
 )
@@ -18,6 +22,11 @@ var (
 type LogrusMate struct {
 	loggersConf sync.Map //map[string]*Config
 	loggers     sync.Map //map[string]*logrus.Logger
+
+	mergeLocker sync.Mutex
+
+	remoteStop     chan struct{}
+	remoteStopOnce sync.Once
 }
 
 func NewLogger(opts ...Option) (logger *logrus.Logger, err error) {
@@ -36,16 +45,60 @@ func Hijack(logger *logrus.Logger, opts ...Option) (err error) {
 		o(&logrusMateConf)
 	}
 
+	if logrusMateConf.err != nil {
+		err = logrusMateConf.err
+		return
+	}
+
 	hijackConf := config.NewConfig(logrusMateConf.configOpts...)
 
 	return hijackByConfig(logger, hijackConf)
 }
 
 func hijackByConfig(logger *logrus.Logger, conf config.Configuration) (err error) {
+	var commit func()
+	if commit, err = prepareHijack(logger, conf); err != nil {
+		return
+	}
+
+	commit()
+
+	return
+}
+
+// prepareHijack builds the logger of conf, logger is unchanged until commit,
+// so several loggers could be hijacked all or nothing
+func prepareHijack(logger *logrus.Logger, conf config.Configuration) (commit func(), err error) {
 	if conf == nil {
+		commit = func() {}
 		return
 	}
 
+	// logrus could not turn the lock on again, the logger hijacked with nolock
+	// stays without lock, so it is hijacked only with nolock again
+	if _, noLock := noLockLoggers.Load(logger); noLock && !conf.GetBoolean("nolock", false) {
+		err = fmt.Errorf("logurs mate: the logger hijacked with nolock could not be hijacked with the lock")
+		return
+	}
+
+	// disabled logger discards the output and fires no hooks
+	if !conf.GetBoolean("enabled", true) {
+		l := logrus.New()
+		l.Out = new(NullWriter)
+		l.Formatter = new(NullFormatter)
+		commit = func() { closeHeartbeats(replaceLogger(logger, l, false), l.Hooks) }
+		return
+	}
+
+	// the levels of this config could use its aliases, they are registered by commit
+	var aliases map[string]logrus.Level
+	if aliasesConf := conf.GetConfig("level-aliases"); aliasesConf != nil {
+		if aliases, err = levelAliasesOf(aliasesConf); err != nil {
+			return
+		}
+		defer pendLevelAliases(aliases)()
+	}
+
 	outConf := conf.GetConfig("out")
 	formatterConf := conf.GetConfig("formatter")
 
@@ -74,47 +127,311 @@ func hijackByConfig(logger *logrus.Logger, conf config.Configuration) (err error
 		return
 	}
 
-	var hooks []logrus.Hook
+	level := conf.GetString("level")
+
+	if len(level) == 0 {
+		level = "info"
+	}
+
+	var lvl = logrus.DebugLevel
+	if lvl, err = ParseLevel(level); err != nil {
+		return
+	}
+
+	// the context fields are merged first, so the other hooks could see them
+	hooks := []logrus.Hook{&contextFieldsHook{}}
+
+	// the tags are attached as fields, so the other hooks could see them too
+	if len(conf.GetStringList("tags")) > 0 {
+		var t *tagsHook
+		if t, err = newTagsHook(conf); err != nil {
+			return
+		}
+		hooks = append(hooks, t)
+	}
+
+	if transformNames := conf.GetStringList("transforms"); len(transformNames) > 0 {
+		var t *transformHook
+		if t, err = newTransformHook(transformNames); err != nil {
+			return
+		}
+		hooks = append(hooks, t)
+	}
+
+	// the configured pre hooks, e.g. mask, are inserted here, after the tags and
+	// transforms, so the template, route and every other hook see the entry changed
+	preIndex := len(hooks)
+	var preHooks []logrus.Hook
+
+	// the message template is rendered after the transforms added their fields
+	if templateConf := conf.GetConfig("template"); templateConf != nil {
+		var t *templateHook
+		if t, err = newTemplateHook(templateConf); err != nil {
+			return
+		}
+		hooks = append(hooks, t)
+	}
+
+	// the keys are normalized after the template rendered the message by the original keys
+	if conf.HasPath("field-case") {
+		var c *fieldCaseHook
+		if c, err = newFieldCaseHook(conf); err != nil {
+			return
+		}
+		hooks = append(hooks, c)
+	}
+
+	// the maps are flattened after the keys normalized, so the separator is kept
+	if conf.HasPath("flatten") {
+		var f *flattenHook
+		if f, err = newFlattenHook(conf); err != nil {
+			return
+		}
+		if f != nil {
+			hooks = append(hooks, f)
+		}
+	}
+
+	// the long values are truncated after the template rendered them into message
+	if conf.HasPath("max-field-len") {
+		var l *fieldLimitHook
+		if l, err = newFieldLimitHook(conf); err != nil {
+			return
+		}
+		hooks = append(hooks, l)
+	}
+
+	// relevel, quiet hours, sample and route decide on the entry enriched, before
+	// the configured hooks fire
+	if relevelConf := conf.GetConfig("relevel"); relevelConf != nil {
+		var r *relevelHook
+		if r, err = newRelevelHook(logger, relevelConf); err != nil {
+			return
+		}
+		hooks = append(hooks, r)
+	}
+
+	// the override follows relevel, so it filters by the level rewritten
+	if overrideConf := conf.GetConfig("level-override"); overrideConf != nil {
+		var o *levelOverrideHook
+		if o, err = newLevelOverrideHook(lvl, overrideConf); err != nil {
+			return
+		}
+		hooks = append(hooks, o)
+		lvl = o.loggerLevel()
+	}
+
+	if quietConf := conf.GetConfig("quiet-hours"); quietConf != nil {
+		var q *quietHoursHook
+		if q, err = newQuietHoursHook(quietConf); err != nil {
+			return
+		}
+		hooks = append(hooks, q)
+	}
+
+	if sampleConf := conf.GetConfig("sample"); sampleConf != nil {
+		var s *sampler
+		if s, err = newSampler(sampleConf); err != nil {
+			return
+		}
+		hooks = append(hooks, &sampleHook{sampler: s})
+	}
+
+	if routeConf := conf.GetConfig("route"); routeConf != nil {
+		var r *routeHook
+		if r, err = newRouteHook(routeConf); err != nil {
+			return
+		}
+		hooks = append(hooks, r)
+	}
+
+	if batchConf := conf.GetConfig("batch"); batchConf != nil {
+		var b *batchHook
+		if b, err = newBatchHook(logger, batchConf); err != nil {
+			return
+		}
+		hooks = append(hooks, b)
+	}
+
+	if err = checkEncoding(conf.GetString("encoding")); err != nil {
+		return
+	}
+
+	if _, _, err = parseEmptyMessage(conf); err != nil {
+		return
+	}
+
+	// with fanout the outputs have their own formatters and are written by the
+	// last hook, the out of logger writes nothing
+	var fanout *fanoutHook
+	if fanoutConf := conf.GetConfig("fanout"); fanoutConf != nil {
+		if fanout, err = newFanoutHook(conf, fanoutConf); err != nil {
+			return
+		}
+		formatter = fanout.formatter()
+		out = new(NullWriter)
+	} else if formatter, err = loggerFormatter(conf, formatter); err != nil {
+		return
+	}
 
 	confHooks := conf.GetConfig("hooks")
+	strictHooks := conf.GetBoolean("strict-hooks", false)
+
+	var enabledHookNames []string
 
 	if confHooks != nil {
 		hookNames := confHooks.Keys()
 
 		for i := 0; i < len(hookNames); i++ {
+			hookConf := confHooks.GetConfig(hookNames[i])
+			if hookConf != nil && !hookConf.GetBoolean("enabled", true) {
+				continue
+			}
+
 			var hook logrus.Hook
-			if hook, err = NewHook(hookNames[i], confHooks.GetConfig(hookNames[i])); err != nil {
+			if hook, err = NewHook(hookNames[i], hookConf); err != nil {
 				return
 			}
-			hooks = append(hooks, hook)
+
+			_, pre := hook.(interface{ PreHook() })
+
+			// the hook with `breaker` stops firing for a while after the consecutive failures
+			if hookConf != nil && hookConf.HasPath("breaker") {
+				hook = &breakerHook{hook: hook, breaker: breaker.New(breaker.OptionsFromConfig(hookConf.GetConfig("breaker")))}
+			}
+
+			// the hook with `when` fires only for the entries matching its fields
+			if hookConf != nil && hookConf.HasPath("when") {
+				var predicates []*fieldPredicate
+				if predicates, err = newFieldPredicates(hookConf); err != nil {
+					return
+				}
+				hook = &predicateHook{hook: hook, predicates: predicates}
+			}
+
+			if pre {
+				preHooks = append(preHooks, newSafeHook(hookNames[i], hook, strictHooks))
+			} else {
+				hooks = append(hooks, newSafeHook(hookNames[i], hook, strictHooks))
+			}
+			enabledHookNames = append(enabledHookNames, hookNames[i])
 		}
 	}
 
-	level := conf.GetString("level")
+	if len(preHooks) > 0 {
+		hooks = append(hooks[:preIndex], append(preHooks, hooks[preIndex:]...)...)
+	}
 
-	if len(level) == 0 {
-		level = "info"
+	// the split out writes by the entry level, the entries are written by the
+	// last hook which knows it, the out of logger writes nothing
+	if splitWriter, ok := out.(*SplitWriter); ok {
+		hooks = append(hooks, &splitHook{writer: splitWriter})
+		out = new(NullWriter)
 	}
 
-	var lvl = logrus.DebugLevel
-	if lvl, err = logrus.ParseLevel(level); err != nil {
-		return
+	if fanout != nil {
+		hooks = append(hooks, fanout)
 	}
 
 	l := logrus.New()
 
 	l.Level = lvl
+	l.ReportCaller = conf.GetBoolean("report-caller", false)
+	if conf.GetBoolean("buffer-pool", false) {
+		l.SetBufferPool(sharedBufferPool)
+	}
+	// only for the logger used by a single goroutine, the concurrent logging would race
+	nolock := conf.GetBoolean("nolock", false)
 	l.Out = out
 	l.Formatter = formatter
 	for i := 0; i < len(hooks); i++ {
 		l.Hooks.Add(hooks[i])
 	}
 
-	*logger = *l
+	var hb *heartbeat
+	if heartbeatConf := conf.GetConfig("heartbeat"); heartbeatConf != nil {
+		if hb, err = newHeartbeat(logger, heartbeatConf); err != nil {
+			return
+		}
+		l.Hooks.Add(hb)
+	}
+
+	mirrorFunc, err := mirrorStandardLogger(conf.GetString("mirror"))
+	if err != nil {
+		return
+	}
+
+	commit = func() {
+		registerLevelAliases(aliases)
+
+		// the heartbeat of the previous hijack stops after the swap
+		closeHeartbeats(replaceLogger(logger, l, nolock), l.Hooks)
+
+		if hb != nil {
+			hb.start()
+		}
+
+		mirrorFunc(logger)
+
+		if conf.GetBoolean("startup-banner", false) {
+			logStartupBanner(logger, conf, outName, formatterName, enabledHookNames)
+		}
+	}
 
 	return
 }
 
+// noLockLoggers are the loggers hijacked with nolock
+var noLockLoggers sync.Map
+
+// replaceLogger sets the config of l to logger by the setters taking its lock,
+// so a logger in use could be hijacked again, e.g. by the remote config updates,
+// and returns the hooks replaced
+func replaceLogger(logger, l *logrus.Logger, nolock bool) (replaced logrus.LevelHooks) {
+	logger.SetLevel(l.Level)
+	logger.SetReportCaller(l.ReportCaller)
+	logger.SetBufferPool(l.BufferPool)
+	logger.SetFormatter(l.Formatter)
+	logger.SetOutput(l.Out)
+	replaced = logger.ReplaceHooks(l.Hooks)
+
+	if nolock {
+		logger.SetNoLock()
+		noLockLoggers.Store(logger, struct{}{})
+	}
+
+	return
+}
+
+// loggerFormatter wraps the formatter of logger by the fallback of config and
+// the features depending on it, the same for the config and SetFormatter
+func loggerFormatter(conf config.Configuration, formatter logrus.Formatter) (logrus.Formatter, error) {
+	formatter, err := withFallbackFormatter(formatter, conf.GetConfig("formatter"))
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapFormatter(conf, formatter), nil
+}
+
+// wrapFormatter wraps the formatter for the features depending on it
+func wrapFormatter(conf config.Configuration, formatter logrus.Formatter) logrus.Formatter {
+	if omit, defaultString, err := parseEmptyMessage(conf); err == nil && (omit || len(defaultString) > 0) {
+		formatter = &emptyMessageFormatter{Formatter: formatter, omit: omit, defaultString: defaultString}
+	}
+
+	if conf.GetConfig("sample") != nil || conf.GetConfig("route") != nil || conf.GetConfig("relevel") != nil ||
+		conf.GetConfig("batch") != nil || conf.GetConfig("quiet-hours") != nil || conf.GetConfig("level-override") != nil {
+		formatter = &dropFormatter{Formatter: formatter}
+	}
+
+	if encoding := conf.GetString("encoding"); len(encoding) > 0 {
+		formatter = &encodingFormatter{Formatter: formatter, ascii: encoding == "ascii"}
+	}
+
+	return formatter
+}
+
 func NewLogrusMate(opts ...Option) (logrusMate *LogrusMate, err error) {
 	mate := &LogrusMate{
 		loggersConf: sync.Map{},
@@ -126,6 +443,11 @@ func NewLogrusMate(opts ...Option) (logrusMate *LogrusMate, err error) {
 		o(&logrusMateConf)
 	}
 
+	if logrusMateConf.err != nil {
+		err = logrusMateConf.err
+		return
+	}
+
 	conf := config.NewConfig(logrusMateConf.configOpts...)
 
 	if conf == nil {
@@ -136,7 +458,24 @@ func NewLogrusMate(opts ...Option) (logrusMate *LogrusMate, err error) {
 	loggerNames := conf.Keys()
 
 	for i := 0; i < len(loggerNames); i++ {
-		mate.loggersConf.LoadOrStore(loggerNames[i], conf.GetConfig(loggerNames[i]))
+		loggerConf := conf.GetConfig(loggerNames[i])
+
+		// the loggers are created by Logger(name) later, the formatter misspelled fails here
+		if loggerConf != nil {
+			if err = checkFormatters(loggerConf); err != nil {
+				err = fmt.Errorf("%s, logger %s", err, loggerNames[i])
+				return
+			}
+		}
+
+		mate.loggersConf.LoadOrStore(loggerNames[i], loggerConf)
+	}
+
+	if len(logrusMateConf.remotes) > 0 {
+		mate.remoteStop = make(chan struct{})
+		for _, source := range logrusMateConf.remotes {
+			go mate.watchRemote(source, mate.remoteStop)
+		}
 	}
 
 	logrusMate = mate
@@ -160,6 +499,11 @@ func (p *LogrusMate) Hijack(logger *logrus.Logger, loggerName string, opts ...Op
 			o(&newConf)
 		}
 
+		if newConf.err != nil {
+			err = newConf.err
+			return
+		}
+
 		conf2 := config.NewConfig(newConf.configOpts...)
 
 		err = hijackByConfig(
@@ -179,10 +523,7 @@ func (p *LogrusMate) Logger(loggerName ...string) (logger *logrus.Logger) {
 	name := "default"
 
 	if len(loggerName) > 0 {
-		name = strings.TrimSpace(loggerName[0])
-		if len(name) == 0 {
-			name = "default"
-		}
+		name = loggerNameOrDefault(loggerName[0])
 	}
 
 	lv, exist := p.loggers.Load(name)
@@ -205,4 +546,129 @@ func (p *LogrusMate) Logger(loggerName ...string) (logger *logrus.Logger) {
 	p.loggers.LoadOrStore(name, l)
 
 	return l
+}
+
+// Buffer returns a snapshot of the output of logger which out.name is buffer,
+// the logger is created by Logger(name) if not exist
+func (p *LogrusMate) Buffer(loggerName string) (buf *bytes.Buffer, exist bool) {
+	w, exist := p.bufferWriter(loggerName)
+	if !exist {
+		return
+	}
+
+	return bytes.NewBuffer(w.Bytes()), true
+}
+
+// ResetBuffer discards the output of logger which out.name is buffer
+func (p *LogrusMate) ResetBuffer(loggerName string) (exist bool) {
+	w, exist := p.bufferWriter(loggerName)
+	if !exist {
+		return
+	}
+
+	w.Reset()
+
+	return
+}
+
+func (p *LogrusMate) bufferWriter(loggerName string) (w *BufferWriter, exist bool) {
+	l := p.Logger(loggerName)
+	if l == nil {
+		return
+	}
+
+	w, exist = l.Out.(*BufferWriter)
+
+	return
+}
+
+// LoggerWith returns an entry of the named logger carrying the static fields,
+// it shares the level, formatter and hooks of the logger, so the later changes
+// of the logger also apply. Unlike calling WithFields on each log call, the fields
+// are attached once and the entry could be passed to the subsystem as its logger.
+func (p *LogrusMate) LoggerWith(loggerName string, fields logrus.Fields) *logrus.Entry {
+	l := p.Logger(loggerName)
+	if l == nil {
+		return nil
+	}
+
+	return l.WithFields(fields)
+}
+
+// AddHook attaches the hook constructed in code to the named logger, e.g. the hook
+// needs a live dependency like *sql.DB. It is guarded the same as the configured hooks,
+// and it is safe to add while logging, the entries being logged meanwhile may miss it.
+func (p *LogrusMate) AddHook(loggerName string, hook logrus.Hook) (err error) {
+	l := p.Logger(loggerName)
+	if l == nil {
+		err = ErrLoggerNotExist
+		return
+	}
+
+	strict := false
+	if confV, exist := p.loggersConf.Load(loggerNameOrDefault(loggerName)); exist {
+		strict = confV.(config.Configuration).GetBoolean("strict-hooks", false)
+	}
+
+	l.AddHook(newSafeHook(fmt.Sprintf("%T", hook), hook, strict))
+
+	return
+}
+
+func loggerNameOrDefault(loggerName string) string {
+	name := strings.TrimSpace(loggerName)
+	if len(name) == 0 {
+		return "default"
+	}
+	return name
+}
+
+// SetFormatter swaps the formatter of the named logger at runtime, the formatter
+// is created by the registry with the options the same as config, and wrapped
+// by the fallback and features of the logger config, e.g.
+// SetFormatter("mike", "json", map[string]interface{}{"timestamp_format": time.RFC3339Nano})
+// The logger with fanout is rejected, its outputs have their own formatters.
+func (p *LogrusMate) SetFormatter(loggerName string, formatterName string, options map[string]interface{}) (err error) {
+	l := p.Logger(loggerName)
+	if l == nil {
+		err = ErrLoggerNotExist
+		return
+	}
+
+	var conf config.Configuration
+	if confV, exist := p.loggersConf.Load(loggerNameOrDefault(loggerName)); exist {
+		conf = confV.(config.Configuration)
+	}
+
+	if conf != nil && conf.GetConfig("fanout") != nil {
+		err = fmt.Errorf("logurs mate: set formatter of logger %s: the fanout outputs have their own formatters", loggerName)
+		return
+	}
+
+	var optionsConf config.Configuration
+	if len(options) > 0 {
+		var data []byte
+		if data, err = json.Marshal(options); err != nil {
+			err = fmt.Errorf("logurs mate: formatter options of %s: %s", formatterName, err)
+			return
+		}
+		optionsConf = config.NewConfig(config.ConfigString(string(data)))
+	}
+
+	var formatter logrus.Formatter
+	if formatter, err = NewFormatter(formatterName, optionsConf); err != nil {
+		err = fmt.Errorf("logurs mate: set formatter %s of logger %s: %s", formatterName, loggerName, err)
+		return
+	}
+
+	if conf != nil {
+		if formatter, err = loggerFormatter(conf, formatter); err != nil {
+			err = fmt.Errorf("logurs mate: set formatter %s of logger %s: %s", formatterName, loggerName, err)
+			return
+		}
+	}
+
+	l.SetFormatter(formatter)
+
+	return
 }
@@ -0,0 +1,129 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// the hooks of tests, test-record records the entries by its id, test-panic
+// panics, test-fail fails every Fire
+func init() {
+	RegisterHook("test-record", func(conf config.Configuration) (logrus.Hook, error) {
+		id := ""
+		if conf != nil {
+			id = conf.GetString("id")
+		}
+		hook := &recordHook{}
+		recordHooks.Store(id, hook)
+		return hook, nil
+	})
+
+	RegisterHook("test-panic", func(config.Configuration) (logrus.Hook, error) {
+		return panicHook{}, nil
+	})
+
+	RegisterHook("test-fail", func(config.Configuration) (logrus.Hook, error) {
+		return &failHook{}, nil
+	})
+}
+
+var recordHooks sync.Map
+
+// recordedBy returns the test-record hook of id created by the last hijack
+func recordedBy(t *testing.T, id string) *recordHook {
+	t.Helper()
+
+	hook, exist := recordHooks.Load(id)
+	if !exist {
+		t.Fatalf("no test-record hook of id %q", id)
+	}
+	return hook.(*recordHook)
+}
+
+type recordedEntry struct {
+	Level   logrus.Level
+	Message string
+	Data    logrus.Fields
+}
+
+type recordHook struct {
+	locker  sync.Mutex
+	entries []recordedEntry
+}
+
+func (p *recordHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *recordHook) Fire(entry *logrus.Entry) error {
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.entries = append(p.entries, recordedEntry{Level: entry.Level, Message: entry.Message, Data: data})
+	return nil
+}
+
+func (p *recordHook) Entries() []recordedEntry {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	return append([]recordedEntry(nil), p.entries...)
+}
+
+type panicHook struct{}
+
+func (panicHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (panicHook) Fire(*logrus.Entry) error {
+	panic("test hook panic")
+}
+
+var errTestHook = errors.New("test hook failed")
+
+type failHook struct {
+	locker sync.Mutex
+	fired  int
+}
+
+func (p *failHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *failHook) Fire(*logrus.Entry) error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	p.fired++
+	return errTestHook
+}
+
+// hijackString hijacks a new logger by the logger config conf, the output is
+// written into the buffer returned
+func hijackString(t *testing.T, conf string) (*logrus.Logger, *bytes.Buffer) {
+	t.Helper()
+
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(conf)); err != nil {
+		t.Fatalf("hijack: %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	logger.Out = buf
+
+	return logger, buf
+}
+
+// configOf parses the config string
+func configOf(str string) config.Configuration {
+	return config.NewConfig(config.ConfigString(str))
+}
@@ -0,0 +1,74 @@
+package logrus_mate_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+	_ "github.com/gogap/logrus_mate/hooks/mask"
+)
+
+var capturedCards struct {
+	sync.Mutex
+	values []interface{}
+}
+
+func init() {
+	logrus_mate.RegisterHook("test-capture-card", func(config.Configuration) (logrus.Hook, error) {
+		return captureCardHook{}, nil
+	})
+}
+
+// captureCardHook records the card field seen by the hooks
+type captureCardHook struct{}
+
+func (captureCardHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (captureCardHook) Fire(entry *logrus.Entry) error {
+	capturedCards.Lock()
+	defer capturedCards.Unlock()
+	capturedCards.values = append(capturedCards.values, entry.Data["card"])
+	return nil
+}
+
+// the mask hook listed after another hook still fires first, the other hooks
+// and the output never see the unmasked value
+func TestMaskHookFiresFirst(t *testing.T) {
+	capturedCards.Lock()
+	capturedCards.values = nil
+	capturedCards.Unlock()
+
+	mate, err := logrus_mate.NewLogrusMate(logrus_mate.ConfigString(`
+		mike {
+			out.name = "buffer"
+			formatter.name = "json"
+			hooks {
+				test-capture-card {}
+				mask { fields { card = "last4" } }
+			}
+		}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mate.Logger("mike").WithField("card", "4111111111111111").Info("paid")
+
+	capturedCards.Lock()
+	values := capturedCards.values
+	capturedCards.Unlock()
+
+	if len(values) != 1 || values[0] != "************1111" {
+		t.Fatalf("the hook before mask in config sees %v", values)
+	}
+
+	buf, _ := mate.Buffer("mike")
+	if s := buf.String(); !strings.Contains(s, `"card":"************1111"`) || strings.Contains(s, "4111111111111111") {
+		t.Fatalf("output %q", s)
+	}
+}
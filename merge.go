@@ -0,0 +1,71 @@
+package logrus_mate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// MergeConflictError reports the logger names defined by both mates
+type MergeConflictError struct {
+	Names []string
+}
+
+func (p *MergeConflictError) Error() string {
+	return "logurs mate: loggers defined twice, " + strings.Join(p.Names, ", ")
+}
+
+// Merge adds the logger configs of other, e.g. the config fragments owned by
+// the subsystems. A logger name defined by both is a conflict, nothing is merged
+// and *MergeConflictError lists the names. Use MergeOverride for later-wins.
+func (p *LogrusMate) Merge(other *LogrusMate) (err error) {
+	return p.merge(other, false)
+}
+
+// MergeOverride adds the logger configs of other, the config of other wins on
+// the same name and the logger created by the replaced config is recreated by
+// the next Logger call, the one already returned keeps the old config.
+func (p *LogrusMate) MergeOverride(other *LogrusMate) (err error) {
+	return p.merge(other, true)
+}
+
+func (p *LogrusMate) merge(other *LogrusMate, override bool) (err error) {
+	if other == nil || other == p {
+		return
+	}
+
+	p.mergeLocker.Lock()
+	defer p.mergeLocker.Unlock()
+
+	confs := make(map[string]config.Configuration)
+	var conflicts []string
+
+	other.loggersConf.Range(func(k, v interface{}) bool {
+		name := k.(string)
+		if _, exist := p.loggersConf.Load(name); exist {
+			conflicts = append(conflicts, name)
+		}
+		confs[name] = v.(config.Configuration)
+		return true
+	})
+
+	if len(conflicts) > 0 && !override {
+		sort.Strings(conflicts)
+		err = &MergeConflictError{Names: conflicts}
+		return
+	}
+
+	for name, conf := range confs {
+		p.loggersConf.Store(name, conf)
+		p.loggers.Delete(name)
+
+		// share the logger already created by other, so both mates log the same
+		if lv, exist := other.loggers.Load(name); exist {
+			p.loggers.Store(name, lv.(*logrus.Logger))
+		}
+	}
+
+	return
+}
@@ -0,0 +1,105 @@
+package logrus_mate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestMate(t *testing.T, conf string) *LogrusMate {
+	t.Helper()
+
+	mate, err := NewLogrusMate(ConfigString(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mate
+}
+
+func TestMergeDisjoint(t *testing.T) {
+	mate := newTestMate(t, `api { level = "info", out.name = "nil" }`)
+	other := newTestMate(t, `db { level = "debug", out.name = "nil" }`)
+
+	if err := mate.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if l := mate.Logger("api"); l == nil || l.Level != logrus.InfoLevel {
+		t.Fatalf("api logger %v", l)
+	}
+	if l := mate.Logger("db"); l == nil || l.Level != logrus.DebugLevel {
+		t.Fatalf("the merged db logger %v", l)
+	}
+	// other is not changed
+	if l := other.Logger("api"); l != nil {
+		t.Fatal("api is merged into other")
+	}
+}
+
+func TestMergeOverlapping(t *testing.T) {
+	mate := newTestMate(t, `
+api { level = "info", out.name = "nil" }
+db { level = "info", out.name = "nil" }`)
+	other := newTestMate(t, `
+db { level = "debug", out.name = "nil" }
+cache { level = "warn", out.name = "nil" }
+api { level = "error", out.name = "nil" }`)
+
+	err := mate.Merge(other)
+	conflict, ok := err.(*MergeConflictError)
+	if !ok {
+		t.Fatalf("merge overlapping: %v", err)
+	}
+	if expected := []string{"api", "db"}; !reflect.DeepEqual(conflict.Names, expected) {
+		t.Fatalf("conflicts %v, expected %v", conflict.Names, expected)
+	}
+
+	// nothing is merged on the conflict, the disjoint cache neither
+	if l := mate.Logger("cache"); l != nil {
+		t.Fatal("cache is merged on the conflict")
+	}
+	if l := mate.Logger("db"); l == nil || l.Level != logrus.InfoLevel {
+		t.Fatalf("db logger %v", l)
+	}
+}
+
+func TestMergeOverride(t *testing.T) {
+	mate := newTestMate(t, `
+api { level = "info", out.name = "nil" }
+db { level = "info", out.name = "nil" }`)
+	other := newTestMate(t, `
+db { level = "debug", out.name = "nil" }
+cache { level = "warn", out.name = "nil" }`)
+
+	old := mate.Logger("db")
+	shared := other.Logger("cache")
+
+	if err := mate.MergeOverride(other); err != nil {
+		t.Fatal(err)
+	}
+
+	// the later config wins, the logger returned before keeps the old config
+	db := mate.Logger("db")
+	if db == nil || db == old || db.Level != logrus.DebugLevel || old.Level != logrus.InfoLevel {
+		t.Fatalf("db logger %v, old %v", db, old)
+	}
+	if l := mate.Logger("api"); l == nil || l.Level != logrus.InfoLevel {
+		t.Fatalf("api logger %v", l)
+	}
+	// the logger already created by other is shared
+	if l := mate.Logger("cache"); l != shared {
+		t.Fatalf("cache logger %v is not shared with other %v", l, shared)
+	}
+}
+
+func TestMergeSelfAndNil(t *testing.T) {
+	mate := newTestMate(t, `api { out.name = "nil" }`)
+
+	if err := mate.Merge(mate); err != nil {
+		t.Fatalf("merge self: %s", err)
+	}
+	if err := mate.Merge(nil); err != nil {
+		t.Fatalf("merge nil: %s", err)
+	}
+}
@@ -0,0 +1,88 @@
+package logrus_mate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type mirroredKey struct{}
+
+// mirrorHook tees the entries into the target logger, the mirrored entries are
+// marked in context and never mirrored back, so two loggers could mirror each other.
+// Panic entries are not mirrored, logging them would panic again in the hook.
+type mirrorHook struct {
+	target *logrus.Logger
+}
+
+func (p *mirrorHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.FatalLevel,
+		logrus.ErrorLevel,
+		logrus.WarnLevel,
+		logrus.InfoLevel,
+		logrus.DebugLevel,
+		logrus.TraceLevel,
+	}
+}
+
+func (p *mirrorHook) Fire(entry *logrus.Entry) (err error) {
+	if isDropped(entry) {
+		return
+	}
+
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	} else if ctx.Value(mirroredKey{}) != nil {
+		return
+	}
+
+	p.target.WithFields(entry.Data).
+		WithTime(entry.Time).
+		WithContext(context.WithValue(ctx, mirroredKey{}, true)).
+		Log(entry.Level, entry.Message)
+
+	return
+}
+
+// mirror adds the hook into from logger to tee entries into the logger to
+func mirror(from, to *logrus.Logger) {
+	if from == to {
+		return
+	}
+
+	for _, hook := range from.Hooks[logrus.InfoLevel] {
+		if m, ok := hook.(*mirrorHook); ok && m.target == to {
+			return
+		}
+	}
+
+	from.AddHook(&mirrorHook{target: to})
+}
+
+// mirrorStandardLogger returns the func mirroring the logger with logrus.StandardLogger()
+// by direction: to-standard, from-standard or both, it is resolved before the commit
+// of hijack, so the unknown direction fails the hijack and the commit never fails
+func mirrorStandardLogger(direction string) (mirrorFunc func(logger *logrus.Logger), err error) {
+	std := logrus.StandardLogger()
+
+	switch direction {
+	case "":
+		mirrorFunc = func(*logrus.Logger) {}
+	case "to-standard":
+		mirrorFunc = func(logger *logrus.Logger) { mirror(logger, std) }
+	case "from-standard":
+		mirrorFunc = func(logger *logrus.Logger) { mirror(std, logger) }
+	case "both":
+		mirrorFunc = func(logger *logrus.Logger) {
+			mirror(logger, std)
+			mirror(std, logger)
+		}
+	default:
+		err = fmt.Errorf("logurs mate: unknown mirror direction: %s", direction)
+	}
+
+	return
+}
@@ -0,0 +1,64 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// standardBuffer writes the standard logger into the buffer until the test ends
+func standardBuffer(t *testing.T) *bytes.Buffer {
+	std := logrus.StandardLogger()
+	out, hooks, formatter := std.Out, std.Hooks, std.Formatter
+	t.Cleanup(func() {
+		std.Out, std.Hooks, std.Formatter = out, hooks, formatter
+	})
+
+	buf := &bytes.Buffer{}
+	std.Out = buf
+	std.Hooks = make(logrus.LevelHooks)
+	std.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	return buf
+}
+
+func TestMirrorToStandard(t *testing.T) {
+	stdBuf := standardBuffer(t)
+	logger, buf := hijackString(t, `mirror = "to-standard"`)
+
+	logger.Info("from mate")
+	logrus.Info("from standard")
+
+	if n := strings.Count(stdBuf.String(), "from mate"); n != 1 {
+		t.Fatalf("the standard logger gets %d entries of mate: %q", n, stdBuf.String())
+	}
+	if strings.Contains(buf.String(), "from standard") {
+		t.Fatalf("the standard entry is mirrored back: %q", buf.String())
+	}
+}
+
+func TestMirrorBoth(t *testing.T) {
+	stdBuf := standardBuffer(t)
+	logger, buf := hijackString(t, `mirror = "both"`)
+
+	logger.Info("from mate")
+	logrus.Info("from standard")
+
+	for _, out := range []string{stdBuf.String(), buf.String()} {
+		if strings.Count(out, "from mate") != 1 || strings.Count(out, "from standard") != 1 {
+			t.Fatalf("each entry is expected once: %q", out)
+		}
+	}
+}
+
+func TestMirrorUnknownDirection(t *testing.T) {
+	standardBuffer(t)
+
+	if err := Hijack(logrus.New(), ConfigString(`mirror = "sideways"`)); err == nil {
+		t.Fatal("the unknown direction is accepted")
+	}
+	if len(logrus.StandardLogger().Hooks) != 0 {
+		t.Fatal("the failed hijack mirrors")
+	}
+}
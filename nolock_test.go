@@ -0,0 +1,74 @@
+package logrus_mate
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lockDisabled reads the unexported MutexWrap.disabled of logger
+func lockDisabled(logger *logrus.Logger) bool {
+	return reflect.ValueOf(logger).Elem().FieldByName("mu").FieldByName("disabled").Bool()
+}
+
+func TestNoLock(t *testing.T) {
+	logger, buf := hijackString(t, `nolock = true`)
+	if !lockDisabled(logger) {
+		t.Fatal("nolock = true should disable the lock of logger")
+	}
+
+	logger.Info("single goroutine")
+	if buf.Len() == 0 {
+		t.Fatal("the logger without lock writes nothing")
+	}
+
+	if logger, _ = hijackString(t, `level = "info"`); lockDisabled(logger) {
+		t.Fatal("the logger should be locked by default")
+	}
+}
+
+func TestNoLockHijackAgain(t *testing.T) {
+	logger, _ := hijackString(t, `nolock = true`)
+
+	// the lock could not be turned on again
+	for _, conf := range []string{`level = "info"`, `nolock = false`, `enabled = false`} {
+		if err := Hijack(logger, ConfigString(conf)); err == nil {
+			t.Fatalf("%s: the nolock logger is hijacked with the lock", conf)
+		}
+	}
+	if !lockDisabled(logger) {
+		t.Fatal("the lock of logger is changed by the failed hijack")
+	}
+
+	if err := Hijack(logger, ConfigString(`nolock = true, level = "debug"`)); err != nil {
+		t.Fatal(err)
+	}
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("level %s", logger.GetLevel())
+	}
+}
+
+func benchmarkNoLock(b *testing.B, conf string) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(conf)); err != nil {
+		b.Fatal(err)
+	}
+	logger.Out = ioutil.Discard
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("benchmark")
+	}
+}
+
+func BenchmarkLoggerLocked(b *testing.B) {
+	benchmarkNoLock(b, `formatter.name = "json"`)
+}
+
+func BenchmarkLoggerNoLock(b *testing.B) {
+	benchmarkNoLock(b, `formatter.name = "json", nolock = true`)
+}
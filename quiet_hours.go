@@ -0,0 +1,123 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// quietWindow is the minutes of day [from, to), it crosses midnight when from > to
+type quietWindow struct {
+	from int
+	to   int
+}
+
+func (p quietWindow) contains(minute int) bool {
+	if p.from <= p.to {
+		return minute >= p.from && minute < p.to
+	}
+	return minute >= p.from || minute < p.to
+}
+
+// quietHoursHook drops the entries less severe than level within the windows,
+// e.g. the nights or the maintenance windows. The level of logger is unchanged,
+// the dropped entries are neither written nor passed to the other hooks.
+type quietHoursHook struct {
+	level    logrus.Level
+	windows  []quietWindow
+	location *time.Location
+
+	// now is injectable for tests
+	now func() time.Time
+}
+
+// newQuietHoursHook parses the config like:
+// quiet-hours { level = "error", windows = ["22:00-06:00", "12:00-12:30"], timezone = "Asia/Shanghai" }
+func newQuietHoursHook(conf config.Configuration) (hook *quietHoursHook, err error) {
+	hook = &quietHoursHook{
+		location: time.Local,
+		now:      time.Now,
+	}
+
+	if hook.level, err = ParseLevel(conf.GetString("level", "error")); err != nil {
+		return
+	}
+
+	if tz := conf.GetString("timezone"); len(tz) > 0 {
+		if hook.location, err = time.LoadLocation(tz); err != nil {
+			err = fmt.Errorf("logurs mate: quiet-hours timezone %s: %s", tz, err)
+			return
+		}
+	}
+
+	for _, s := range conf.GetStringList("windows") {
+		var window quietWindow
+		if window, err = parseQuietWindow(s); err != nil {
+			return
+		}
+		hook.windows = append(hook.windows, window)
+	}
+
+	return
+}
+
+// parseQuietWindow parses "22:00-06:00", the end 24:00 is the midnight
+func parseQuietWindow(s string) (window quietWindow, err error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		err = fmt.Errorf("logurs mate: quiet-hours window should be like 22:00-06:00, but got %q", s)
+		return
+	}
+
+	if window.from, err = parseMinuteOfDay(parts[0]); err != nil {
+		err = fmt.Errorf("logurs mate: quiet-hours window %q: %s", s, err)
+		return
+	}
+
+	if window.to, err = parseMinuteOfDay(parts[1]); err != nil {
+		err = fmt.Errorf("logurs mate: quiet-hours window %q: %s", s, err)
+		return
+	}
+
+	return
+}
+
+func parseMinuteOfDay(s string) (int, error) {
+	var hour, minute int
+	if n, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &hour, &minute); err != nil || n != 2 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	return hour*60 + minute, nil
+}
+
+func (p *quietHoursHook) quiet() bool {
+	now := p.now().In(p.location)
+	minute := now.Hour()*60 + now.Minute()
+
+	for _, window := range p.windows {
+		if window.contains(minute) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *quietHoursHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *quietHoursHook) Fire(entry *logrus.Entry) error {
+	if entry.Level > p.level && p.quiet() {
+		markDropped(entry)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// quietHoursOf returns the quiet-hours hook of logger, its clock set to now
+func quietHoursOf(t *testing.T, logger *logrus.Logger, now *time.Time) *quietHoursHook {
+	t.Helper()
+
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if q, ok := hook.(*quietHoursHook); ok {
+			q.now = func() time.Time { return *now }
+			return q
+		}
+	}
+
+	t.Fatal("no quiet-hours hook")
+	return nil
+}
+
+func TestQuietHoursDropsInsideWindow(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+quiet-hours { level = "error", windows = ["22:00-06:00", "12:00-12:30"], timezone = "UTC" }
+hooks.test-record.id = "quiet"`)
+
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	quietHoursOf(t, logger, &now)
+
+	for _, c := range []struct {
+		at    string
+		quiet bool
+	}{
+		{"23:00", true},
+		{"03:59", true},
+		{"06:00", false},
+		{"11:59", false},
+		{"12:00", true},
+		{"12:30", false},
+		{"21:59", false},
+		{"22:00", true},
+	} {
+		at, _ := time.Parse("15:04", c.at)
+		now = time.Date(2024, 1, 1, at.Hour(), at.Minute(), 0, 0, time.UTC)
+		buf.Reset()
+
+		logger.Info("info at " + c.at)
+		logger.Error("error at " + c.at)
+
+		s := buf.String()
+		if strings.Contains(s, "info at") == c.quiet || !strings.Contains(s, "error at") {
+			t.Fatalf("at %s quiet %v, output %q", c.at, c.quiet, s)
+		}
+	}
+
+	// the dropped entries are not passed to the hooks
+	for _, e := range recordedBy(t, "quiet").Entries() {
+		if e.Level == logrus.InfoLevel && (e.Message == "info at 23:00" || e.Message == "info at 12:00") {
+			t.Fatalf("the hooks see %q", e.Message)
+		}
+	}
+
+	// the level of logger is unchanged
+	if logger.Level != logrus.InfoLevel {
+		t.Fatalf("level %s", logger.Level)
+	}
+}
+
+func TestQuietHoursTimezone(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+quiet-hours { windows = ["22:00-06:00"], timezone = "Asia/Tokyo" }`)
+
+	// 14:00 UTC is 23:00 in Tokyo
+	now := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	quietHoursOf(t, logger, &now)
+
+	logger.Warn("dropped")
+	now = now.Add(8 * time.Hour)
+	logger.Warn("written")
+
+	if s := buf.String(); strings.Contains(s, "dropped") || !strings.Contains(s, "written") {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestQuietHoursInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`quiet-hours { windows = ["22:00"] }`,
+		`quiet-hours { windows = ["25:00-06:00"] }`,
+		`quiet-hours { windows = ["22:60-06:00"] }`,
+		`quiet-hours { level = "loud", windows = ["22:00-06:00"] }`,
+		`quiet-hours { timezone = "Mars/Olympus", windows = ["22:00-06:00"] }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Fatalf("%s is accepted", conf)
+		}
+	}
+}
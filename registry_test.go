@@ -0,0 +1,64 @@
+package logrus_mate_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+
+	"github.com/gogap/logrus_mate"
+	_ "github.com/gogap/logrus_mate/hooks/file"
+)
+
+func init() {
+	logrus_mate.RegisterHook("registry-test", func(config.Configuration) (logrus.Hook, error) {
+		return nil, nil
+	})
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegisteredHooks(t *testing.T) {
+	hooks := logrus_mate.RegisteredHooks()
+
+	// registered by importing hooks/file
+	if !contains(hooks, "file") {
+		t.Fatalf("file is not in %v", hooks)
+	}
+	if !sort.StringsAreSorted(hooks) {
+		t.Fatalf("not sorted: %v", hooks)
+	}
+
+	// registered by init of the test
+	if !contains(hooks, "registry-test") {
+		t.Fatalf("registry-test is not in %v", hooks)
+	}
+}
+
+func TestRegisteredFormatters(t *testing.T) {
+	formatters := logrus_mate.RegisteredFormatters()
+	for _, name := range []string{"json", "text"} {
+		if !contains(formatters, name) {
+			t.Fatalf("%s is not in %v", name, formatters)
+		}
+	}
+	if !sort.StringsAreSorted(formatters) {
+		t.Fatalf("not sorted: %v", formatters)
+	}
+}
+
+func TestUnknownHookListsRegistered(t *testing.T) {
+	_, err := logrus_mate.NewHook("no-such-hook", nil)
+	if err == nil || !strings.Contains(err.Error(), "no-such-hook") || !strings.Contains(err.Error(), "file") {
+		t.Fatalf("error %v", err)
+	}
+}
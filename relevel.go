@@ -0,0 +1,157 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// relevelRule rewrites the level of the entries matching the message pattern
+// and logged by the package, the empty conditions match all
+type relevelRule struct {
+	name  string
+	match *regexp.Regexp
+	pkg   string
+	from  map[logrus.Level]bool
+	level logrus.Level
+}
+
+// relevelHook rewrites entry.Level by the first matched rule. logrus picks the
+// hooks to fire by the original level before, so the rewrite changes the level
+// written by the formatter and seen by the later hooks in entry.Level, but the
+// hooks fired, the level filter of logger, and the panic or exit of Panic and
+// Fatal still follow the original level. The entry rewritten below the level
+// of logger is dropped from the output and the other hooks.
+type relevelHook struct {
+	logger *logrus.Logger
+	rules  []*relevelRule
+}
+
+// newRelevelHook parses the rules like:
+// rules { db-noise { match = "connection reset", package = "github.com/go-sql-driver", from = ["error"], level = "info" } }
+func newRelevelHook(logger *logrus.Logger, conf config.Configuration) (hook *relevelHook, err error) {
+	hook = &relevelHook{logger: logger}
+
+	rulesConf := conf.GetConfig("rules")
+	if rulesConf == nil {
+		return
+	}
+
+	names := rulesConf.Keys()
+	sort.Strings(names)
+
+	for _, name := range names {
+		ruleConf := rulesConf.GetConfig(name)
+		if ruleConf == nil {
+			err = fmt.Errorf("logurs mate: relevel rule %s should be { match, package, from, level }", name)
+			return
+		}
+
+		rule := &relevelRule{
+			name: name,
+			pkg:  ruleConf.GetString("package"),
+			from: make(map[logrus.Level]bool),
+		}
+
+		if match := ruleConf.GetString("match"); len(match) > 0 {
+			if rule.match, err = regexp.Compile(match); err != nil {
+				err = fmt.Errorf("logurs mate: relevel rule %s: %s", name, err)
+				return
+			}
+		}
+
+		for _, from := range ruleConf.GetStringList("from") {
+			var lvl logrus.Level
+			if lvl, err = ParseLevel(from); err != nil {
+				err = fmt.Errorf("logurs mate: relevel rule %s: %s", name, err)
+				return
+			}
+			rule.from[lvl] = true
+		}
+
+		if rule.level, err = ParseLevel(ruleConf.GetString("level")); err != nil {
+			err = fmt.Errorf("logurs mate: relevel rule %s: %s", name, err)
+			return
+		}
+
+		hook.rules = append(hook.rules, rule)
+	}
+
+	return
+}
+
+func (p *relevelHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *relevelHook) Fire(entry *logrus.Entry) error {
+	pkg := ""
+
+	for _, rule := range p.rules {
+		if len(rule.from) > 0 && !rule.from[entry.Level] {
+			continue
+		}
+
+		if rule.match != nil && !rule.match.MatchString(entry.Message) {
+			continue
+		}
+
+		if len(rule.pkg) > 0 {
+			if len(pkg) == 0 {
+				pkg = callerPackage(entry)
+			}
+			if !strings.HasPrefix(pkg, rule.pkg) {
+				continue
+			}
+		}
+
+		entry.Level = rule.level
+
+		if !p.logger.IsLevelEnabled(rule.level) {
+			markDropped(entry)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// callerPackage returns the package logging the entry, by entry.Caller with
+// report-caller, or else by walking the stack out of logrus and mate
+func callerPackage(entry *logrus.Entry) string {
+	if entry.Caller != nil {
+		return funcPackage(entry.Caller.Function)
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		pkg := funcPackage(frame.Function)
+		if pkg != "github.com/sirupsen/logrus" && pkg != "github.com/gogap/logrus_mate" {
+			return pkg
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcPackage returns the package of the func name like github.com/a/b.(*T).Method
+func funcPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
@@ -0,0 +1,117 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const relevelConf = `
+level = "info"
+hooks.test-record.id = "relevel"
+relevel.rules {
+    db-noise { match = "connection reset", from = ["error"], level = "info" }
+    cache-miss { match = "^cache miss", level = "debug" }
+    upgrade { match = "data lost", from = ["info"], level = "error" }
+}`
+
+func TestRelevelPatternDowngrade(t *testing.T) {
+	logger, buf := hijackString(t, relevelConf)
+
+	logger.Error("read: connection reset by peer")
+	logger.Warn("write: connection reset by peer")
+	logger.Error("disk full")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines %q", lines)
+	}
+
+	// the matched error is written as info, the warn is not in from, the other error is kept
+	for i, expected := range []string{"level=info", "level=warning", "level=error"} {
+		if !strings.Contains(lines[i], expected) {
+			t.Fatalf("line %d %q, expected %s", i, lines[i], expected)
+		}
+	}
+
+	entries := recordedBy(t, "relevel").Entries()
+	if len(entries) != 3 || entries[0].Level != logrus.InfoLevel || entries[2].Level != logrus.ErrorLevel {
+		t.Fatalf("the hooks see %v", entries)
+	}
+}
+
+func TestRelevelBelowLoggerLevel(t *testing.T) {
+	logger, buf := hijackString(t, relevelConf)
+
+	// downgraded below the info of logger, dropped from the output and the hooks
+	logger.Warn("cache miss of user 1")
+	logger.Info("cache hit, no cache miss")
+
+	if s := buf.String(); strings.Contains(s, "of user 1") || !strings.Contains(s, "cache hit") {
+		t.Fatalf("output %q", s)
+	}
+
+	entries := recordedBy(t, "relevel").Entries()
+	if len(entries) != 1 || entries[0].Message != "cache hit, no cache miss" {
+		t.Fatalf("the hooks see %v", entries)
+	}
+}
+
+func TestRelevelUpgrade(t *testing.T) {
+	logger, buf := hijackString(t, relevelConf)
+
+	logger.Info("data lost on shutdown")
+
+	if s := buf.String(); !strings.Contains(s, "level=error") {
+		t.Fatalf("output %q", s)
+	}
+}
+
+func TestRelevelPackage(t *testing.T) {
+	conf := `
+relevel.rules {
+    a-other { package = "github.com/other", level = "error" }
+    b-mine { package = "github.com/gogap/logrus_mate", level = "warn" }
+    c-testing { package = "testing", level = "debug" }
+}`
+
+	// the caller reported is this test of the mate package
+	logger, buf := hijackString(t, "level = \"debug\", report-caller = true"+conf)
+	logger.Info("logged by test")
+
+	if s := buf.String(); !strings.Contains(s, "level=warning") {
+		t.Fatalf("by caller: %q", s)
+	}
+
+	// walking the stack skips logrus and mate, the internal test is called by testing
+	logger, buf = hijackString(t, "level = \"debug\""+conf)
+	logger.Info("logged by test")
+
+	if s := buf.String(); !strings.Contains(s, "level=debug") {
+		t.Fatalf("by stack: %q", s)
+	}
+}
+
+func TestRelevelInvalid(t *testing.T) {
+	for _, conf := range []string{
+		`relevel.rules { r { match = "(", level = "info" } }`,
+		`relevel.rules { r { level = "loud" } }`,
+		`relevel.rules { r { from = ["loud"], level = "info" } }`,
+		`relevel.rules { r = "info" }`,
+	} {
+		if err := Hijack(logrus.New(), ConfigString(conf)); err == nil {
+			t.Errorf("%s: expected error", conf)
+		}
+	}
+}
+
+func TestRelevelSchema(t *testing.T) {
+	if errs := validateConfig(configOf("mike {" + relevelConf + "}")); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if errs := validateConfig(configOf(`mike.relevel.rules.r { match = "x", levl = "info" }`)); len(errs) != 1 {
+		t.Fatalf("the misspelled key is not reported: %v", errs)
+	}
+}
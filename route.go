@@ -0,0 +1,126 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// routeTarget is a named output with its own formatter, the entry is
+// formatted and written under lock the same as logger does
+type routeTarget struct {
+	locker    sync.Mutex
+	out       io.Writer
+	formatter logrus.Formatter
+}
+
+// routeHook sends the entry carrying the route field to the target of its value,
+// the field is stripped before formatting, unknown targets stay in the logger. The exclusive routed entries are
+// dropped from the logger output and the other hooks.
+type routeHook struct {
+	field     string
+	exclusive bool
+	targets   map[string]*routeTarget
+}
+
+func newRouteHook(conf config.Configuration) (hook *routeHook, err error) {
+	hook = &routeHook{
+		field:     conf.GetString("field", "_route"),
+		exclusive: conf.GetBoolean("exclusive", true),
+		targets:   make(map[string]*routeTarget),
+	}
+
+	targetsConf := conf.GetConfig("targets")
+	if targetsConf == nil {
+		return
+	}
+
+	for _, name := range targetsConf.Keys() {
+		var target *routeTarget
+		if target, err = newRouteTarget(targetsConf.GetConfig(name)); err != nil {
+			err = fmt.Errorf("logurs mate: route target %s: %s", name, err)
+			return
+		}
+
+		hook.targets[name] = target
+	}
+
+	return
+}
+
+// newRouteTarget creates the out and formatter of target, stdout and text by default
+func newRouteTarget(conf config.Configuration) (target *routeTarget, err error) {
+	outName, formatterName := "stdout", "text"
+	var outOptionsConf, formatterConf, formatterOptionsConf config.Configuration
+
+	if conf != nil {
+		if outConf := conf.GetConfig("out"); outConf != nil {
+			outName = outConf.GetString("name", outName)
+			outOptionsConf = outConf.GetConfig("options")
+		}
+
+		if formatterConf = conf.GetConfig("formatter"); formatterConf != nil {
+			formatterName = formatterConf.GetString("name", formatterName)
+			formatterOptionsConf = formatterConf.GetConfig("options")
+		}
+	}
+
+	target = &routeTarget{}
+
+	if target.out, err = NewWriter(outName, outOptionsConf); err != nil {
+		return
+	}
+
+	if target.formatter, err = NewFormatter(formatterName, formatterOptionsConf); err != nil {
+		return
+	}
+
+	if target.formatter, err = withFallbackFormatter(target.formatter, formatterConf); err != nil {
+		return
+	}
+
+	return
+}
+
+func (p *routeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *routeHook) Fire(entry *logrus.Entry) (err error) {
+	if isDropped(entry) {
+		return
+	}
+
+	v, exist := entry.Data[p.field]
+	if !exist {
+		return
+	}
+
+	target, exist := p.targets[fmt.Sprint(v)]
+	if !exist {
+		return
+	}
+
+	delete(entry.Data, p.field)
+
+	target.locker.Lock()
+	defer target.locker.Unlock()
+
+	serialized, err := target.formatter.Format(entry)
+	if err != nil {
+		return
+	}
+
+	if _, err = writeEntry(target.out, entry, serialized); err != nil {
+		return
+	}
+
+	if p.exclusive {
+		markDropped(entry)
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// routeTargetOut returns the buffer out of the route target of logger
+func routeTargetOut(t *testing.T, logger *logrus.Logger, name string) *BufferWriter {
+	t.Helper()
+
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if r, ok := hook.(*routeHook); ok {
+			return r.targets[name].out.(*BufferWriter)
+		}
+	}
+
+	t.Fatal("no route hook")
+	return nil
+}
+
+func TestRouteExclusive(t *testing.T) {
+	logger, buf := hijackString(t, `
+formatter.name = "json"
+route.targets.audit {
+    out.name = "buffer"
+    formatter.name = "json"
+}
+hooks.test-record.id = "route-exclusive"`)
+
+	audit := routeTargetOut(t, logger, "audit")
+
+	logger.WithField("_route", "audit").WithField("user", "bob").Info("user deleted")
+	logger.WithField("_route", "unknown").Info("unknown target")
+	logger.Info("plain")
+
+	auditOut := string(audit.Bytes())
+	if !strings.Contains(auditOut, `"msg":"user deleted"`) || !strings.Contains(auditOut, `"user":"bob"`) ||
+		strings.Contains(auditOut, "_route") || strings.Count(auditOut, "\n") != 1 {
+		t.Fatalf("audit %q", auditOut)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "user deleted") || !strings.Contains(out, "unknown target") || !strings.Contains(out, "plain") {
+		t.Fatalf("logger out %q", out)
+	}
+
+	for _, entry := range recordedBy(t, "route-exclusive").Entries() {
+		if entry.Message == "user deleted" {
+			t.Fatal("the exclusive routed entry fires the other hooks")
+		}
+	}
+}
+
+func TestRouteShared(t *testing.T) {
+	logger, buf := hijackString(t, `
+route {
+    exclusive = false
+    field = "sink"
+    targets.audit.out.name = "buffer"
+}`)
+
+	audit := routeTargetOut(t, logger, "audit")
+
+	logger.WithField("sink", "audit").Info("both")
+
+	if !strings.Contains(string(audit.Bytes()), "both") || !strings.Contains(buf.String(), "both") {
+		t.Fatalf("audit %q, logger out %q", audit.Bytes(), buf)
+	}
+	if strings.Contains(buf.String(), "sink=") {
+		t.Fatalf("the route field is not stripped: %q", buf)
+	}
+}
@@ -0,0 +1,111 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// max keys of sampler before the quiet ones are forgotten
+const maxSamplerKeys = 10000
+
+// sampler logs the first Burst entries of the same key fully, then 1 of SampleRate,
+// the counter of the key is reset after it is quiet for ResetAfter
+type sampler struct {
+	Burst      int
+	SampleRate int
+	ResetAfter time.Duration
+	KeyField   string
+
+	locker   sync.Mutex
+	counters map[string]*sampleCounter
+
+	// now is injectable for tests
+	now func() time.Time
+}
+
+type sampleCounter struct {
+	count int
+	last  time.Time
+}
+
+func newSampler(conf config.Configuration) (s *sampler, err error) {
+	s = &sampler{
+		Burst:      int(conf.GetInt32("burst", 10)),
+		SampleRate: int(conf.GetInt32("sample-rate", 100)),
+		ResetAfter: conf.GetTimeDuration("reset-after", time.Minute),
+		KeyField:   conf.GetString("key-field"),
+		counters:   make(map[string]*sampleCounter),
+		now:        time.Now,
+	}
+
+	if s.Burst < 0 || s.SampleRate <= 0 {
+		err = fmt.Errorf("logurs mate: sample burst should not be negative and sample-rate should be positive: %d, %d", s.Burst, s.SampleRate)
+		return
+	}
+
+	return
+}
+
+func (p *sampler) keep(entry *logrus.Entry) bool {
+	key := entry.Message
+	if len(p.KeyField) > 0 {
+		if v, exist := entry.Data[p.KeyField]; exist {
+			key = fmt.Sprint(v)
+		}
+	}
+
+	now := p.now()
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	c, exist := p.counters[key]
+	if !exist {
+		if len(p.counters) >= maxSamplerKeys {
+			p.forget(now)
+		}
+		c = &sampleCounter{}
+		p.counters[key] = c
+	} else if now.Sub(c.last) > p.ResetAfter {
+		c.count = 0
+	}
+
+	c.count++
+	c.last = now
+
+	if c.count <= p.Burst {
+		return true
+	}
+
+	return (c.count-p.Burst-1)%p.SampleRate == 0
+}
+
+func (p *sampler) forget(now time.Time) {
+	for k, c := range p.counters {
+		if now.Sub(c.last) > p.ResetAfter {
+			delete(p.counters, k)
+		}
+	}
+}
+
+// sampleHook is not the first hook, it follows the hooks enriching the entry,
+// since its key is the message or a field they may set. It decides before the
+// configured hooks fire, which skip the entries dropped.
+type sampleHook struct {
+	sampler *sampler
+}
+
+func (p *sampleHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *sampleHook) Fire(entry *logrus.Entry) error {
+	if !p.sampler.keep(entry) {
+		markDropped(entry)
+	}
+	return nil
+}
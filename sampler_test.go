@@ -0,0 +1,90 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSamplerPhases(t *testing.T) {
+	s, err := newSampler(configOf(`burst = 3, sample-rate = 4, reset-after = 1m`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	entry := &logrus.Entry{Message: "db down", Data: logrus.Fields{}}
+	keeps := func(n int) (kept []int) {
+		for i := 1; i <= n; i++ {
+			if s.keep(entry) {
+				kept = append(kept, i)
+			}
+			now = now.Add(time.Second)
+		}
+		return
+	}
+
+	// the burst 1-3 fully, then 1 of 4 from the 4th
+	if kept := keeps(12); !equalInts(kept, []int{1, 2, 3, 4, 8, 12}) {
+		t.Fatalf("burst and sample phases kept %v", kept)
+	}
+
+	// the other key has its own burst
+	if !s.keep(&logrus.Entry{Message: "cache miss", Data: logrus.Fields{}}) {
+		t.Fatal("the other key is sampled")
+	}
+
+	// the counter is reset after quiet
+	now = now.Add(2 * time.Minute)
+	if kept := keeps(5); !equalInts(kept, []int{1, 2, 3, 4}) {
+		t.Fatalf("reset phase kept %v", kept)
+	}
+}
+
+func TestSamplerKeyField(t *testing.T) {
+	s, err := newSampler(configOf(`burst = 1, sample-rate = 1000, key-field = "code"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the burst 1 and the first sampled, by the code regardless of message
+	kept := []bool{
+		s.keep(&logrus.Entry{Message: "a", Data: logrus.Fields{"code": 500}}),
+		s.keep(&logrus.Entry{Message: "b", Data: logrus.Fields{"code": 500}}),
+		s.keep(&logrus.Entry{Message: "c", Data: logrus.Fields{"code": 500}}),
+		s.keep(&logrus.Entry{Message: "a", Data: logrus.Fields{"code": 502}}),
+	}
+
+	if !kept[0] || !kept[1] || kept[2] || !kept[3] {
+		t.Fatalf("kept %v", kept)
+	}
+}
+
+func TestSampleHook(t *testing.T) {
+	logger, buf := hijackString(t, `sample { burst = 2, sample-rate = 1000 }`)
+
+	for i := 0; i < 5; i++ {
+		logger.Error("noisy")
+	}
+
+	// the burst 2 and the first sampled
+	if n := strings.Count(buf.String(), "noisy"); n != 3 {
+		t.Fatalf("%d written, expected 3", n)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package logrus_mate
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetFormatterSwitch(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer", formatter.name = "text" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := mate.Logger("mike")
+
+	logger.Info("as text")
+	buf, _ := mate.Buffer("mike")
+	if s := buf.String(); !strings.Contains(s, `msg="as text"`) {
+		t.Fatalf("text %q", s)
+	}
+
+	if err = mate.SetFormatter("mike", "json", map[string]interface{}{"timestamp_format": "2006"}); err != nil {
+		t.Fatal(err)
+	}
+	mate.ResetBuffer("mike")
+	logger.Info("as json")
+	buf, _ = mate.Buffer("mike")
+	if s := buf.String(); !strings.HasPrefix(s, "{") || !strings.Contains(s, `"msg":"as json"`) {
+		t.Fatalf("json %q", s)
+	}
+
+	if err = mate.SetFormatter("mike", "text", map[string]interface{}{"disable-timestamp": true}); err != nil {
+		t.Fatal(err)
+	}
+	mate.ResetBuffer("mike")
+	logger.Info("back to text")
+	buf, _ = mate.Buffer("mike")
+	if s := buf.String(); s != "level=info msg=\"back to text\"\n" {
+		t.Fatalf("text %q", s)
+	}
+}
+
+type unmarshalable struct{}
+
+func (unmarshalable) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("unmarshalable")
+}
+
+func TestSetFormatterKeepsFallback(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+		mike {
+			out.name = "buffer"
+			formatter { name = "text", fallback { name = "text", options.disable-timestamp = true } }
+		}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mate.SetFormatter("mike", "json", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// json fails on the field, the fallback of config formats the entry
+	mate.Logger("mike").WithField("value", unmarshalable{}).Info("unmarshalable")
+
+	buf, _ := mate.Buffer("mike")
+	if s := buf.String(); !strings.Contains(s, `msg=unmarshalable`) || !strings.Contains(s, formatterErrorKey+"=") {
+		t.Fatalf("the fallback is lost: %q", s)
+	}
+}
+
+func TestSetFormatterRejectsFanout(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { fanout { console.out.name = "buffer" } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := mate.Logger("mike")
+	formatter := logger.Formatter
+
+	if err = mate.SetFormatter("mike", "json", nil); err == nil || !strings.Contains(err.Error(), "fanout") {
+		t.Fatalf("expected fanout error: %v", err)
+	}
+	if logger.Formatter != formatter {
+		t.Fatal("the fanout formatter is replaced")
+	}
+}
+
+func TestSetFormatterErrors(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mate.SetFormatter("mike", "jsom", nil); err == nil || !strings.Contains(err.Error(), "jsom") {
+		t.Fatalf("expected unknown formatter error: %v", err)
+	}
+
+	if err = mate.SetFormatter("missing", "json", nil); err != ErrLoggerNotExist {
+		t.Fatalf("expected ErrLoggerNotExist: %v", err)
+	}
+}
+
+func TestSetFormatterWhileLogging(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := mate.Logger("mike")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("busy")
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		name := []string{"json", "text"}[i%2]
+		if err = mate.SetFormatter("mike", name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,56 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// signalFlushTimeout bounds the flush on signal, so the stop of container is not delayed forever
+const signalFlushTimeout = 5 * time.Second
+
+// InstallSignalFlush flushes and closes the hooks by CloseWithTimeout when one of
+// the sigs (SIGTERM and SIGINT usually) arrives, then re-raises it with the default
+// action restored, so the program exits as if not installed.
+//
+// The handlers installed by the application with signal.Notify are kept and still
+// receive the signal, but they receive it again by the re-raise. The application
+// having its own graceful shutdown should call CloseWithTimeout itself instead.
+// Fatal does not go through the signals, use logrus.RegisterExitHandler
+// to close the hooks before logger.ExitFunc.
+//
+// uninstall stops watching the sigs, it is safe to call more than once.
+func (p *LogrusMate) InstallSignalFlush(sigs ...os.Signal) (uninstall func()) {
+	c := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+
+	signal.Notify(c, sigs...)
+
+	var once sync.Once
+	uninstall = func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(stop)
+		})
+	}
+
+	go func() {
+		select {
+		case sig := <-c:
+			if err := p.CloseWithTimeout(signalFlushTimeout); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "logurs mate: flush on signal %s: %s\n", sig, err)
+			}
+
+			uninstall()
+
+			if proc, err := os.FindProcess(os.Getpid()); err == nil {
+				_ = proc.Signal(sig)
+			}
+		case <-stop:
+		}
+	}()
+
+	return
+}
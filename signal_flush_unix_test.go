@@ -0,0 +1,87 @@
+//go:build linux || darwin || freebsd || dragonfly
+// +build linux darwin freebsd dragonfly
+
+package logrus_mate
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalFlush(t *testing.T) {
+	// the handler of application keeps the re-raised signal from killing the test
+	app := make(chan os.Signal, 2)
+	signal.Notify(app, syscall.SIGUSR1)
+	defer signal.Stop(app)
+
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := &closingHook{closed: make(chan struct{})}
+	if err = mate.AddHook("mike", hook); err != nil {
+		t.Fatal(err)
+	}
+
+	uninstall := mate.InstallSignalFlush(syscall.SIGUSR1)
+	defer uninstall()
+
+	if err = syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-hook.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the hook is not flushed on signal")
+	}
+
+	// the application receives the signal and the re-raised one
+	for i := 0; i < 2; i++ {
+		select {
+		case <-app:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the application received %d signals, expected 2", i)
+		}
+	}
+}
+
+func TestSignalFlushUninstall(t *testing.T) {
+	app := make(chan os.Signal, 1)
+	signal.Notify(app, syscall.SIGUSR2)
+	defer signal.Stop(app)
+
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "buffer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := &closingHook{closed: make(chan struct{})}
+	if err = mate.AddHook("mike", hook); err != nil {
+		t.Fatal(err)
+	}
+
+	uninstall := mate.InstallSignalFlush(syscall.SIGUSR2)
+	uninstall()
+	uninstall()
+
+	if err = syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-app:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the application receives no signal")
+	}
+
+	select {
+	case <-hook.closed:
+		t.Fatal("the hook is flushed after uninstall")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,164 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Snapshot is the effective state of the loggers of a mate, the configs by
+// name and the level, out, formatter and hooks of the created loggers, including
+// the changes at runtime like SetFormatter, AddHook or the level handler
+type Snapshot struct {
+	configs map[string]config.Configuration
+	states  map[string]*loggerState
+}
+
+type loggerState struct {
+	level        logrus.Level
+	out          io.Writer
+	formatter    logrus.Formatter
+	hooks        logrus.LevelHooks
+	reportCaller bool
+}
+
+// Snapshot captures the state of the loggers, Apply restores it
+func (p *LogrusMate) Snapshot() *Snapshot {
+	s := &Snapshot{
+		configs: make(map[string]config.Configuration),
+		states:  make(map[string]*loggerState),
+	}
+
+	p.loggersConf.Range(func(k, v interface{}) bool {
+		s.configs[k.(string)] = v.(config.Configuration)
+		return true
+	})
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		l := v.(*logrus.Logger)
+		s.states[k.(string)] = &loggerState{
+			level:        l.GetLevel(),
+			out:          l.Out,
+			formatter:    l.Formatter,
+			hooks:        copyLevelHooks(l.Hooks),
+			reportCaller: l.ReportCaller,
+		}
+		return true
+	})
+
+	return s
+}
+
+// Loggers returns the logger names of the snapshot
+func (s *Snapshot) Loggers() []string {
+	var names []string
+	for name := range s.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config returns the logger config of the snapshot
+func (s *Snapshot) Config(loggerName string) config.Configuration {
+	return s.configs[loggerNameOrDefault(loggerName)]
+}
+
+// WithConfig returns a copy of the snapshot with the logger config replaced,
+// or added, Apply hijacks the created logger by the config instead of restoring its state
+func (s *Snapshot) WithConfig(loggerName string, conf config.Configuration) *Snapshot {
+	name := loggerNameOrDefault(loggerName)
+
+	c := &Snapshot{
+		configs: make(map[string]config.Configuration, len(s.configs)+1),
+		states:  make(map[string]*loggerState, len(s.states)),
+	}
+
+	for k, v := range s.configs {
+		c.configs[k] = v
+	}
+	for k, v := range s.states {
+		c.states[k] = v
+	}
+
+	c.configs[name] = conf
+	delete(c.states, name)
+
+	return c
+}
+
+// Apply restores the snapshot all or nothing: the loggers of the replaced configs are
+// built first, any error returns with nothing changed. Then the configs are replaced,
+// the created loggers are restored to their states or hijacked by the replaced configs,
+// and the loggers absent from the snapshot are forgotten by the mate.
+func (p *LogrusMate) Apply(s *Snapshot) (err error) {
+	p.mergeLocker.Lock()
+	defer p.mergeLocker.Unlock()
+
+	commits := make(map[string]func())
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		name := k.(string)
+		conf, exist := s.configs[name]
+		if _, restore := s.states[name]; !exist || restore {
+			return true
+		}
+
+		var commit func()
+		if commit, err = prepareHijack(v.(*logrus.Logger), conf); err != nil {
+			err = fmt.Errorf("logurs mate: apply snapshot of logger %s: %s", name, err)
+			return false
+		}
+		commits[name] = commit
+		return true
+	})
+
+	if err != nil {
+		return
+	}
+
+	p.loggersConf.Range(func(k, v interface{}) bool {
+		if _, exist := s.configs[k.(string)]; !exist {
+			p.loggersConf.Delete(k)
+		}
+		return true
+	})
+
+	for name, conf := range s.configs {
+		p.loggersConf.Store(name, conf)
+	}
+
+	p.loggers.Range(func(k, v interface{}) bool {
+		name, l := k.(string), v.(*logrus.Logger)
+
+		if _, exist := s.configs[name]; !exist {
+			p.loggers.Delete(k)
+			return true
+		}
+
+		if state, exist := s.states[name]; exist {
+			l.SetLevel(state.level)
+			l.SetOutput(state.out)
+			l.SetFormatter(state.formatter)
+			closeHeartbeats(l.ReplaceHooks(copyLevelHooks(state.hooks)), state.hooks)
+			l.SetReportCaller(state.reportCaller)
+		} else if commit, exist := commits[name]; exist {
+			commit()
+		}
+
+		return true
+	})
+
+	return
+}
+
+func copyLevelHooks(hooks logrus.LevelHooks) logrus.LevelHooks {
+	c := make(logrus.LevelHooks, len(hooks))
+	for level, levelHooks := range hooks {
+		c[level] = append([]logrus.Hook(nil), levelHooks...)
+	}
+	return c
+}
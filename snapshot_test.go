@@ -0,0 +1,119 @@
+package logrus_mate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+mike { level = "info", out.name = "buffer" }
+jack { level = "warn", out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := mate.Logger("mike")
+
+	snapshot := mate.Snapshot()
+	if names := snapshot.Loggers(); !reflect.DeepEqual(names, []string{"jack", "mike"}) {
+		t.Fatalf("loggers %v", names)
+	}
+
+	// the runtime changes after the snapshot
+	added := &recordHook{}
+	if err = mate.AddHook("mike", added); err != nil {
+		t.Fatal(err)
+	}
+	if err = mate.SetFormatter("mike", "json", nil); err != nil {
+		t.Fatal(err)
+	}
+	logger.SetLevel(logrus.DebugLevel)
+	mate.Logger("jack")
+
+	if err = mate.Apply(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	// the logger is restored in place
+	if mate.Logger("mike") != logger || logger.GetLevel() != logrus.InfoLevel {
+		t.Fatalf("logger level %s", logger.GetLevel())
+	}
+	if _, json := logger.Formatter.(*logrus.JSONFormatter); json {
+		t.Fatal("the formatter is not restored")
+	}
+
+	mate.ResetBuffer("mike")
+	logger.Debug("below level")
+	logger.Info("restored")
+	if entries := added.Entries(); len(entries) != 0 {
+		t.Fatalf("the hook added after the snapshot fires %v", entries)
+	}
+	if buf, _ := mate.Buffer("mike"); buf.String() == "" || strings.Contains(buf.String(), "below level") || strings.HasPrefix(buf.String(), "{") {
+		t.Fatalf("output %q", buf.String())
+	}
+
+	// a snapshot of the restored state equals the taken one
+	again := mate.Snapshot()
+	if !reflect.DeepEqual(again.Loggers(), snapshot.Loggers()) || again.states["mike"].level != snapshot.states["mike"].level {
+		t.Fatalf("snapshot after apply %+v", again)
+	}
+}
+
+func TestSnapshotApplyModified(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { level = "info", out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := mate.Logger("mike")
+	snapshot := mate.Snapshot()
+
+	tried := snapshot.WithConfig("mike", configOf(`level = "error", out.name = "nil"`)).
+		WithConfig("jack", configOf(`level = "debug", out.name = "nil"`))
+	if err = mate.Apply(tried); err != nil {
+		t.Fatal(err)
+	}
+
+	// the created logger is hijacked in place by the replaced config, the new one is created by its config
+	if mate.Logger("mike") != logger || logger.GetLevel() != logrus.ErrorLevel {
+		t.Fatalf("mike level %s", logger.GetLevel())
+	}
+	if jack := mate.Logger("jack"); jack == nil || jack.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("jack %v", jack)
+	}
+
+	// revert, jack is absent from the snapshot and forgotten
+	if err = mate.Apply(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if logger.GetLevel() != logrus.InfoLevel || mate.Logger("jack") != nil {
+		t.Fatalf("mike level %s after revert", logger.GetLevel())
+	}
+}
+
+func TestSnapshotApplyAllOrNothing(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+mike { level = "info", out.name = "nil" }
+jack { level = "warn", out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mike, jack := mate.Logger("mike"), mate.Logger("jack")
+	snapshot := mate.Snapshot()
+
+	bad := snapshot.WithConfig("mike", configOf(`level = "debug", out.name = "nil"`)).
+		WithConfig("jack", configOf(`level = "verbose", out.name = "nil"`))
+	if err = mate.Apply(bad); err == nil {
+		t.Fatal("the invalid snapshot is applied")
+	}
+
+	// nothing changed, neither the valid mike
+	if mike.GetLevel() != logrus.InfoLevel || jack.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("levels %s %s", mike.GetLevel(), jack.GetLevel())
+	}
+	if level := mate.Snapshot().Config("mike").GetString("level"); level != "info" {
+		t.Fatalf("config of mike %s", level)
+	}
+}
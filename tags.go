@@ -0,0 +1,69 @@
+package logrus_mate
+
+import (
+	"fmt"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// tagsHook attaches the tags of logger to every entry as a list field, the tags
+// given by the call in the same field are merged after them, the duplicates removed
+type tagsHook struct {
+	field string
+	tags  []string
+}
+
+// newTagsHook parses tags = ["prod", "billing"] and tags-field = "tags"
+func newTagsHook(conf config.Configuration) (hook *tagsHook, err error) {
+	hook = &tagsHook{
+		field: conf.GetString("tags-field", "tags"),
+		tags:  conf.GetStringList("tags"),
+	}
+
+	if len(hook.field) == 0 {
+		err = fmt.Errorf("logurs mate: tags-field is empty")
+		return
+	}
+
+	return
+}
+
+func (p *tagsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *tagsHook) Fire(entry *logrus.Entry) error {
+	// a copy per entry, the later hooks may change it
+	tags := make([]string, 0, len(p.tags))
+	tags = append(tags, p.tags...)
+
+	switch v := entry.Data[p.field].(type) {
+	case nil:
+	case string:
+		tags = appendTag(tags, v)
+	case []string:
+		for _, tag := range v {
+			tags = appendTag(tags, tag)
+		}
+	case []interface{}:
+		for _, tag := range v {
+			tags = appendTag(tags, fmt.Sprint(tag))
+		}
+	default:
+		tags = appendTag(tags, fmt.Sprint(v))
+	}
+
+	entry.Data[p.field] = tags
+
+	return nil
+}
+
+func appendTag(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
@@ -0,0 +1,100 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jsonLine decodes the single json line of buf
+func jsonLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &m); err != nil {
+		t.Fatalf("decode %q: %s", buf.String(), err)
+	}
+	return m
+}
+
+func TestTagsHijack(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+formatter.name = "json"
+tags = ["prod", "billing", "pci"]`)
+
+	logger.WithField("order", 42).Info("paid")
+
+	m := jsonLine(t, buf)
+	if tags := m["tags"]; !reflect.DeepEqual(tags, []interface{}{"prod", "billing", "pci"}) {
+		t.Fatalf("tags %#v", tags)
+	}
+	// the per-call fields coexist with the tags
+	if m["order"] != float64(42) || m["msg"] != "paid" {
+		t.Fatalf("line %v", m)
+	}
+}
+
+func TestTagsNamedLogger(t *testing.T) {
+	mate := newTestMate(t, `mike { level = "info", formatter.name = "json", tags = ["prod"], tags-field = "labels" }`)
+
+	logger := mate.Logger("mike")
+	buf := &bytes.Buffer{}
+	logger.Out = buf
+
+	logger.WithField("labels", []string{"refund", "prod"}).Info("refunded")
+
+	// merged after the logger tags without the duplicates
+	if tags := jsonLine(t, buf)["labels"]; !reflect.DeepEqual(tags, []interface{}{"prod", "refund"}) {
+		t.Fatalf("labels %#v", tags)
+	}
+}
+
+func TestTagsMerge(t *testing.T) {
+	hook, err := newTagsHook(configOf(`tags = ["a", "b"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		value    interface{}
+		expected []string
+	}{
+		{nil, []string{"a", "b"}},
+		{"c", []string{"a", "b", "c"}},
+		{"a", []string{"a", "b"}},
+		{[]string{"b", "c", "c"}, []string{"a", "b", "c"}},
+		{[]interface{}{"c", 1}, []string{"a", "b", "c", "1"}},
+		{7, []string{"a", "b", "7"}},
+	} {
+		entry := &logrus.Entry{Data: logrus.Fields{}}
+		if c.value != nil {
+			entry.Data["tags"] = c.value
+		}
+		if err = hook.Fire(entry); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(entry.Data["tags"], c.expected) {
+			t.Fatalf("%#v: tags %#v, expected %#v", c.value, entry.Data["tags"], c.expected)
+		}
+	}
+
+	// every entry gets its copy
+	entry := &logrus.Entry{Data: logrus.Fields{}}
+	_ = hook.Fire(entry)
+	entry.Data["tags"].([]string)[0] = "changed"
+	if hook.tags[0] != "a" {
+		t.Fatalf("the logger tags changed: %v", hook.tags)
+	}
+}
+
+func TestTagsInvalid(t *testing.T) {
+	conf := `tags = ["a"], tags-field = ""`
+	if err := Hijack(logrus.New(), ConfigString(conf)); err == nil || !strings.Contains(err.Error(), "tags-field") {
+		t.Fatalf("%s: %v", conf, err)
+	}
+}
@@ -0,0 +1,69 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.\-]*)\}`)
+
+// templateHook renders the message template like "user {user_id} did {action}"
+// by the fields of entry, the fields are kept for the structured output.
+// With marker, only the messages having the marker prefix are templates and the
+// marker is stripped. The missing field is rendered as {field?} by missing = "mark",
+// or left as {field} by missing = "literal".
+type templateHook struct {
+	marker  string
+	literal bool
+}
+
+func newTemplateHook(conf config.Configuration) (hook *templateHook, err error) {
+	hook = &templateHook{marker: conf.GetString("marker")}
+
+	switch missing := conf.GetString("missing", "mark"); missing {
+	case "mark":
+	case "literal":
+		hook.literal = true
+	default:
+		err = fmt.Errorf("logurs mate: template missing should be mark or literal, but got %s", missing)
+		return
+	}
+
+	return
+}
+
+func (p *templateHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *templateHook) Fire(entry *logrus.Entry) error {
+	message := entry.Message
+
+	if len(p.marker) > 0 {
+		if !strings.HasPrefix(message, p.marker) {
+			return nil
+		}
+		message = message[len(p.marker):]
+	}
+
+	if strings.IndexByte(message, '{') >= 0 {
+		message = templatePlaceholder.ReplaceAllStringFunc(message, func(placeholder string) string {
+			name := placeholder[1 : len(placeholder)-1]
+			if v, exist := entry.Data[name]; exist {
+				return fmt.Sprint(v)
+			}
+			if p.literal {
+				return placeholder
+			}
+			return "{" + name + "?}"
+		})
+	}
+
+	entry.Message = message
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package logrus_mate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateSubstitution(t *testing.T) {
+	logger, buf := hijackString(t, `
+level = "info"
+template {}
+hooks.test-record.id = "template"
+formatter.name = "json"`)
+
+	logger.WithField("user_id", 42).WithField("action", "login").Info("user {user_id} did {action}")
+
+	s := buf.String()
+	if !strings.Contains(s, `"msg":"user 42 did login"`) {
+		t.Fatalf("output %q", s)
+	}
+	// the fields are kept for the structured output
+	if !strings.Contains(s, `"user_id":42`) || !strings.Contains(s, `"action":"login"`) {
+		t.Fatalf("the fields are lost %q", s)
+	}
+
+	entries := recordedBy(t, "template").Entries()
+	if len(entries) != 1 || entries[0].Message != "user 42 did login" {
+		t.Fatalf("the hooks see %v", entries)
+	}
+}
+
+func TestTemplateMissingField(t *testing.T) {
+	for _, c := range []struct {
+		missing  string
+		expected string
+	}{
+		{"", "user 42 did {action?}"},
+		{"mark", "user 42 did {action?}"},
+		{"literal", "user 42 did {action}"},
+	} {
+		conf := `level = "info", hooks.test-record.id = "template-missing", template {}`
+		if c.missing != "" {
+			conf = `level = "info", hooks.test-record.id = "template-missing", template.missing = "` + c.missing + `"`
+		}
+
+		logger, _ := hijackString(t, conf)
+		logger.WithField("user_id", 42).Info("user {user_id} did {action}")
+
+		entries := recordedBy(t, "template-missing").Entries()
+		if len(entries) != 1 || entries[0].Message != c.expected {
+			t.Fatalf("missing %q: the hooks see %v, expected %q", c.missing, entries, c.expected)
+		}
+	}
+}
+
+func TestTemplateMarker(t *testing.T) {
+	logger, _ := hijackString(t, `
+level = "info"
+template.marker = "tpl:"
+hooks.test-record.id = "template-marker"`)
+
+	logger.WithField("user_id", 42).Info("tpl:user {user_id} logged in")
+	logger.WithField("user_id", 42).Info("json {user_id} kept as is")
+
+	entries := recordedBy(t, "template-marker").Entries()
+	if len(entries) != 2 || entries[0].Message != "user 42 logged in" || entries[1].Message != "json {user_id} kept as is" {
+		t.Fatalf("the hooks see %v", entries)
+	}
+}
+
+func TestTemplateInvalidMissing(t *testing.T) {
+	if _, err := newTemplateHook(configOf(`missing = "drop"`)); err == nil {
+		t.Fatal("the invalid missing is accepted")
+	}
+}
@@ -0,0 +1,51 @@
+package logrus_mate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Timer starts timing the op, the returned stop logs the elapsed time through the
+// named logger with the fields op and duration_ms, and the extra fields given to stop.
+// The level is timer-level of the logger, info by default. Only the first call of
+// stop logs, the later calls do nothing.
+//
+//	defer mate.Timer("mike", "load-users")()
+func (p *LogrusMate) Timer(name, op string) func(fields ...logrus.Fields) {
+	start := time.Now()
+
+	level := logrus.InfoLevel
+	if confV, exist := p.loggersConf.Load(loggerNameOrDefault(name)); exist {
+		if timerLevel := confV.(config.Configuration).GetString("timer-level"); len(timerLevel) > 0 {
+			if lvl, err := ParseLevel(timerLevel); err == nil {
+				level = lvl
+			}
+		}
+	}
+
+	var once sync.Once
+
+	return func(fields ...logrus.Fields) {
+		once.Do(func() {
+			elapsed := time.Since(start)
+
+			logger := p.Logger(name)
+			if logger == nil {
+				return
+			}
+
+			entry := logger.WithFields(logrus.Fields{
+				"op":          op,
+				"duration_ms": float64(elapsed) / float64(time.Millisecond),
+			})
+			for _, f := range fields {
+				entry = entry.WithFields(f)
+			}
+
+			entry.Log(level, "timer")
+		})
+	}
+}
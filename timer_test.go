@@ -0,0 +1,73 @@
+package logrus_mate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestTimerDuration(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { level = "debug", out.name = "nil", hooks.test-record.id = "timer" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := mate.Timer("mike", "load-users")
+	start := time.Now()
+	time.Sleep(50 * time.Millisecond)
+	stop(logrus.Fields{"rows": 10})
+	elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+
+	entries := recordedBy(t, "timer").Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries %v", entries)
+	}
+
+	e := entries[0]
+	if e.Message != "timer" || e.Level != logrus.InfoLevel || e.Data["op"] != "load-users" || e.Data["rows"] != 10 {
+		t.Fatalf("entry %v", e)
+	}
+
+	// approximately the elapsed time, measured inside the bounds of the caller
+	duration, ok := e.Data["duration_ms"].(float64)
+	if !ok || duration < 50 || duration > elapsed {
+		t.Fatalf("duration_ms %v, slept 50ms, elapsed %.3fms", e.Data["duration_ms"], elapsed)
+	}
+}
+
+func TestTimerOnce(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { level = "debug", out.name = "nil", timer-level = "debug", hooks.test-record.id = "timer-once" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := mate.Timer("mike", "op")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stop()
+		}()
+	}
+	wg.Wait()
+	stop()
+
+	entries := recordedBy(t, "timer-once").Entries()
+	if len(entries) != 1 || entries[0].Level != logrus.DebugLevel {
+		t.Fatalf("entries %v", entries)
+	}
+}
+
+func TestTimerUnknownLogger(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`mike { out.name = "nil" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing to log into, it must not panic
+	mate.Timer("missing", "op")()
+}
@@ -0,0 +1,84 @@
+package logrus_mate
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	transformsLocker = sync.Mutex{}
+	transformFuncs   = make(map[string]TransformFunc)
+)
+
+// TransformFunc changes the entry before it is formatted, e.g. normalizes the fields
+type TransformFunc func(*logrus.Entry) error
+
+func RegisterTransform(name string, transformFunc TransformFunc) {
+	transformsLocker.Lock()
+	defer transformsLocker.Unlock()
+
+	if name == "" {
+		panic("logurs mate: Register transform name is empty")
+	}
+
+	if transformFunc == nil {
+		panic("logurs mate: Register transform is nil")
+	}
+
+	if _, exist := transformFuncs[name]; exist {
+		panic("logurs mate: Register called twice for transform " + name)
+	}
+
+	transformFuncs[name] = transformFunc
+}
+
+func Transforms() []string {
+	transformsLocker.Lock()
+	defer transformsLocker.Unlock()
+	var list []string
+	for name := range transformFuncs {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// transformHook runs the transforms in order before the other hooks and the
+// formatter, the pipeline stops at the first error
+type transformHook struct {
+	transforms []TransformFunc
+}
+
+func newTransformHook(names []string) (hook *transformHook, err error) {
+	transformsLocker.Lock()
+	defer transformsLocker.Unlock()
+
+	hook = &transformHook{}
+
+	for _, name := range names {
+		transformFunc, exist := transformFuncs[name]
+		if !exist {
+			err = errors.New("logurs mate: transform not registerd: " + name)
+			return
+		}
+		hook.transforms = append(hook.transforms, transformFunc)
+	}
+
+	return
+}
+
+func (p *transformHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *transformHook) Fire(entry *logrus.Entry) error {
+	for _, transform := range p.transforms {
+		if err := transform(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package logrus_mate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+var errTestTransform = errors.New("test transform failed")
+
+func init() {
+	// the legacy key is renamed first, so the second stage sees the new one
+	RegisterTransform("test-rename-uid", func(entry *logrus.Entry) error {
+		if v, exist := entry.Data["uid"]; exist {
+			delete(entry.Data, "uid")
+			entry.Data["user_id"] = v
+		}
+		return nil
+	})
+
+	RegisterTransform("test-tag-user", func(entry *logrus.Entry) error {
+		if _, exist := entry.Data["user_id"]; exist {
+			entry.Data["has_user"] = true
+		}
+		return nil
+	})
+
+	RegisterTransform("test-fail", func(entry *logrus.Entry) error {
+		return errTestTransform
+	})
+}
+
+func TestTransformPipeline(t *testing.T) {
+	logger, _ := hijackString(t, `
+transforms = ["test-rename-uid", "test-tag-user"]
+hooks.test-record.id = "transforms"`)
+
+	logger.WithField("uid", 42).Info("legacy")
+
+	entries := recordedBy(t, "transforms").Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries %v", entries)
+	}
+
+	data := entries[0].Data
+	if _, exist := data["uid"]; exist || data["user_id"] != 42 || data["has_user"] != true {
+		t.Fatalf("the hooks see %v", data)
+	}
+}
+
+func TestTransformOrder(t *testing.T) {
+	// the second stage runs before the key is renamed, it sees no user_id
+	logger, _ := hijackString(t, `
+transforms = ["test-tag-user", "test-rename-uid"]
+hooks.test-record.id = "transforms-reversed"`)
+
+	logger.WithField("uid", 42).Info("legacy")
+
+	data := recordedBy(t, "transforms-reversed").Entries()[0].Data
+	if _, exist := data["has_user"]; exist || data["user_id"] != 42 {
+		t.Fatalf("the hooks see %v", data)
+	}
+}
+
+func TestTransformStopsAtError(t *testing.T) {
+	hook, err := newTransformHook([]string{"test-rename-uid", "test-fail", "test-tag-user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := logrus.NewEntry(logrus.New()).WithField("uid", 1)
+	if err = hook.Fire(entry); err != errTestTransform {
+		t.Fatalf("expected the error of transform: %v", err)
+	}
+
+	if _, exist := entry.Data["has_user"]; exist || entry.Data["user_id"] != 1 {
+		t.Fatalf("the stages after the error should not run: %v", entry.Data)
+	}
+}
+
+func TestTransformNotRegistered(t *testing.T) {
+	if err := Hijack(logrus.New(), ConfigString(`transforms = ["test-rename-uid", "test-missing"]`)); err == nil || !strings.Contains(err.Error(), "test-missing") {
+		t.Fatalf("expected error of the missing transform: %v", err)
+	}
+}
+
+func TestTransformsListed(t *testing.T) {
+	names := strings.Join(Transforms(), ",")
+	if !strings.Contains(names, "test-fail,test-rename-uid,test-tag-user") {
+		t.Fatalf("transforms %s", names)
+	}
+}
+
+func TestRegisterTransformPanics(t *testing.T) {
+	for name, register := range map[string]func(){
+		"empty name": func() { RegisterTransform("", func(*logrus.Entry) error { return nil }) },
+		"nil":        func() { RegisterTransform("test-nil", nil) },
+		"twice":      func() { RegisterTransform("test-fail", func(*logrus.Entry) error { return nil }) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+func TestTransformsSchema(t *testing.T) {
+	if errs := validateConfig(configOf(`mike.transforms = ["test-rename-uid", "test-tag-user"]`)); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+}
@@ -0,0 +1,43 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/gogap/config"
+)
+
+func init() {
+	RegisterWriter("buffer", NewBufferWriter)
+}
+
+// BufferWriter keeps the formatted output in memory, it is safe for concurrent use
+type BufferWriter struct {
+	locker sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *BufferWriter) Write(p []byte) (n int, err error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	return w.buf.Write(p)
+}
+
+// Bytes returns a copy of the buffered output
+func (w *BufferWriter) Bytes() []byte {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	return append([]byte(nil), w.buf.Bytes()...)
+}
+
+func (w *BufferWriter) Reset() {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	w.buf.Reset()
+}
+
+func NewBufferWriter(conf config.Configuration) (writer io.Writer, err error) {
+	writer = new(BufferWriter)
+	return
+}
@@ -0,0 +1,70 @@
+package logrus_mate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBufferByLoggerName(t *testing.T) {
+	mate, err := NewLogrusMate(ConfigString(`
+captured {
+    out.name = "buffer"
+    formatter.name = "json"
+}
+console {
+    out.name = "stdout"
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mate.Logger("captured").Info("kept")
+
+	buf, exist := mate.Buffer("captured")
+	if !exist || !strings.Contains(buf.String(), `"msg":"kept"`) {
+		t.Fatalf("buffer %v %q", exist, buf)
+	}
+
+	// the snapshot is not the output
+	buf.Reset()
+	if buf, _ = mate.Buffer("captured"); buf.Len() == 0 {
+		t.Fatal("reset of the snapshot discards the output")
+	}
+
+	if !mate.ResetBuffer("captured") {
+		t.Fatal("the buffer logger is not reset")
+	}
+	if buf, _ = mate.Buffer("captured"); buf.Len() != 0 {
+		t.Fatalf("the output is kept after reset: %q", buf)
+	}
+
+	if _, exist = mate.Buffer("console"); exist {
+		t.Fatal("the stdout logger has the buffer")
+	}
+	if _, exist = mate.Buffer("missing"); exist {
+		t.Fatal("the missing logger has the buffer")
+	}
+}
+
+func TestBufferWriterConcurrent(t *testing.T) {
+	w := &BufferWriter{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = fmt.Fprintf(w, "%d-%d\n", i, j)
+				_ = w.Bytes()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if lines := strings.Count(string(w.Bytes()), "\n"); lines != 800 {
+		t.Fatalf("%d lines, expected 800", lines)
+	}
+}
@@ -0,0 +1,44 @@
+package logrus_mate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gogap/config"
+)
+
+func init() {
+	RegisterWriter("fd", NewFdWriter)
+}
+
+// NewFdWriter writes to the pre-opened descriptor passed by the supervisor, e.g. fd = 3,
+// it is checked writable by a zero length write
+func NewFdWriter(conf config.Configuration) (writer io.Writer, err error) {
+	if conf == nil {
+		err = errors.New("logurs mate: fd writer's fd is not set")
+		return
+	}
+
+	fd := int(conf.GetInt32("fd", 0))
+	if fd <= 0 {
+		err = fmt.Errorf("logurs mate: fd writer's fd should be positive: %d", fd)
+		return
+	}
+
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		err = fmt.Errorf("logurs mate: invalid fd %d", fd)
+		return
+	}
+
+	if _, err = f.Write(nil); err != nil {
+		err = fmt.Errorf("logurs mate: fd %d is not writable: %s", fd, err)
+		return
+	}
+
+	writer = f
+
+	return
+}
@@ -0,0 +1,77 @@
+//go:build linux || darwin || freebsd || dragonfly
+// +build linux darwin freebsd dragonfly
+
+package logrus_mate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFdWriterPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	_ = r.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// the writer owns a dup, so the pipe is closed once by each
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	if err = Hijack(logger, ConfigString(fmt.Sprintf(`out { name = "fd", options.fd = %d }, formatter.name = "json"`, fd))); err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Out.(*os.File).Close()
+
+	logger.Info("to the supervisor")
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line, `"msg":"to the supervisor"`) {
+		t.Fatalf("line %q", line)
+	}
+}
+
+func TestFdWriterInvalid(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	fd, err := syscall.Dup(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the dup is owned by the file of the failed writer, closed when collected
+
+	for _, conf := range []string{
+		fmt.Sprintf("fd = %d", fd),
+		"fd = 0",
+		"fd = -1",
+	} {
+		if _, err = NewFdWriter(configOf(conf)); err == nil {
+			t.Errorf("%s: expected error", conf)
+		}
+	}
+
+	if _, err = NewFdWriter(nil); err == nil {
+		t.Error("expected error without config")
+	}
+}
@@ -0,0 +1,89 @@
+package logrus_mate
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gogap/config"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterWriter("split", NewSplitWriter)
+}
+
+// SplitWriter writes the entries at or above Level (warn by default) to Stderr,
+// and the others to Stdout. The level comes along with each entry by WriteLevel,
+// the logger with the split out writes by a hook knowing the entry, so the
+// writer works with any formatter.
+type SplitWriter struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	Level  logrus.Level
+
+	locker sync.Mutex
+}
+
+// Write writes p of unknown level to Stdout
+func (w *SplitWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(logrus.InfoLevel, p)
+}
+
+// WriteLevel writes p of the entry level to Stderr or Stdout
+func (w *SplitWriter) WriteLevel(level logrus.Level, p []byte) (n int, err error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	if level <= w.Level {
+		return w.Stderr.Write(p)
+	}
+	return w.Stdout.Write(p)
+}
+
+func NewSplitWriter(conf config.Configuration) (writer io.Writer, err error) {
+	w := &SplitWriter{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Level:  logrus.WarnLevel,
+	}
+
+	if conf != nil {
+		if w.Level, err = ParseLevel(conf.GetString("split-level", "warn")); err != nil {
+			return
+		}
+	}
+
+	writer = w
+
+	return
+}
+
+// writeEntry writes the serialized entry to out, SplitWriter gets the entry level along
+func writeEntry(out io.Writer, entry *logrus.Entry, serialized []byte) (n int, err error) {
+	if w, ok := out.(*SplitWriter); ok {
+		return w.WriteLevel(entry.Level, serialized)
+	}
+	return out.Write(serialized)
+}
+
+// splitHook writes the entries of the logger with the split out, formatted by
+// the current formatter of logger. It is the last hook, the out of logger writes nothing.
+type splitHook struct {
+	writer *SplitWriter
+}
+
+func (p *splitHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (p *splitHook) Fire(entry *logrus.Entry) (err error) {
+	serialized, err := entry.Bytes()
+	if err != nil || len(serialized) == 0 {
+		return
+	}
+
+	_, err = p.writer.WriteLevel(entry.Level, serialized)
+
+	return
+}
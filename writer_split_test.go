@@ -0,0 +1,138 @@
+package logrus_mate
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// splitOf returns the split writer of logger with the streams replaced by buffers
+func splitOf(t *testing.T, logger *logrus.Logger) (stdout, stderr *bytes.Buffer) {
+	t.Helper()
+
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if s, ok := hook.(*splitHook); ok {
+			stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+			s.writer.Stdout, s.writer.Stderr = stdout, stderr
+			return
+		}
+	}
+
+	t.Fatal("no split hook")
+	return
+}
+
+// bytesHook formats the entry by the logger formatter, the same as the hooks writing it elsewhere
+type bytesHook struct{}
+
+func (bytesHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (bytesHook) Fire(entry *logrus.Entry) error {
+	_, err := entry.Bytes()
+	return err
+}
+
+func TestSplitWriter(t *testing.T) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(`out.name = "split", level = "debug", formatter.name = "json"`)); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr := splitOf(t, logger)
+	if _, ok := logger.Out.(*NullWriter); !ok {
+		t.Fatalf("the out of logger should write nothing: %T", logger.Out)
+	}
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	if out := stdout.String(); !strings.Contains(out, `"msg":"debug"`) || !strings.Contains(out, `"msg":"info"`) || strings.Contains(out, "warn") || strings.Contains(out, "error") {
+		t.Fatalf("stdout %q", out)
+	}
+	if out := stderr.String(); !strings.Contains(out, `"msg":"warn"`) || !strings.Contains(out, `"msg":"error"`) || strings.Contains(out, "info") {
+		t.Fatalf("stderr %q", out)
+	}
+}
+
+func TestSplitWriterLevel(t *testing.T) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(`out { name = "split", options.split-level = "error" }`)); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr := splitOf(t, logger)
+
+	logger.Warn("warn")
+	logger.Error("error")
+
+	if !strings.Contains(stdout.String(), "msg=warn") || !strings.Contains(stderr.String(), "msg=error") || strings.Contains(stderr.String(), "warn") {
+		t.Fatalf("stdout %q, stderr %q", stdout, stderr)
+	}
+}
+
+// the level goes along with each entry, the concurrent logging or formatting by
+// the other hooks never sends an entry to the other stream
+func TestSplitWriterConcurrent(t *testing.T) {
+	logger := logrus.New()
+	if err := Hijack(logger, ConfigString(`out.name = "split", formatter.name = "json"`)); err != nil {
+		t.Fatal(err)
+	}
+	logger.AddHook(bytesHook{})
+
+	stdout, stderr := splitOf(t, logger)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if (i+j)%2 == 0 {
+					logger.Info("to stdout")
+				} else {
+					logger.Error("to stderr")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if out := stdout.String(); strings.Count(out, "to stdout") != 400 || strings.Contains(out, "to stderr") {
+		t.Fatalf("stdout has %d lines of stdout and %d of stderr", strings.Count(out, "to stdout"), strings.Count(out, "to stderr"))
+	}
+	if out := stderr.String(); strings.Count(out, "to stderr") != 400 || strings.Contains(out, "to stdout") {
+		t.Fatalf("stderr has %d lines of stderr and %d of stdout", strings.Count(out, "to stderr"), strings.Count(out, "to stdout"))
+	}
+}
+
+func TestSplitWriterRouteTarget(t *testing.T) {
+	logger, buf := hijackString(t, `route.targets.console.out.name = "split"`)
+
+	var target *routeTarget
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if r, ok := hook.(*routeHook); ok {
+			target = r.targets["console"]
+		}
+	}
+	if target == nil {
+		t.Fatal("no route target console")
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	splitWriter := target.out.(*SplitWriter)
+	splitWriter.Stdout, splitWriter.Stderr = stdout, stderr
+
+	logger.WithField("_route", "console").Info("info")
+	logger.WithField("_route", "console").Error("error")
+
+	if !strings.Contains(stdout.String(), "msg=info") || !strings.Contains(stderr.String(), "msg=error") || buf.Len() != 0 {
+		t.Fatalf("stdout %q, stderr %q, logger %q", stdout, stderr, buf)
+	}
+}